@@ -0,0 +1,399 @@
+package main
+
+import (
+	"app/internal/codec"
+	"app/internal/filter"
+	"app/internal/loader"
+	"app/pkg/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// runImport adds the vehicles of a file, the ones already in the inventory are a conflict
+func runImport(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var target targetFlags
+	target.register(fs)
+	from := fs.String("from", "", "format of the file imported when its extension does not tell it: json, ndjson or csv")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	inv, err := target.open(true)
+	if err != nil {
+		return err
+	}
+	// the file imported is read as the loader reads it, the target keeps -format
+	source := target.readFlags
+	source.format = *from
+	v, report, err := source.load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if printReport(os.Stderr, report, false) > 0 {
+		fmt.Fprintf(os.Stderr, "%s: nothing imported, fix the records above\n", fs.Arg(0))
+		return errFailed
+	}
+	docs := make([]models.VehicleDoc, 0, len(v))
+	for _, id := range sortedIds(v) {
+		docs = append(docs, mapVehicleToDoc(v[id]))
+	}
+	if err = inv.add(ctx, docs); err != nil {
+		return err
+	}
+	if err = inv.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d vehicles imported\n", len(docs))
+	return nil
+}
+
+// runExport writes all the vehicles
+func runExport(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var target targetFlags
+	target.register(fs)
+	to := fs.String("to", string(codec.JSON), "format written: json (a list the loader reads), csv, xml or ndjson")
+	out := fs.String("o", "-", "file written, - for the standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	f, err := codec.ParseFormat(*to)
+	if err != nil {
+		return err
+	}
+
+	inv, err := target.open(false)
+	if err != nil {
+		return err
+	}
+	v, err := inv.vehicles(ctx)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, v, f)
+}
+
+// runValidate loads a file or directory and prints what the loader did with its records, it fails
+// if any was skipped or duplicated
+func runValidate(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var read readFlags
+	read.register(fs)
+	verbose := fs.Bool("v", false, "print the records completed with the defaults as well")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	_, report, err := read.load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if printReport(os.Stdout, report, *verbose) > 0 {
+		return errFailed
+	}
+	return nil
+}
+
+// runDiff prints the vehicles added, removed and changed from one file to another, it fails if they differ
+func runDiff(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var read readFlags
+	read.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	left, report, err := read.load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if report.Errors() > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d records skipped or duplicated are not compared\n", fs.Arg(0), report.Errors())
+	}
+	right, report, err := read.load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if report.Errors() > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d records skipped or duplicated are not compared\n", fs.Arg(1), report.Errors())
+	}
+
+	ids := sortedIds(left)
+	for id := range right {
+		if _, ok := left[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	var added, removed, changed int
+	for _, id := range ids {
+		before, inLeft := left[id]
+		after, inRight := right[id]
+		switch {
+		case !inLeft:
+			added++
+			fmt.Printf("+ %d %s %s\n", id, after.Brand, after.Model)
+		case !inRight:
+			removed++
+			fmt.Printf("- %d %s %s\n", id, before.Brand, before.Model)
+		default:
+			changes := diffVehicles(before, after)
+			if len(changes) == 0 {
+				continue
+			}
+			changed++
+			fmt.Printf("~ %d %s\n", id, strings.Join(changes, ", "))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d added, %d removed, %d changed\n", added, removed, changed)
+	if added+removed+changed > 0 {
+		return errFailed
+	}
+	return nil
+}
+
+// diffVehicles returns the fields that differ between two vehicles as name: before -> after,
+// named and in the order of the columns of the exports
+func diffVehicles(before, after models.Vehicle) (changes []string) {
+	b, a := reflect.ValueOf(mapVehicleToDoc(before)), reflect.ValueOf(mapVehicleToDoc(after))
+	names := make(map[string]int)
+	for i := 0; i < b.NumField(); i++ {
+		name, _, _ := strings.Cut(b.Type().Field(i).Tag.Get("json"), ",")
+		names[name] = i
+	}
+	for _, name := range codec.Columns() {
+		i, ok := names[name]
+		if !ok {
+			continue
+		}
+		if x, y := b.Field(i).Interface(), a.Field(i).Interface(); x != y {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, x, y))
+		}
+	}
+	return
+}
+
+// runApply applies the updates of a file in order. Against a file nothing is written unless all of
+// them succeed; against a server the ones before a failure stay applied, as with a batch.
+func runApply(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var target targetFlags
+	target.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	updates, err := readUpdates(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	inv, err := target.open(true)
+	if err != nil {
+		return err
+	}
+	for i, u := range updates {
+		if err = inv.apply(ctx, u); err != nil {
+			return fmt.Errorf("update %d (id %d): %w", i+1, u.Id, err)
+		}
+	}
+	if err = inv.save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d updates applied\n", len(updates))
+	return nil
+}
+
+// readUpdates reads a file of updates, a JSON list or an update in JSON by line, - for the standard input
+func readUpdates(path string) (updates []update, err error) {
+	var content []byte
+	if path == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.DisallowUnknownFields()
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		if err = dec.Decode(&updates); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else {
+		for {
+			var u update
+			if err = dec.Decode(&u); err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: update %d: %w", path, len(updates)+1, err)
+			}
+			updates = append(updates, u)
+		}
+	}
+	for i, u := range updates {
+		if u.Id <= 0 {
+			return nil, fmt.Errorf("%s: update %d: id is required", path, i+1)
+		}
+	}
+	return updates, nil
+}
+
+// runQuery writes the vehicles that match the filters, given as field=value or as a query string
+func runQuery(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var target targetFlags
+	target.register(fs)
+	to := fs.String("to", string(codec.JSON), "format written: json, csv, xml or ndjson")
+	out := fs.String("o", "-", "file written, - for the standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f, err := codec.ParseFormat(*to)
+	if err != nil {
+		return err
+	}
+	for _, arg := range fs.Args() {
+		if strings.HasPrefix(arg, "-") {
+			return fmt.Errorf("flag %s after the filters, the flags go first", arg)
+		}
+	}
+	query, err := url.ParseQuery(strings.Join(fs.Args(), "&"))
+	if err != nil {
+		return fmt.Errorf("malformed filters: %w", err)
+	}
+	ft, err := filter.Parse(query)
+	if err != nil {
+		return err
+	}
+
+	inv, err := target.open(false)
+	if err != nil {
+		return err
+	}
+	v, err := inv.vehicles(ctx)
+	if err != nil {
+		return err
+	}
+	v = ft.Apply(v)
+	if err = writeOutput(*out, v, f); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d vehicles\n", len(v))
+	return nil
+}
+
+// printReport prints the records skipped and duplicated, and the repaired ones with verbose,
+// followed by a summary. It returns the number of records not loaded.
+func printReport(w io.Writer, report models.LoadReport, verbose bool) int {
+	printIssues := func(kind string, issues []models.LoadIssue) {
+		for _, issue := range issues {
+			fmt.Fprintf(w, "%s %s:%d", kind, issue.Source, issue.Position)
+			if issue.Id != 0 {
+				fmt.Fprintf(w, " id %d", issue.Id)
+			}
+			fmt.Fprintf(w, ": %s\n", strings.Join(issue.Reasons, "; "))
+		}
+	}
+	printIssues("skipped", report.Skipped)
+	printIssues("duplicate", report.Duplicates)
+	for _, source := range report.Ignored {
+		fmt.Fprintf(w, "ignored %s: unknown extension\n", source)
+	}
+	if verbose {
+		printIssues("repaired", report.Repaired)
+	}
+	fmt.Fprintf(w, "%d loaded, %d skipped, %d duplicates, %d repaired, %d files ignored\n",
+		report.Loaded, len(report.Skipped), len(report.Duplicates), len(report.Repaired), len(report.Ignored))
+	return report.Errors()
+}
+
+// writeOutput writes the vehicles ordered by id to the file, - for the standard output. JSON is the
+// list the loader reads, the other formats are the ones of the exports of the API.
+func writeOutput(path string, v map[int]models.Vehicle, f codec.Format) (err error) {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		file, errCreate := os.Create(path)
+		if errCreate != nil {
+			return errCreate
+		}
+		// err is the result, so a failure writing the last bytes on close is returned
+		defer func() {
+			if errClose := file.Close(); err == nil {
+				err = errClose
+			}
+		}()
+		w = file
+	}
+
+	if f == codec.JSON {
+		return loader.WriteJSON(w, v)
+	}
+	vw := codec.NewVehicleWriter(w, f)
+	for _, id := range sortedIds(v) {
+		if err = vw.Write(mapVehicleToDoc(v[id])); err != nil {
+			return
+		}
+	}
+	return vw.Close()
+}
+
+// sortedIds returns the identifiers of the vehicles in order
+func sortedIds(v map[int]models.Vehicle) []int {
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func mapVehicleToDoc(vehicle models.Vehicle) models.VehicleDoc {
+	return models.VehicleDoc{
+		ID:              vehicle.Id,
+		Brand:           vehicle.Brand,
+		Model:           vehicle.Model,
+		Registration:    vehicle.Registration,
+		Color:           vehicle.Color,
+		FabricationYear: vehicle.FabricationYear,
+		Capacity:        vehicle.Capacity,
+		MaxSpeed:        vehicle.MaxSpeed,
+		FuelType:        vehicle.FuelType,
+		Transmission:    vehicle.Transmission,
+		Weight:          vehicle.Weight,
+		Height:          vehicle.Height,
+		Length:          vehicle.Length,
+		Width:           vehicle.Width,
+		Price:           vehicle.Price,
+		Mileage:         vehicle.Mileage,
+		BranchId:        vehicle.BranchId,
+		Status:          vehicle.Status,
+	}
+}
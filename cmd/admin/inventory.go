@@ -0,0 +1,323 @@
+package main
+
+import (
+	"app/internal/auth"
+	"app/internal/codec"
+	"app/internal/envelope"
+	"app/internal/loader"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/pkg/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// inventory is an interface that represents the vehicles the subcommands work on, a local file or a running server
+type inventory interface {
+	// vehicles returns all the vehicles at once
+	vehicles(ctx context.Context) (v map[int]models.Vehicle, err error)
+	// add adds the vehicles in order, stopping at the first one that is not valid
+	add(ctx context.Context, docs []models.VehicleDoc) (err error)
+	// apply applies an update to a vehicle
+	apply(ctx context.Context, u update) (err error)
+	// save writes the changes, a server has them applied already
+	save() (err error)
+}
+
+// update is a change to a vehicle of a file of updates, each field set is changed through the
+// same path as its route of the API
+type update struct {
+	// Id is the identifier of the vehicle
+	Id int `json:"id"`
+	// MaxSpeed is the new maximum speed, as PUT /vehicles/{id}/update_speed
+	MaxSpeed *float64 `json:"max_speed,omitempty"`
+	// FuelType is the new fuel type, as PUT /vehicles/{id}/update_fuel
+	FuelType *string `json:"fuel_type,omitempty"`
+	// Price is the new price, as PUT /vehicles/{id}/update_price
+	Price *decimal.Decimal `json:"price,omitempty"`
+	// Delete deletes the vehicle, as DELETE /vehicles/{id}, the other fields are ignored
+	Delete bool `json:"delete,omitempty"`
+}
+
+// readFlags are the flags of how the files of vehicles are read, the same as the loader options of the server
+type readFlags struct {
+	format       string
+	delimiter    string
+	decimalComma bool
+}
+
+// register adds the flags to fs
+func (f *readFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.format, "format", "", "format of the files of vehicles: json, ndjson or csv, by extension when empty")
+	fs.StringVar(&f.delimiter, "delimiter", "", "character that separates the values of the CSV files, or tab")
+	fs.BoolVar(&f.decimalComma, "decimal-comma", false, "read the numbers of the CSV files with a decimal comma, e.g. 1.234,5")
+}
+
+// options returns the options of the loader
+func (f *readFlags) options() (opts loader.Options, err error) {
+	if opts.Format, err = loader.ParseFormat(f.format); err != nil {
+		return
+	}
+	if f.delimiter != "" {
+		if opts.CSV.Delimiter, err = codec.ParseDelimiter(f.delimiter); err != nil {
+			return
+		}
+	}
+	opts.CSV.DecimalComma = f.decimalComma
+	return
+}
+
+// load reads the vehicles of a file or directory, or of the standard input with the path -
+func (f *readFlags) load(path string) (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	opts, err := f.options()
+	if err != nil {
+		return
+	}
+	if path == "-" {
+		if opts.Format == "" {
+			return nil, report, errors.New("-format is required to read the standard input")
+		}
+		return loader.Decode(os.Stdin, opts, "stdin")
+	}
+	ld, err := loader.New(path, opts)
+	if err != nil {
+		return
+	}
+	return ld.Load()
+}
+
+// targetFlags are the flags that select the inventory: a local file or a running server
+type targetFlags struct {
+	readFlags
+	file   string
+	server string
+	apiKey string
+	token  string
+}
+
+// register adds the flags to fs, the server and its credentials default to the environment
+func (f *targetFlags) register(fs *flag.FlagSet) {
+	f.readFlags.register(fs)
+	fs.StringVar(&f.file, "file", "", "file or directory of vehicles worked on directly, the server must not be running on it")
+	fs.StringVar(&f.server, "server", os.Getenv("CONCESIONARIA_SERVER"), "URL of a running server, e.g. http://localhost:8080")
+	fs.StringVar(&f.apiKey, "api-key", os.Getenv("CONCESIONARIA_API_KEY"), "API key sent to the server in "+auth.APIKeyHeader)
+	fs.StringVar(&f.token, "token", os.Getenv("CONCESIONARIA_TOKEN"), "JWT sent to the server as a bearer token")
+}
+
+// open returns the inventory selected, write tells if the subcommand changes it
+func (f *targetFlags) open(write bool) (inventory, error) {
+	switch {
+	case f.file != "" && f.server != "":
+		return nil, errors.New("-file and -server can't be used together")
+	case f.server != "":
+		return &remoteInventory{
+			base:   strings.TrimSuffix(f.server, "/") + envelope.Prefix,
+			apiKey: f.apiKey,
+			token:  f.token,
+			client: &http.Client{Timeout: time.Minute},
+		}, nil
+	case f.file != "":
+		return f.openLocal(write)
+	default:
+		return nil, errors.New("-file or -server is required")
+	}
+}
+
+// openLocal loads the file, a file that is written back must be plain JSON like the file repository of the server
+func (f *targetFlags) openLocal(write bool) (inventory, error) {
+	if write {
+		opts, err := f.options()
+		if err != nil {
+			return nil, err
+		}
+		format, compressed := loader.FormatOf(f.file)
+		if opts.Format != "" {
+			format = opts.Format
+		}
+		info, err := os.Stat(f.file)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() || compressed || format != loader.JSON {
+			return nil, fmt.Errorf("%s: only a plain JSON file can be written, export the vehicles to one first", f.file)
+		}
+	}
+
+	v, report, err := f.load(f.file)
+	if err != nil {
+		return nil, err
+	}
+	if report.Errors() > 0 {
+		// writing the file back would drop them
+		return nil, fmt.Errorf("%s: %d records are skipped or duplicated, see admin validate", f.file, report.Errors())
+	}
+	rp := repository.NewVehicleMap(v)
+	return &localInventory{path: f.file, rp: rp, sv: service.NewVehicleDefault(rp)}, nil
+}
+
+// localInventory is the inventory of a file, changed through the service of the server so it
+// validates the changes the same way
+type localInventory struct {
+	// path is the file of the vehicles
+	path string
+	// rp keeps the vehicles in memory until they are saved
+	rp *repository.VehicleMap
+	// sv validates and applies the changes
+	sv service.VehicleService
+	// changed tells if there are changes to save
+	changed bool
+}
+
+func (l *localInventory) vehicles(ctx context.Context) (map[int]models.Vehicle, error) {
+	return l.rp.FindAll(ctx)
+}
+
+func (l *localInventory) add(ctx context.Context, docs []models.VehicleDoc) (err error) {
+	for i, doc := range docs {
+		if err = l.sv.AddMultipleVehicles(ctx, []models.VehicleDoc{doc}); err != nil {
+			return fmt.Errorf("vehicle %d (id %d): %w", i+1, doc.ID, err)
+		}
+		l.changed = true
+	}
+	return
+}
+
+func (l *localInventory) apply(ctx context.Context, u update) (err error) {
+	switch {
+	case u.Delete:
+		err = l.sv.DeleteVehicle(ctx, u.Id)
+	default:
+		if u.MaxSpeed != nil {
+			if err = l.sv.UpdateMaxSpeed(ctx, u.Id, *u.MaxSpeed); err != nil {
+				return
+			}
+		}
+		if u.FuelType != nil {
+			if err = l.sv.UpdateFuel(ctx, u.Id, models.VehicleDoc{FuelType: *u.FuelType}); err != nil {
+				return
+			}
+		}
+		if u.Price != nil {
+			err = l.sv.UpdatePrice(ctx, u.Id, *u.Price)
+		}
+	}
+	if err == nil {
+		l.changed = true
+	}
+	return
+}
+
+func (l *localInventory) save() error {
+	if !l.changed {
+		return nil
+	}
+	v, err := l.rp.FindAll(context.Background())
+	if err != nil {
+		return err
+	}
+	return loader.NewVehicleJSONFile(l.path).Save(v)
+}
+
+// remoteInventory is the inventory of a running server, changed through the routes of the API
+type remoteInventory struct {
+	// base is the URL of the versioned API
+	base string
+	// apiKey and token are the credentials, either or none
+	apiKey, token string
+	// client sends the requests
+	client *http.Client
+}
+
+func (r *remoteInventory) vehicles(ctx context.Context) (map[int]models.Vehicle, error) {
+	var v map[int]models.Vehicle
+	err := r.do(ctx, http.MethodGet, "/vehicles?format=ndjson", nil, func(body io.Reader) (err error) {
+		v, _, err = loader.Decode(body, loader.Options{Format: loader.NDJSON}, r.base)
+		return
+	})
+	return v, err
+}
+
+func (r *remoteInventory) add(ctx context.Context, docs []models.VehicleDoc) error {
+	return r.do(ctx, http.MethodPost, "/vehicles/batch", docs, nil)
+}
+
+func (r *remoteInventory) apply(ctx context.Context, u update) (err error) {
+	path := "/vehicles/" + strconv.Itoa(u.Id)
+	if u.Delete {
+		return r.do(ctx, http.MethodDelete, path, nil, nil)
+	}
+	if u.MaxSpeed != nil {
+		if err = r.do(ctx, http.MethodPut, path+"/update_speed", models.VehicleDoc{MaxSpeed: *u.MaxSpeed}, nil); err != nil {
+			return
+		}
+	}
+	if u.FuelType != nil {
+		if err = r.do(ctx, http.MethodPut, path+"/update_fuel", models.VehicleDoc{FuelType: *u.FuelType}, nil); err != nil {
+			return
+		}
+	}
+	if u.Price != nil {
+		err = r.do(ctx, http.MethodPut, path+"/update_price", models.VehicleDoc{Price: *u.Price}, nil)
+	}
+	return
+}
+
+func (r *remoteInventory) save() error {
+	return nil
+}
+
+// do sends a request with the body in JSON, read reads the body of a successful response
+func (r *remoteInventory) do(ctx context.Context, method, path string, body any, read func(body io.Reader) error) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.base+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.apiKey != "" {
+		req.Header.Set(auth.APIKeyHeader, r.apiKey)
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		// the errors of the versioned API come in the envelope
+		var env envelope.Envelope
+		if err := json.NewDecoder(res.Body).Decode(&env); err == nil && env.Error != nil {
+			return fmt.Errorf("%s %s: %s", method, req.URL.Path, env.Error.Message)
+		}
+		return fmt.Errorf("%s %s: %s", method, req.URL.Path, res.Status)
+	}
+	if read == nil {
+		return nil
+	}
+	return read(res.Body)
+}
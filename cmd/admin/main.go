@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+// command is a subcommand of the tool
+type command struct {
+	// usage is the arguments of the subcommand
+	usage string
+	// summary is what the subcommand does
+	summary string
+	// run runs the subcommand with its flags, not parsed yet, and its arguments
+	run func(ctx context.Context, fs *flag.FlagSet, args []string) error
+}
+
+// commands are the subcommands by name
+var commands = map[string]command{
+	"import":   {usage: "(-file data.json | -server URL) [-from json|ndjson|csv] vehicles.csv", summary: "add the vehicles of a file in any format the loader reads", run: runImport},
+	"export":   {usage: "(-file path | -server URL) [-to json|csv|xml|ndjson] [-o out]", summary: "write all the vehicles, in JSON as the loader reads them", run: runExport},
+	"validate": {usage: "[-format f] [-v] path", summary: "check a file or directory of vehicles as the server loads it", run: runValidate},
+	"diff":     {usage: "[-format f] old new", summary: "show the vehicles added, removed and changed between two files", run: runDiff},
+	"apply":    {usage: "(-file data.json | -server URL) updates.json", summary: "apply a batch of updates and deletions", run: runApply},
+	"query":    {usage: "(-file path | -server URL) [-to json|csv|xml|ndjson] [field=value ...]", summary: "list the vehicles that match the filters of the API", run: runQuery},
+}
+
+// errFailed is returned by the subcommands that already explained why they failed, e.g. a file with
+// records skipped or two files that differ
+var errFailed = errors.New("failed")
+
+func main() {
+	// the subcommands working against a server stop at the first signal
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		if os.Args[1] != "-h" && os.Args[1] != "help" {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		}
+		usage()
+		os.Exit(2)
+	}
+
+	err := cmd.run(ctx, newFlagSet(os.Args[1], cmd), os.Args[2:])
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.Is(err, errFailed):
+		os.Exit(1)
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the subcommands
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: admin <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n  %-9s   admin %s %s\n", name, commands[name].summary, "", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run admin <command> -h for its flags.")
+}
+
+// newFlagSet returns the flags of a subcommand, with its usage
+func newFlagSet(name string, cmd command) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: admin %s %s\n\n%s\n\n", name, cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
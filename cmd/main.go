@@ -3,22 +3,23 @@ package main
 import (
 	"app/cmd/server"
 	"fmt"
+	"os"
 )
 
 func main() {
 	// env
-	// ...
+	// - config: defaults < config file < environment < flags
+	cfg, err := server.LoadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	// app
-	// - config
-	cfg := &server.ConfigServerChi{
-		ServerAddress:  ":8080",
-		LoaderFilePath: "../docs/db/vehicles_100.json",
-	}
 	app := server.NewServerChi(cfg)
 	// - run
 	if err := app.Run(); err != nil {
 		fmt.Println(err)
-		return
+		os.Exit(1)
 	}
 }
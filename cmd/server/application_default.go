@@ -1,30 +1,160 @@
 package server
 
 import (
+	"app/internal/auth"
+	"app/internal/certs"
+	"app/internal/codec"
+	"app/internal/envelope"
 	"app/internal/handler"
+	"app/internal/limits"
 	"app/internal/loader"
+	"app/internal/logging"
+	"app/internal/metrics"
+	"app/internal/openapi"
 	"app/internal/repository"
+	"app/internal/security"
 	"app/internal/service"
+	"app/internal/snapshot"
+	"app/internal/storage"
+	"app/internal/tracing"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/shopspring/decimal"
 )
 
 // ConfigServerChi is a struct that represents the configuration for ServerChi
 type ConfigServerChi struct {
 	// ServerAddress is the address where the server will be listening
 	ServerAddress string
-	// LoaderFilePath is the path to the file that contains the vehicles
+	// LoaderFilePath is the path to the file that contains the vehicles, or to a directory of files merged together
 	LoaderFilePath string
+	// LoaderFormat is the format of the files of vehicles (json, ndjson or csv), by extension when empty
+	LoaderFormat string
+	// LoaderCSVDelimiter separates the values of the CSV files of vehicles, a character or tab
+	LoaderCSVDelimiter string
+	// LoaderCSVDecimalComma reads the numbers of the CSV files of vehicles with a decimal comma
+	LoaderCSVDecimalComma bool
+	// LoaderStrict refuses to start when a record of the files of vehicles is skipped or duplicated
+	LoaderStrict bool
+	// LoaderWatchInterval is the time between checks of the files of vehicles to reload them, zero never checks
+	LoaderWatchInterval time.Duration
+	// LoaderReloadMerge keeps the vehicles changed through the API when the files are reloaded
+	LoaderReloadMerge bool
+	// RepositoryBackend is where the vehicles are kept (memory or file)
+	RepositoryBackend string
+	// ReadTimeout is the maximum duration for reading a request
+	ReadTimeout time.Duration
+	// WriteTimeout is the maximum duration for writing a response
+	WriteTimeout time.Duration
+	// IdleTimeout is the maximum duration a keep-alive connection waits for the next request
+	IdleTimeout time.Duration
+	// ShutdownTimeout is the maximum duration to drain the requests in flight on shutdown
+	ShutdownTimeout time.Duration
+	// DrainDelay is the time between failing the readiness probe and closing the listener on shutdown
+	DrainDelay time.Duration
+	// FlushInterval is the time between writes of the file repository, zero writes every change
+	FlushInterval time.Duration
+	// LogLevel is the minimum level of the logs (debug, info, warn or error)
+	LogLevel string
+	// AttachmentsDir is the directory where the vehicle attachments are stored
+	AttachmentsDir string
+	// AttachmentMaxSize is the maximum size of an attachment in bytes
+	AttachmentMaxSize int64
+	// MaxBodySize is the maximum size in bytes of the body of a request
+	MaxBodySize int64
+	// MaxBatchBodySize is the maximum size in bytes of the body of POST /vehicles/batch and POST /vehicles/import
+	MaxBatchBodySize int64
+	// SnapshotDir is the directory where the scheduled snapshots of the vehicles are written
+	SnapshotDir string
+	// SnapshotInterval is the time between scheduled snapshots, zero takes none
+	SnapshotInterval time.Duration
+	// SnapshotKeep is the number of scheduled snapshots kept, the oldest ones are removed
+	SnapshotKeep int
+	// ImportAsyncRows is the number of rows from which an import is added in the background
+	ImportAsyncRows int
+	// FinancingMaxRate is the highest nominal annual rate of a financing plan, in percent
+	FinancingMaxRate decimal.Decimal
+	// RateLimitKey is the budget of each authenticated client, by API key or user
+	RateLimitKey limits.Policy
+	// RateLimitIP is the budget of each address without credentials
+	RateLimitIP limits.Policy
+	// TracingExporter is where the spans are exported (none, otlp or file)
+	TracingExporter string
+	// TracingEndpoint is the URL of the OTLP collector, empty for the OTEL_EXPORTER_OTLP_* environment
+	TracingEndpoint string
+	// TracingFile is the file written by the file exporter
+	TracingFile string
+	// TLSCertFile is the PEM certificate chain of the server, with TLSKeyFile it turns TLS on
+	TLSCertFile string
+	// TLSKeyFile is the PEM private key of the server
+	TLSKeyFile string
+	// TLSClientCAFile is the PEM file with the authorities of the client certificates
+	TLSClientCAFile string
+	// TLSClientAuth is how the client certificates are requested (none, optional or require)
+	TLSClientAuth string
+	// TLSMinVersion is the lowest TLS version accepted (1.2 or 1.3)
+	TLSMinVersion string
+	// TLSReloadInterval is the time between checks of the certificate files for changes
+	TLSReloadInterval time.Duration
+	// AuthClientCertRole is the role granted to a verified client certificate, empty to not accept them
+	AuthClientCertRole string
+	// CORSAllowedOrigins are the origins allowed to call the API from a browser, empty turns CORS off
+	CORSAllowedOrigins []string
+	// CORSAllowedMethods are the methods allowed to the origins
+	CORSAllowedMethods []string
+	// CORSAllowedHeaders are the request headers allowed to the origins
+	CORSAllowedHeaders []string
+	// CORSExposedHeaders are the response headers readable by the origins
+	CORSExposedHeaders []string
+	// CORSAllowCredentials lets the browser send credentials to the origins
+	CORSAllowCredentials bool
+	// CORSMaxAge is how long the browser caches a preflight response
+	CORSMaxAge time.Duration
+	// SecurityContentSecurityPolicy is the Content-Security-Policy of the responses
+	SecurityContentSecurityPolicy string
+	// SecurityHSTSMaxAge is the max-age of Strict-Transport-Security, zero omits the header
+	SecurityHSTSMaxAge time.Duration
+	// AuthEnabled turns the authentication on, when off every caller is an admin
+	AuthEnabled bool
+	// AuthAPIKeys are the static API keys accepted
+	AuthAPIKeys []auth.APIKey
+	// AuthJWTAlgorithm is the algorithm of the accepted tokens (HS256 or RS256), empty to not accept tokens
+	AuthJWTAlgorithm string
+	// AuthJWTSecret is the shared secret of HS256
+	AuthJWTSecret string
+	// AuthJWTPublicKeyFile is the PEM file with the RSA public key of RS256
+	AuthJWTPublicKeyFile string
+	// AuthJWTPrivateKeyFile is the PEM file with the RSA private key that signs the RS256 tokens of the users
+	AuthJWTPrivateKeyFile string
+	// AuthJWTIssuer is the issuer required in the tokens, empty to accept any
+	AuthJWTIssuer string
+	// AuthAccessTokenTTL is the lifetime of the access tokens issued on login
+	AuthAccessTokenTTL time.Duration
+	// AuthRefreshTokenTTL is the lifetime of the refresh tokens issued on login
+	AuthRefreshTokenTTL time.Duration
+	// AuthPasswordResetTTL is the lifetime of the password reset tokens
+	AuthPasswordResetTTL time.Duration
+	// Features are the feature toggles by name, a feature not present is enabled
+	Features map[string]bool
 }
 
 // NewServerChi is a function that returns a new instance of ServerChi
 func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 	// default values
-	defaultConfig := &ConfigServerChi{
-		ServerAddress: ":8080",
-	}
+	defaultConfig := DefaultConfig()
 	if cfg != nil {
 		if cfg.ServerAddress != "" {
 			defaultConfig.ServerAddress = cfg.ServerAddress
@@ -32,77 +162,760 @@ func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 		if cfg.LoaderFilePath != "" {
 			defaultConfig.LoaderFilePath = cfg.LoaderFilePath
 		}
+		if cfg.LoaderFormat != "" {
+			defaultConfig.LoaderFormat = cfg.LoaderFormat
+		}
+		if cfg.LoaderCSVDelimiter != "" {
+			defaultConfig.LoaderCSVDelimiter = cfg.LoaderCSVDelimiter
+		}
+		if cfg.LoaderCSVDecimalComma {
+			defaultConfig.LoaderCSVDecimalComma = cfg.LoaderCSVDecimalComma
+		}
+		if cfg.LoaderStrict {
+			defaultConfig.LoaderStrict = cfg.LoaderStrict
+		}
+		if cfg.LoaderWatchInterval > 0 {
+			defaultConfig.LoaderWatchInterval = cfg.LoaderWatchInterval
+		}
+		if cfg.LoaderReloadMerge {
+			defaultConfig.LoaderReloadMerge = cfg.LoaderReloadMerge
+		}
+		if cfg.RepositoryBackend != "" {
+			defaultConfig.RepositoryBackend = cfg.RepositoryBackend
+		}
+		if cfg.ReadTimeout > 0 {
+			defaultConfig.ReadTimeout = cfg.ReadTimeout
+		}
+		if cfg.WriteTimeout > 0 {
+			defaultConfig.WriteTimeout = cfg.WriteTimeout
+		}
+		if cfg.IdleTimeout > 0 {
+			defaultConfig.IdleTimeout = cfg.IdleTimeout
+		}
+		if cfg.ShutdownTimeout > 0 {
+			defaultConfig.ShutdownTimeout = cfg.ShutdownTimeout
+		}
+		if cfg.DrainDelay > 0 {
+			defaultConfig.DrainDelay = cfg.DrainDelay
+		}
+		if cfg.FlushInterval > 0 {
+			defaultConfig.FlushInterval = cfg.FlushInterval
+		}
+		if cfg.LogLevel != "" {
+			defaultConfig.LogLevel = cfg.LogLevel
+		}
+		if cfg.AttachmentsDir != "" {
+			defaultConfig.AttachmentsDir = cfg.AttachmentsDir
+		}
+		if cfg.AttachmentMaxSize > 0 {
+			defaultConfig.AttachmentMaxSize = cfg.AttachmentMaxSize
+		}
+		if cfg.MaxBodySize > 0 {
+			defaultConfig.MaxBodySize = cfg.MaxBodySize
+		}
+		if cfg.MaxBatchBodySize > 0 {
+			defaultConfig.MaxBatchBodySize = cfg.MaxBatchBodySize
+		}
+		if cfg.SnapshotDir != "" {
+			defaultConfig.SnapshotDir = cfg.SnapshotDir
+		}
+		if cfg.SnapshotInterval > 0 {
+			defaultConfig.SnapshotInterval = cfg.SnapshotInterval
+		}
+		if cfg.SnapshotKeep > 0 {
+			defaultConfig.SnapshotKeep = cfg.SnapshotKeep
+		}
+		if cfg.ImportAsyncRows > 0 {
+			defaultConfig.ImportAsyncRows = cfg.ImportAsyncRows
+		}
+		if cfg.FinancingMaxRate.IsPositive() {
+			defaultConfig.FinancingMaxRate = cfg.FinancingMaxRate
+		}
+		if cfg.RateLimitKey != (limits.Policy{}) {
+			defaultConfig.RateLimitKey = cfg.RateLimitKey
+		}
+		if cfg.RateLimitIP != (limits.Policy{}) {
+			defaultConfig.RateLimitIP = cfg.RateLimitIP
+		}
+		if cfg.TracingExporter != "" {
+			defaultConfig.TracingExporter = cfg.TracingExporter
+		}
+		if cfg.TracingEndpoint != "" {
+			defaultConfig.TracingEndpoint = cfg.TracingEndpoint
+		}
+		if cfg.TracingFile != "" {
+			defaultConfig.TracingFile = cfg.TracingFile
+		}
+		if cfg.TLSCertFile != "" {
+			defaultConfig.TLSCertFile = cfg.TLSCertFile
+		}
+		if cfg.TLSKeyFile != "" {
+			defaultConfig.TLSKeyFile = cfg.TLSKeyFile
+		}
+		if cfg.TLSClientCAFile != "" {
+			defaultConfig.TLSClientCAFile = cfg.TLSClientCAFile
+		}
+		if cfg.TLSClientAuth != "" {
+			defaultConfig.TLSClientAuth = cfg.TLSClientAuth
+		}
+		if cfg.TLSMinVersion != "" {
+			defaultConfig.TLSMinVersion = cfg.TLSMinVersion
+		}
+		if cfg.TLSReloadInterval > 0 {
+			defaultConfig.TLSReloadInterval = cfg.TLSReloadInterval
+		}
+		if cfg.AuthClientCertRole != "" {
+			defaultConfig.AuthClientCertRole = cfg.AuthClientCertRole
+		}
+		if cfg.CORSAllowedOrigins != nil {
+			defaultConfig.CORSAllowedOrigins = cfg.CORSAllowedOrigins
+		}
+		if cfg.CORSAllowedMethods != nil {
+			defaultConfig.CORSAllowedMethods = cfg.CORSAllowedMethods
+		}
+		if cfg.CORSAllowedHeaders != nil {
+			defaultConfig.CORSAllowedHeaders = cfg.CORSAllowedHeaders
+		}
+		if cfg.CORSExposedHeaders != nil {
+			defaultConfig.CORSExposedHeaders = cfg.CORSExposedHeaders
+		}
+		if cfg.CORSAllowCredentials {
+			defaultConfig.CORSAllowCredentials = cfg.CORSAllowCredentials
+		}
+		if cfg.CORSMaxAge > 0 {
+			defaultConfig.CORSMaxAge = cfg.CORSMaxAge
+		}
+		if cfg.SecurityContentSecurityPolicy != "" {
+			defaultConfig.SecurityContentSecurityPolicy = cfg.SecurityContentSecurityPolicy
+		}
+		if cfg.SecurityHSTSMaxAge > 0 {
+			defaultConfig.SecurityHSTSMaxAge = cfg.SecurityHSTSMaxAge
+		}
+		if cfg.AuthEnabled {
+			defaultConfig.AuthEnabled = cfg.AuthEnabled
+		}
+		if cfg.AuthAPIKeys != nil {
+			defaultConfig.AuthAPIKeys = cfg.AuthAPIKeys
+		}
+		if cfg.AuthJWTAlgorithm != "" {
+			defaultConfig.AuthJWTAlgorithm = cfg.AuthJWTAlgorithm
+		}
+		if cfg.AuthJWTSecret != "" {
+			defaultConfig.AuthJWTSecret = cfg.AuthJWTSecret
+		}
+		if cfg.AuthJWTPublicKeyFile != "" {
+			defaultConfig.AuthJWTPublicKeyFile = cfg.AuthJWTPublicKeyFile
+		}
+		if cfg.AuthJWTPrivateKeyFile != "" {
+			defaultConfig.AuthJWTPrivateKeyFile = cfg.AuthJWTPrivateKeyFile
+		}
+		if cfg.AuthAccessTokenTTL > 0 {
+			defaultConfig.AuthAccessTokenTTL = cfg.AuthAccessTokenTTL
+		}
+		if cfg.AuthRefreshTokenTTL > 0 {
+			defaultConfig.AuthRefreshTokenTTL = cfg.AuthRefreshTokenTTL
+		}
+		if cfg.AuthPasswordResetTTL > 0 {
+			defaultConfig.AuthPasswordResetTTL = cfg.AuthPasswordResetTTL
+		}
+		if cfg.AuthJWTIssuer != "" {
+			defaultConfig.AuthJWTIssuer = cfg.AuthJWTIssuer
+		}
+		if cfg.Features != nil {
+			defaultConfig.Features = cfg.Features
+		}
 	}
 
-	return &ServerChi{
-		serverAddress:  defaultConfig.ServerAddress,
-		loaderFilePath: defaultConfig.LoaderFilePath,
-	}
+	return &ServerChi{cfg: *defaultConfig}
 }
 
 // ServerChi is a struct that implements the Application interface
 type ServerChi struct {
-	// serverAddress is the address where the server will be listening
-	serverAddress string
-	// loaderFilePath is the path to the file that contains the vehicles
-	loaderFilePath string
+	// cfg is the configuration of the server with the defaults applied
+	cfg ConfigServerChi
 }
 
-// Run is a method that runs the server
+// Run is a method that runs the server until it receives SIGINT or SIGTERM.
+// The probes answer as soon as the server listens, the API is mounted once the
+// vehicles are loaded. On shutdown it drains the requests in flight, stops the
+// background jobs and flushes the pending changes of the repository, returning
+// nil if all of it succeeded.
 func (a *ServerChi) Run() (err error) {
+	// logger, its level can be changed while the server runs
+	var level slog.Level
+	if err = level.UnmarshalText([]byte(a.cfg.LogLevel)); err != nil {
+		return
+	}
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(level)
+	slog.SetDefault(slog.New(logging.NewHandler(os.Stderr, logLevel)))
+	hdLogLevel := handler.NewLogLevelDefault(logLevel)
+
+	// health
+	version, commit, buildTime, goVersion := buildInfo()
+	hdHealth := handler.NewHealthDefault(handler.BuildInfo{
+		Version:    version,
+		Commit:     commit,
+		BuildTime:  buildTime,
+		GoVersion:  goVersion,
+		Repository: a.cfg.RepositoryBackend,
+	})
+	// tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:       a.cfg.TracingExporter,
+		Endpoint:       a.cfg.TracingEndpoint,
+		File:           a.cfg.TracingFile,
+		ServiceVersion: version,
+	})
+	if err != nil {
+		return fmt.Errorf("tracing: %w", err)
+	}
+	defer func() {
+		// flush the spans still buffered, bounded like the rest of the shutdown
+		ctx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+		defer cancel()
+		if errTracing := shutdownTracing(ctx); errTracing != nil {
+			err = errors.Join(err, fmt.Errorf("tracing: %w", errTracing))
+		}
+	}()
+	// authentication
+	authn, err := auth.NewAuthenticator(a.authConfig())
+	if err != nil {
+		return fmt.Errorf("authentication: %w", err)
+	}
+	// metrics
+	mt := metrics.NewMetrics()
+	// api description
+	spec, err := openapi.New()
+	if err != nil {
+		return err
+	}
+	// background jobs
+	bg := newBackground()
+	defer bg.Stop()
+	// vehicles, loaded once the server listens and reloaded when their files change
+	rld := loader.NewReloader(a.cfg.LoaderFilePath, a.loaderOptions(), a.cfg.LoaderStrict)
+	hdReload := handler.NewReloadDefault(rld, a.cfg.LoaderReloadMerge)
+	sn := snapshot.NewSnapshotter(a.cfg.SnapshotDir, a.cfg.SnapshotKeep)
+	hdSnapshot := handler.NewSnapshotDefault(sn)
+
+	// router
+	api := &apiHandler{}
+	rt := a.newRouter(api, hdHealth, hdLogLevel, hdReload, hdSnapshot, mt, authn, spec)
+	// - every route must be in the description, the ones of the api are checked once built
+	if missing := spec.Undocumented(rt); len(missing) > 0 {
+		return fmt.Errorf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", "))
+	}
+
+	// run server
+	srv := &http.Server{
+		Addr:         a.cfg.ServerAddress,
+		Handler:      rt,
+		ReadTimeout:  a.cfg.ReadTimeout,
+		WriteTimeout: a.cfg.WriteTimeout,
+		IdleTimeout:  a.cfg.IdleTimeout,
+	}
+	// - TLS, the certificates are reloaded without dropping the connections
+	if a.cfg.TLSCertFile != "" {
+		var reloader *certs.Reloader
+		reloader, err = certs.NewReloader(certs.Config{
+			CertFile:     a.cfg.TLSCertFile,
+			KeyFile:      a.cfg.TLSKeyFile,
+			ClientCAFile: a.cfg.TLSClientCAFile,
+			ClientAuth:   a.cfg.TLSClientAuth,
+			MinVersion:   tlsVersions[a.cfg.TLSMinVersion],
+		})
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		srv.TLSConfig = reloader.TLSConfig()
+		bg.Go("tls reload", func(ctx context.Context) {
+			reloader.Watch(ctx, a.cfg.TLSReloadInterval)
+		})
+	}
+	return a.serve(srv, api, bg, hdHealth, mt, authn, spec, rld, sn)
+}
+
+// newRouter is a method that builds the routes served at the root: the probes, the api description,
+// the admin routes and the api, mounted once the vehicles are loaded
+func (a *ServerChi) newRouter(api http.Handler, hdHealth *handler.HealthDefault, hdLogLevel *handler.LogLevelDefault, hdReload *handler.ReloadDefault, hdSnapshot *handler.SnapshotDefault, mt *metrics.Metrics, authn *auth.Authenticator, spec *openapi.Spec) (rt chi.Router) {
+	rt = chi.NewRouter()
+	// - middlewares
+	rt.Use(middleware.RequestID)
+	rt.Use(tracing.Middleware)
+	rt.Use(logging.Middleware)
+	rt.Use(mt.Middleware)
+	rt.Use(middleware.Recoverer)
+	rt.Use(security.Headers(security.HeadersConfig{
+		ContentSecurityPolicy: a.cfg.SecurityContentSecurityPolicy,
+		HSTSMaxAge:            a.cfg.SecurityHSTSMaxAge,
+	}))
+	// - the preflight requests are answered before the routes, they carry no credentials
+	rt.Use(security.NewCORS(security.CORSConfig{
+		AllowedOrigins:   a.cfg.CORSAllowedOrigins,
+		AllowedMethods:   a.cfg.CORSAllowedMethods,
+		AllowedHeaders:   a.cfg.CORSAllowedHeaders,
+		ExposedHeaders:   a.cfg.CORSExposedHeaders,
+		AllowCredentials: a.cfg.CORSAllowCredentials,
+		MaxAge:           a.cfg.CORSMaxAge,
+	}).Middleware)
+	// - probes, they answer while the vehicles are loading
+	rt.Get("/healthz", hdHealth.Healthz())
+	rt.Get("/readyz", hdHealth.Readyz())
+	rt.Get("/version", hdHealth.Version())
+	rt.Method(http.MethodGet, "/metrics", mt.Handler())
+	// - api description and its documentation page
+	rt.Get("/openapi.json", spec.Handler())
+	rt.Method(http.MethodGet, "/docs", openapi.UI())
+	rt.Method(http.MethodGet, "/docs/{file}", openapi.UI())
+	// - admin
+	rt.Route("/admin", func(rt chi.Router) {
+		rt.Use(authn.Middleware)
+		rt.Use(auth.Require(auth.RoleAdmin))
+		// - GET /admin/log_level
+		rt.Get("/log_level", hdLogLevel.Get())
+		// - PUT /admin/log_level
+		rt.Put("/log_level", hdLogLevel.Set())
+		// - POST /admin/reload
+		rt.Post("/reload", hdReload.Reload())
+		// - GET /admin/snapshot
+		rt.Get("/snapshot", hdSnapshot.Get())
+	})
+	// - api, served once the vehicles are loaded
+	rt.Mount("/", api)
+	return rt
+}
+
+// authConfig is a method that returns the configuration of the authentication
+func (a *ServerChi) authConfig() auth.Config {
+	return auth.Config{
+		Enabled:           a.cfg.AuthEnabled,
+		APIKeys:           a.cfg.AuthAPIKeys,
+		JWTAlgorithm:      a.cfg.AuthJWTAlgorithm,
+		JWTSecret:         a.cfg.AuthJWTSecret,
+		JWTPublicKeyFile:  a.cfg.AuthJWTPublicKeyFile,
+		JWTPrivateKeyFile: a.cfg.AuthJWTPrivateKeyFile,
+		JWTIssuer:         a.cfg.AuthJWTIssuer,
+		AccessTokenTTL:    a.cfg.AuthAccessTokenTTL,
+		ClientCertRole:    a.cfg.AuthClientCertRole,
+	}
+}
+
+// loaderOptions is a method that returns how the files of vehicles are read
+func (a *ServerChi) loaderOptions() loader.Options {
+	// the configuration is validated
+	format, _ := loader.ParseFormat(a.cfg.LoaderFormat)
+	opts := loader.Options{Format: format}
+	opts.CSV.Delimiter, _ = codec.ParseDelimiter(a.cfg.LoaderCSVDelimiter)
+	opts.CSV.DecimalComma = a.cfg.LoaderCSVDecimalComma
+	return opts
+}
+
+// newAPI is a method that loads the vehicles and builds the routes of the API.
+// The dependencies that must stay reachable are registered as readiness checks.
+func (a *ServerChi) newAPI(bg *background, hdHealth *handler.HealthDefault, mt *metrics.Metrics, authn *auth.Authenticator, spec *openapi.Spec, rld *loader.Reloader, sn *snapshot.Snapshotter) (rt chi.Router, rp repository.VehicleRepository, err error) {
 	// dependencies
-	// - loader
-	ld := loader.NewVehicleJSONFile(a.loaderFilePath)
-	db, err := ld.Load()
+	// - loader, the vehicles of the files are checked as the new ones, at a branch that exists
+	rpBranch := repository.NewBranchMap(map[int]models.Branch{
+		models.DefaultBranchId: {Id: models.DefaultBranchId, Name: "Casa central"},
+	})
+	rld.UseCheck(func(v models.Vehicle) error {
+		return service.CheckStock(rpBranch, v.Status, v.BranchId)
+	})
+	db, _, err := rld.Load()
+	if errors.Is(err, loader.ErrInvalidRecords) {
+		return nil, nil, fmt.Errorf("loader_strict: %w", err)
+	}
+	if err != nil {
+		return
+	}
+	// - repository, the reloads are swapped into it
+	switch a.cfg.RepositoryBackend {
+	case RepositoryFile:
+		// the configuration checks the file is plain JSON, the only format that is written
+		rpFile := repository.NewVehicleFile(db, loader.NewVehicleJSONFile(a.cfg.LoaderFilePath), a.cfg.FlushInterval)
+		bg.Go("flush vehicles", rpFile.Run)
+		rld.Use(rpFile)
+		rp = rpFile
+	default:
+		rpMap := repository.NewVehicleMap(db)
+		rld.Use(rpMap)
+		rp = rpMap
+	}
+	// - snapshots, a copy of the map under its lock is consistent at a point in time
+	sn.Use(rp)
+	if a.cfg.SnapshotInterval > 0 {
+		bg.Go("snapshots", func(ctx context.Context) {
+			sn.Run(ctx, a.cfg.SnapshotInterval)
+		})
+	}
+	if a.cfg.LoaderWatchInterval > 0 {
+		bg.Go("watch vehicles", func(ctx context.Context) {
+			rld.Watch(ctx, a.cfg.LoaderWatchInterval, a.cfg.LoaderReloadMerge)
+		})
+	}
+	rpQuote := repository.NewQuoteMap(nil)
+	rpOdometer := repository.NewOdometerMap(nil)
+	rpMaintenance := repository.NewMaintenanceMap()
+	rpAttachment := repository.NewAttachmentMap(nil)
+	rpTransfer := repository.NewTransferMap(nil)
+	rpImport := repository.NewImportJobMap()
+	rpUser := repository.NewUserMap(nil)
+	rpToken := repository.NewTokenMap()
+	// - storage
+	st, err := storage.NewBlobDisk(a.cfg.AttachmentsDir)
 	if err != nil {
 		return
 	}
-	// - repository
-	rp := repository.NewVehicleMap(db)
+	// - metrics
+	if err = mt.RegisterInventory(rp); err != nil {
+		return
+	}
+	// - the services see the repository through the spans, the metrics and the mutation logs
+	rpObserved := tracing.TraceVehicles(logging.LogVehicles(mt.CountVehicles(rp)))
+	// - readiness
+	hdHealth.AddCheck("repository", rp.Ping)
+	if a.cfg.FeatureEnabled(FeatureAttachments) {
+		hdHealth.AddCheck("attachments", func(ctx context.Context) error { return st.Ping() })
+	}
 	// - service
-	sv := service.NewVehicleDefault(rp)
+	sv := service.NewVehicleDefault(rpObserved)
+	svFinancing := service.NewFinancingDefault(rpObserved, rpQuote, a.cfg.FinancingMaxRate)
+	svOdometer := service.NewOdometerDefault(rpObserved, rpOdometer)
+	svMaintenance := service.NewMaintenanceDefault(rpObserved, rpMaintenance, svOdometer)
+	svAttachment := service.NewAttachmentDefault(rpObserved, rpAttachment, st, a.cfg.AttachmentMaxSize)
+	svBranch := service.NewBranchDefault(rpBranch, rpObserved)
+	svTransfer := service.NewTransferDefault(rpTransfer, rpObserved, rpBranch)
+	svImport := service.NewImportDefault(tracing.TraceVehicleService(sv), rpImport)
+	// the imports in the background are interrupted on shutdown before the pending changes are written,
+	// their jobs tell the rows added
+	bg.Go("import jobs", svImport.Run)
+	// - staff accounts, they need a key to sign their tokens
+	var svUser *service.UserDefault
+	if a.cfg.FeatureEnabled(FeatureUsers) {
+		switch {
+		case a.cfg.AuthJWTAlgorithm == "":
+			slog.Warn("user accounts disabled, auth.jwt_algorithm is not set")
+		case a.cfg.AuthJWTAlgorithm == auth.AlgorithmRS256 && a.cfg.AuthJWTPrivateKeyFile == "":
+			slog.Warn("user accounts disabled, auth.jwt_private_key_file is not set")
+		default:
+			var signer *auth.Signer
+			signer, err = auth.NewSigner(a.authConfig())
+			if err != nil {
+				return nil, nil, fmt.Errorf("authentication: %w", err)
+			}
+			svUser = service.NewUserDefault(rpUser, rpToken, signer, a.cfg.AuthRefreshTokenTTL, a.cfg.AuthPasswordResetTTL)
+			authn.UseRevocation(svUser)
+		}
+	}
+	// - new vehicles go to a branch that exists
+	sv.UseBranches(rpBranch)
+	// - a vehicle with a transfer in course can't be deleted
+	sv.CheckDelete(svTransfer.CheckVehicleDeletable)
+	// - hard deleting a vehicle removes what belongs to it, also when a reload drops it, so a vehicle
+	//   added again with its id starts clean
+	sv.OnDelete(svAttachment.DeleteAttachmentsByVehicle)
+	sv.OnDelete(svOdometer.DeleteReadingsByVehicle)
+	sv.OnDelete(svMaintenance.DeleteMaintenanceByVehicle)
+	sv.OnDelete(svFinancing.DeleteQuotesByVehicle)
+	sv.OnDelete(svTransfer.DeleteTransfersByVehicle)
+	rld.OnDelete(sv.Deleted)
 	// - handler
-	hd := handler.NewVehicleDefault(sv)
+	hd := handler.NewVehicleDefault(tracing.TraceVehicleService(sv))
+	hdFinancing := handler.NewFinancingDefault(svFinancing)
+	hdOdometer := handler.NewOdometerDefault(svOdometer)
+	hdMaintenance := handler.NewMaintenanceDefault(svMaintenance)
+	hdAttachment := handler.NewAttachmentDefault(svAttachment, a.cfg.AttachmentMaxSize)
+	hdBranch := handler.NewBranchDefault(svBranch)
+	hdTransfer := handler.NewTransferDefault(svTransfer)
+	hdImport := handler.NewImportDefault(svImport, a.cfg.ImportAsyncRows)
+	// - rate limits by client
+	rl := limits.NewLimiter(limits.Config{Key: a.cfg.RateLimitKey, IP: a.cfg.RateLimitIP})
+	bg.Go("rate limit eviction", rl.Run)
 	// router
-	rt := chi.NewRouter()
-	// - middlewares
-	rt.Use(middleware.Logger)
-	rt.Use(middleware.Recoverer)
-	// - endpoints
-	rt.Route("/vehicles", func(rt chi.Router) {
-		// - GET /vehicles
-		rt.Get("/", hd.GetAll())
-		// - POST /vehicles
-		rt.Post("/", hd.AddVehicle())
-		// get vehicles filtered by color and year
-		rt.Get("/color/{color}/year/{year}", hd.FindVehiclesByColorAndYear())
-		// get vehicles filtered by brand and range of years
-		rt.Get("/brand/{brand}/between/{start_year}/{end_year}", hd.FindVhehiclesByBrandAndRangeYears())
+	// - the routes are served under /v1 with the responses in the envelope {data, meta, error},
+	//   and at the root as deprecated aliases that keep the responses they always had
+	routes := func(rt chi.Router) {
+		// - middlewares: the body is read up to the size of its route before decoding it
+		rt.Use(limits.BodySize{
+			Default: a.cfg.MaxBodySize,
+			Routes: map[string]int64{
+				"POST /vehicles/batch":  a.cfg.MaxBatchBodySize,
+				"POST /vehicles/import": a.cfg.MaxBatchBodySize,
+				// the upload is streamed to the storage, the handler applies attachment_max_size
+				"POST /vehicles/{id}/attachments": 0,
+			},
+		}.Middleware(rt))
+		// - sessions, logging in needs no credentials
+		if svUser != nil {
+			hdUser := handler.NewUserDefault(svUser)
+			rt.Route("/auth", func(rt chi.Router) {
+				// the requests are counted by address, they carry no credentials yet
+				rt.Use(rl.Middleware)
+				// - POST /auth/login
+				rt.Post("/login", hdUser.Login())
+				// - POST /auth/refresh
+				rt.Post("/refresh", hdUser.Refresh())
+				// - POST /auth/password_reset
+				rt.Post("/password_reset", hdUser.ResetPassword())
+				// - POST /auth/logout
+				rt.With(authn.Middleware).Post("/logout", hdUser.Logout())
+			})
+			rt.With(rl.Failures, authn.Middleware, rl.Middleware, auth.Require(auth.RoleAdmin)).Route("/users", func(rt chi.Router) {
+				// - GET /users
+				rt.Get("/", hdUser.GetAll())
+				// - POST /users
+				rt.Post("/", hdUser.CreateUser())
+				// - GET /users/{id}
+				rt.Get("/{id}", hdUser.GetUserById())
+				// invalidates every token issued to the user
+				rt.Post("/{id}/revoke_tokens", hdUser.RevokeTokens())
+				// single use token to set a new password, handed to the user by the admin
+				rt.Post("/{id}/password_reset", hdUser.RequestPasswordReset())
+			})
+		}
+		// - the rest requires credentials: reads are open to viewers, writes require a salesperson,
+		// the failed attempts are counted by address
+		api := rt.With(rl.Failures, authn.Middleware, rl.Middleware, auth.RequireByMethod)
+		// - endpoints
+		api.Route("/vehicles", func(rt chi.Router) {
+			// - GET /vehicles
+			rt.Get("/", hd.GetAll())
+			// - POST /vehicles
+			rt.Post("/", hd.AddVehicle())
+			// get vehicles filtered by color and year
+			rt.Get("/color/{color}/year/{year}", hd.FindVehiclesByColorAndYear())
+			// get vehicles filtered by brand and range of years
+			rt.Get("/brand/{brand}/between/{start_year}/{end_year}", hd.FindVhehiclesByBrandAndRangeYears())
 
-		rt.Get("/average_speed/brand/{brand}", hd.FindAverageOfSpeedByBrand())
+			rt.Get("/average_speed/brand/{brand}", hd.FindAverageOfSpeedByBrand())
 
-		rt.Post("/batch", hd.AddMultipleVehicles())
+			rt.With(auth.Require(auth.RoleAdmin)).Post("/batch", hd.AddMultipleVehicles())
+			// bulk import of a CSV file, validated row by row and added in a job that can be polled
+			rt.With(auth.Require(auth.RoleAdmin)).Post("/import", hdImport.Import())
+			rt.With(auth.Require(auth.RoleAdmin)).Get("/import/{job_id}", hdImport.GetJobById())
 
-		rt.Put("/{id}/update_speed", hd.UpdateMaxSpeed())
+			rt.Put("/{id}/update_speed", hd.UpdateMaxSpeed())
 
-		rt.Get("/{id}", hd.GetVehicleById())
+			rt.Get("/{id}", hd.GetVehicleById())
 
-		rt.Get("/fuel_type/{type}", hd.FindVehiclesByFuel())
+			rt.Get("/fuel_type/{type}", hd.FindVehiclesByFuel())
 
-		rt.Delete("/{id}", hd.DeleteVehicle())
+			rt.With(auth.Require(auth.RoleAdmin)).Delete("/{id}", hd.DeleteVehicle())
 
-		rt.Get("/transmission/{type}", hd.FindVehiclesBytransmission())
+			rt.Get("/transmission/{type}", hd.FindVehiclesBytransmission())
 
-		rt.Put("/{id}/update_fuel", hd.UpdateFuel())
+			rt.Put("/{id}/update_fuel", hd.UpdateFuel())
 
-		rt.Get("/average_capacity/brand/{brand}", hd.GetAveragePeopleCapacityByBrand())
+			rt.Get("/average_capacity/brand/{brand}", hd.GetAveragePeopleCapacityByBrand())
 
-		rt.Get("/dimensions", hd.FindVehiclesByDimensions())
+			rt.Get("/dimensions", hd.FindVehiclesByDimensions())
 
-		rt.Get("/weight", hd.FindVehiclesByWeigth())
+			rt.Get("/weight", hd.FindVehiclesByWeigth())
+
+			rt.With(auth.Require(auth.RoleAdmin)).Put("/{id}/update_price", hd.UpdatePrice())
+			if a.cfg.FeatureEnabled(FeatureFinancing) {
+				// financing plan of a vehicle (french or german system)
+				rt.Get("/{id}/financing", hdFinancing.Simulate())
+			}
+
+			// get vehicles filtered by range of mileage
+			rt.Get("/mileage", hd.FindVehiclesByMileage())
+			if a.cfg.FeatureEnabled(FeatureOdometer) {
+				// odometer reading history of a vehicle
+				rt.Get("/{id}/odometer", hdOdometer.FindReadingsByVehicle())
+				rt.Post("/{id}/odometer", hdOdometer.AddReading())
+			}
+
+			if a.cfg.FeatureEnabled(FeatureMaintenance) {
+				// vehicles whose scheduled maintenance is overdue by days or km
+				rt.Get("/maintenance/overdue", hdMaintenance.FindOverdue())
+				// workshop interventions of a vehicle
+				rt.Get("/{id}/maintenance", hdMaintenance.FindRecordsByVehicle())
+				rt.Post("/{id}/maintenance", hdMaintenance.AddRecord())
+				rt.Get("/{id}/maintenance/cost", hdMaintenance.GetReconditioningCost())
+				rt.Get("/{id}/maintenance/schedules", hdMaintenance.FindSchedulesByVehicle())
+				rt.Post("/{id}/maintenance/schedules", hdMaintenance.AddSchedule())
+			}
+
+			if a.cfg.FeatureEnabled(FeatureAttachments) {
+				// photos, title documents and inspection reports of a vehicle
+				rt.Get("/{id}/attachments", hdAttachment.FindAttachmentsByVehicle())
+				rt.Post("/{id}/attachments", hdAttachment.Upload())
+				rt.Get("/{id}/attachments/{attachment_id}", hdAttachment.Download())
+				rt.Get("/{id}/attachments/{attachment_id}/thumbnail", hdAttachment.Thumbnail())
+				rt.Delete("/{id}/attachments/{attachment_id}", hdAttachment.DeleteAttachment())
+			}
+
+			if a.cfg.FeatureEnabled(FeatureBranches) {
+				// transfer history of a vehicle between branches
+				rt.Get("/{id}/transfers", hdTransfer.FindTransfersByVehicle())
+			}
+		})
+		if a.cfg.FeatureEnabled(FeatureBranches) {
+			api.Route("/branches", func(rt chi.Router) {
+				// - GET /branches
+				rt.Get("/", hdBranch.GetAll())
+				// - POST /branches
+				rt.Post("/", hdBranch.AddBranch())
+				// - GET /branches/{id}
+				rt.Get("/{id}", hdBranch.GetBranchById())
+				// - GET /branches/{id}/vehicles
+				rt.Get("/{id}/vehicles", hdBranch.FindVehiclesByBranch())
+			})
+			api.Route("/transfers", func(rt chi.Router) {
+				// - POST /transfers
+				rt.Post("/", hdTransfer.RequestTransfer())
+				// - GET /transfers?status=...
+				rt.Get("/", hdTransfer.FindTransfers())
+				// - GET /transfers/{id}
+				rt.Get("/{id}", hdTransfer.GetTransferById())
+				// workflow: requested -> in_transit -> received, or requested -> cancelled
+				rt.Put("/{id}/dispatch", hdTransfer.Dispatch())
+				rt.Put("/{id}/receive", hdTransfer.Receive())
+				rt.Put("/{id}/cancel", hdTransfer.Cancel())
+			})
+		}
+		if a.cfg.FeatureEnabled(FeatureFinancing) {
+			api.Route("/quotes", func(rt chi.Router) {
+				// - POST /quotes
+				rt.Post("/", hdFinancing.SaveQuote())
+				// - GET /quotes?customer_document=...|vehicle_id=...
+				rt.Get("/", hdFinancing.FindQuotes())
+				// - GET /quotes/{id}
+				rt.Get("/{id}", hdFinancing.GetQuoteById())
+			})
+		}
+	}
+	rt = chi.NewRouter()
+	rt.Route(envelope.Prefix, func(rt chi.Router) {
+		rt.Use(envelope.Middleware)
+		rt.NotFound(func(w http.ResponseWriter, r *http.Request) {
+			envelope.WriteError(w, r, http.StatusNotFound, "Ruta no encontrada")
+		})
+		rt.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+			envelope.WriteError(w, r, http.StatusMethodNotAllowed, "Método no permitido en la ruta")
+		})
+		routes(rt)
+	})
+	rt.Group(func(rt chi.Router) {
+		rt.Use(envelope.Deprecated)
+		routes(rt)
 	})
+	if missing := spec.Undocumented(rt); len(missing) > 0 {
+		return nil, nil, fmt.Errorf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", "))
+	}
 
-	// run server
-	err = http.ListenAndServe(a.serverAddress, rt)
-	return
+	return rt, rp, nil
+}
+
+// serve is a method that listens, loads the API and waits for a termination signal to shut the server down
+func (a *ServerChi) serve(srv *http.Server, api *apiHandler, bg *background, hdHealth *handler.HealthDefault, mt *metrics.Metrics, authn *auth.Authenticator, spec *openapi.Spec, rld *loader.Reloader, sn *snapshot.Snapshotter) (err error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errServe := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "address", a.cfg.ServerAddress, "repository", a.cfg.RepositoryBackend, "tls", srv.TLSConfig != nil)
+		if srv.TLSConfig != nil {
+			// the certificates come from the TLS configuration
+			errServe <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		errServe <- srv.ListenAndServe()
+	}()
+
+	type result struct {
+		rt  chi.Router
+		rp  repository.VehicleRepository
+		err error
+	}
+	loaded := make(chan result, 1)
+	go func() {
+		rt, rp, err := a.newAPI(bg, hdHealth, mt, authn, spec, rld, sn)
+		loaded <- result{rt: rt, rp: rp, err: err}
+	}()
+
+	// the repository is set once loaded, there is nothing to flush before that
+	var rp repository.VehicleRepository
+wait:
+	for {
+		select {
+		case res := <-loaded:
+			if res.err != nil {
+				// the server can't work without the vehicles
+				srv.Close()
+				return fmt.Errorf("loading: %w", res.err)
+			}
+			rp = res.rp
+			api.Store(res.rt)
+			hdHealth.MarkReady()
+			slog.Info("server ready")
+			// a nil channel is never ready, stop selecting it
+			loaded = nil
+		case err = <-errServe:
+			// the server could not start or failed
+			return err
+		case <-ctx.Done():
+			// a second signal kills the process right away
+			stop()
+			break wait
+		}
+	}
+
+	// shutdown
+	slog.Info("shutting down", "timeout", a.cfg.ShutdownTimeout.String(), "drain_delay", a.cfg.DrainDelay.String())
+	var errs []error
+	// - fail the readiness probe and give the load balancer time to notice it
+	hdHealth.MarkDraining()
+	time.Sleep(a.cfg.DrainDelay)
+	// - drain the requests in flight
+	ctxShutdown, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout)
+	defer cancel()
+	if err = srv.Shutdown(ctxShutdown); err != nil {
+		errs = append(errs, fmt.Errorf("draining connections: %w", err))
+	}
+	// - stop the background jobs
+	bg.Stop()
+	// - write the pending changes
+	if fl, ok := rp.(interface{ Flush() error }); ok {
+		if err = fl.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("flushing repository: %w", err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// apiHandler is a handler that serves the API once it is loaded and 503 until then
+type apiHandler struct {
+	// rt is the router of the API, nil while loading
+	rt atomic.Pointer[chi.Router]
+}
+
+// Store sets the router that serves the requests from now on
+func (h *apiHandler) Store(rt chi.Router) {
+	h.rt.Store(&rt)
+}
+
+// ServeHTTP serves the request with the router of the API
+func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt := h.rt.Load()
+	if rt == nil {
+		w.Header().Set("Retry-After", "1")
+		starting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			envelope.WriteError(w, r, http.StatusServiceUnavailable, "El servicio se está iniciando")
+		})
+		if strings.HasPrefix(r.URL.Path, envelope.Prefix+"/") {
+			envelope.Middleware(starting).ServeHTTP(w, r)
+			return
+		}
+		starting(w, r)
+		return
+	}
+	(*rt).ServeHTTP(w, r)
 }
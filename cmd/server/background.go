@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// newBackground is a function that returns a new instance of background
+func newBackground() *background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &background{ctx: ctx, cancel: cancel}
+}
+
+// background is a struct that runs the jobs that live as long as the server
+type background struct {
+	// ctx is done when the jobs must stop
+	ctx context.Context
+	// cancel stops the jobs
+	cancel context.CancelFunc
+	// mu guards stopped and the jobs added to wg
+	mu sync.Mutex
+	// stopped is set by Stop, no job starts after it
+	stopped bool
+	// wg waits for the jobs to return
+	wg sync.WaitGroup
+}
+
+// Go is a method that runs a job until Stop is called, a job added once Stop is called is not run,
+// e.g. one of the API still loading when the server shuts down
+func (b *background) Go(name string, job func(ctx context.Context)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		slog.Debug("background job not started, stopping", "job", name)
+		return
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		slog.Debug("background job started", "job", name)
+		job(b.ctx)
+		slog.Debug("background job stopped", "job", name)
+	}()
+}
+
+// Stop is a method that signals the jobs to stop and waits for them
+func (b *background) Stop() {
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+
+	b.cancel()
+	b.wg.Wait()
+}
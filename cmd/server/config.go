@@ -0,0 +1,703 @@
+package server
+
+import (
+	"app/internal/auth"
+	"app/internal/certs"
+	"app/internal/codec"
+	"app/internal/limits"
+	"app/internal/loader"
+	"app/internal/security"
+	"app/internal/tracing"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// RepositoryMemory keeps the vehicles only in memory, changes are lost on restart
+	RepositoryMemory = "memory"
+	// RepositoryFile keeps the vehicles in memory and writes every change to LoaderFilePath
+	RepositoryFile = "file"
+)
+
+const (
+	// FeatureFinancing enables the financing simulator and the quotes
+	FeatureFinancing = "financing"
+	// FeatureOdometer enables the odometer readings
+	FeatureOdometer = "odometer"
+	// FeatureMaintenance enables the maintenance records and schedules
+	FeatureMaintenance = "maintenance"
+	// FeatureAttachments enables the vehicle attachments
+	FeatureAttachments = "attachments"
+	// FeatureBranches enables the branches and the transfers between them
+	FeatureBranches = "branches"
+	// FeatureUsers enables the staff accounts and the login, it needs a key to sign the tokens
+	FeatureUsers = "users"
+)
+
+// features are the known feature toggles, all of them enabled by default
+var features = []string{FeatureFinancing, FeatureOdometer, FeatureMaintenance, FeatureAttachments, FeatureBranches, FeatureUsers}
+
+// tlsVersions are the TLS versions accepted as tls.min_version
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// envPrefix is the prefix of the environment variables read by LoadConfig
+const envPrefix = "CONCESIONARIA_"
+
+// option is a configuration value that can be set from the config file, the environment and the flags
+type option struct {
+	// key is the name in the config file, the environment variable and the flag derive from it
+	key string
+	// usage is the description shown by -help
+	usage string
+	// set parses the value and stores it in the configuration
+	set func(cfg *ConfigServerChi, value string) error
+}
+
+// env returns the name of the environment variable of the option
+func (o option) env() string {
+	return envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(o.key))
+}
+
+// flag returns the name of the command line flag of the option
+func (o option) flag() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(o.key)
+}
+
+// options returns every configurable value
+func options() []option {
+	opts := []option{
+		{key: "server_address", usage: "address where the server will be listening", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.ServerAddress = value
+			return nil
+		}},
+		{key: "loader_file_path", usage: "path to the file that contains the vehicles, or to a directory of files merged together", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.LoaderFilePath = value
+			return nil
+		}},
+		{key: "loader_format", usage: "format of the files of vehicles: json, ndjson or csv, by extension when empty", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.LoaderFormat = strings.ToLower(value)
+			return nil
+		}},
+		{key: "loader_csv_delimiter", usage: "character that separates the values of the CSV files of vehicles, or tab", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.LoaderCSVDelimiter = value
+			return nil
+		}},
+		{key: "loader_csv_decimal_comma", usage: "read the numbers of the CSV files of vehicles with a decimal comma, e.g. 1.234,5", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.LoaderCSVDecimalComma, err = strconv.ParseBool(value)
+			return
+		}},
+		{key: "loader_strict", usage: "refuse to start when a record of the files of vehicles is skipped or duplicated", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.LoaderStrict, err = strconv.ParseBool(value)
+			return
+		}},
+		{key: "loader_watch_interval", usage: "time between checks of the files of vehicles to reload them when they change, e.g. 10s, 0 never checks", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.LoaderWatchInterval, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "loader_reload_merge", usage: "keep the vehicles changed through the API when the files of vehicles are reloaded", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.LoaderReloadMerge, err = strconv.ParseBool(value)
+			return
+		}},
+		{key: "repository_backend", usage: "where the vehicles are kept: memory or file", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.RepositoryBackend = strings.ToLower(value)
+			return nil
+		}},
+		{key: "read_timeout", usage: "maximum duration for reading a request, e.g. 10s", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.ReadTimeout, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "write_timeout", usage: "maximum duration for writing a response, e.g. 30s", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.WriteTimeout, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "idle_timeout", usage: "maximum duration a keep-alive connection waits for the next request", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.IdleTimeout, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "shutdown_timeout", usage: "maximum duration to drain the requests in flight on shutdown", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.ShutdownTimeout, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "drain_delay", usage: "time /readyz fails before the listener closes on shutdown, e.g. 5s", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.DrainDelay, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "flush_interval", usage: "time between writes of the file repository, 0 writes every change", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.FlushInterval, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "log_level", usage: "minimum level of the logs: debug, info, warn or error", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.LogLevel = strings.ToLower(value)
+			return nil
+		}},
+		{key: "attachments_dir", usage: "directory where the vehicle attachments are stored", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.AttachmentsDir = value
+			return nil
+		}},
+		{key: "attachment_max_size", usage: "maximum size of an attachment in bytes", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.AttachmentMaxSize, err = strconv.ParseInt(value, 10, 64)
+			return
+		}},
+		{key: "max_body_size", usage: "maximum size in bytes of the body of a request", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.MaxBodySize, err = strconv.ParseInt(value, 10, 64)
+			return
+		}},
+		{key: "max_batch_body_size", usage: "maximum size in bytes of the body of POST /vehicles/batch and POST /vehicles/import", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.MaxBatchBodySize, err = strconv.ParseInt(value, 10, 64)
+			return
+		}},
+		{key: "snapshot_dir", usage: "directory where the scheduled snapshots of the vehicles are written", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.SnapshotDir = value
+			return nil
+		}},
+		{key: "snapshot_interval", usage: "time between scheduled snapshots of the vehicles, e.g. 1h, 0 takes none", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.SnapshotInterval, err = time.ParseDuration(value)
+			return
+		}},
+		{key: "snapshot_keep", usage: "number of scheduled snapshots kept, the oldest ones are removed", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.SnapshotKeep, err = strconv.Atoi(value)
+			return
+		}},
+		{key: "import_async_rows", usage: "rows of a CSV import from which the vehicles are added in the background", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.ImportAsyncRows, err = strconv.Atoi(value)
+			return
+		}},
+		{key: "financing_max_rate", usage: "highest nominal annual rate of a financing plan, in percent", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.FinancingMaxRate, err = decimal.NewFromString(value)
+			return
+		}},
+	}
+
+	opts = append(opts,
+		option{key: "ratelimit.key_read", usage: "reads allowed to each API key or user, e.g. 600/m, empty is unlimited", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.RateLimitKey.Read, err = limits.ParseRate(value)
+			return
+		}},
+		option{key: "ratelimit.key_write", usage: "writes allowed to each API key or user, e.g. 60/m, empty is unlimited", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.RateLimitKey.Write, err = limits.ParseRate(value)
+			return
+		}},
+		option{key: "ratelimit.ip_read", usage: "reads allowed to each address without credentials, e.g. 60/m, empty is unlimited", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.RateLimitIP.Read, err = limits.ParseRate(value)
+			return
+		}},
+		option{key: "ratelimit.ip_write", usage: "writes allowed to each address without credentials, e.g. 10/m, empty is unlimited", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.RateLimitIP.Write, err = limits.ParseRate(value)
+			return
+		}},
+		option{key: "tls.cert_file", usage: "PEM certificate chain of the server, with tls.key_file it serves HTTPS and HTTP/2", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TLSCertFile = value
+			return nil
+		}},
+		option{key: "tls.key_file", usage: "PEM private key of the server", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TLSKeyFile = value
+			return nil
+		}},
+		option{key: "tls.client_ca_file", usage: "PEM authorities of the client certificates for mutual TLS", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TLSClientCAFile = value
+			return nil
+		}},
+		option{key: "tls.client_auth", usage: "client certificates: none, optional or require", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TLSClientAuth = strings.ToLower(value)
+			return nil
+		}},
+		option{key: "tls.min_version", usage: "lowest TLS version accepted: 1.2 or 1.3", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TLSMinVersion = value
+			return nil
+		}},
+		option{key: "tls.reload_interval", usage: "time between checks of the certificate files for changes, SIGHUP reloads them at once", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.TLSReloadInterval, err = time.ParseDuration(value)
+			return
+		}},
+		option{key: "auth.client_cert_role", usage: "role granted to a verified TLS client certificate, empty to not accept them as credentials", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.AuthClientCertRole = strings.ToLower(value)
+			return nil
+		}},
+		option{key: "cors.allowed_origins", usage: "origins allowed to call the API from a browser separated by commas, * for any, e.g. https://*.example.com", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.CORSAllowedOrigins = splitList(value)
+			return nil
+		}},
+		option{key: "cors.allowed_methods", usage: "methods allowed to the origins separated by commas", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.CORSAllowedMethods = splitList(strings.ToUpper(value))
+			return nil
+		}},
+		option{key: "cors.allowed_headers", usage: "request headers allowed to the origins separated by commas, * for any", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.CORSAllowedHeaders = splitList(value)
+			return nil
+		}},
+		option{key: "cors.exposed_headers", usage: "response headers readable by the origins separated by commas", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.CORSExposedHeaders = splitList(value)
+			return nil
+		}},
+		option{key: "cors.allow_credentials", usage: "let the browser send cookies and the Authorization header to the origins", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.CORSAllowCredentials, err = strconv.ParseBool(value)
+			return
+		}},
+		option{key: "cors.max_age", usage: "time the browser caches a preflight response, e.g. 10m", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.CORSMaxAge, err = time.ParseDuration(value)
+			return
+		}},
+		option{key: "security.content_security_policy", usage: "Content-Security-Policy of the responses", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.SecurityContentSecurityPolicy = value
+			return nil
+		}},
+		option{key: "security.hsts_max_age", usage: "max-age of Strict-Transport-Security on TLS connections, 0 omits it, e.g. 8760h", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.SecurityHSTSMaxAge, err = time.ParseDuration(value)
+			return
+		}},
+		option{key: "tracing.exporter", usage: "where the spans are exported: none, otlp or file", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TracingExporter = strings.ToLower(value)
+			return nil
+		}},
+		option{key: "tracing.endpoint", usage: "URL of the OTLP HTTP collector, e.g. http://localhost:4318", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TracingEndpoint = value
+			return nil
+		}},
+		option{key: "tracing.file", usage: "file where the file exporter writes the spans", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.TracingFile = value
+			return nil
+		}},
+	)
+
+	opts = append(opts,
+		option{key: "auth.enabled", usage: "require credentials on the API, when off every caller is an admin", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.AuthEnabled, err = strconv.ParseBool(value)
+			return
+		}},
+		option{key: "auth.api_keys", usage: "comma separated name:role:key static API keys, roles: viewer, salesperson or admin", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.AuthAPIKeys, err = auth.ParseAPIKeys(value)
+			return
+		}},
+		option{key: "auth.jwt_algorithm", usage: "algorithm of the accepted tokens: HS256 or RS256, empty to not accept tokens", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.AuthJWTAlgorithm = strings.ToUpper(value)
+			return nil
+		}},
+		option{key: "auth.jwt_secret", usage: "shared secret of HS256 tokens", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.AuthJWTSecret = value
+			return nil
+		}},
+		option{key: "auth.jwt_public_key_file", usage: "PEM file with the RSA public key of RS256 tokens", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.AuthJWTPublicKeyFile = value
+			return nil
+		}},
+		option{key: "auth.jwt_private_key_file", usage: "PEM file with the RSA private key that signs the RS256 tokens of the users", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.AuthJWTPrivateKeyFile = value
+			return nil
+		}},
+		option{key: "auth.access_token_ttl", usage: "lifetime of the access tokens issued on login, e.g. 15m", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.AuthAccessTokenTTL, err = time.ParseDuration(value)
+			return
+		}},
+		option{key: "auth.refresh_token_ttl", usage: "lifetime of the refresh tokens issued on login, e.g. 168h", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.AuthRefreshTokenTTL, err = time.ParseDuration(value)
+			return
+		}},
+		option{key: "auth.password_reset_ttl", usage: "lifetime of the password reset tokens, e.g. 1h", set: func(cfg *ConfigServerChi, value string) (err error) {
+			cfg.AuthPasswordResetTTL, err = time.ParseDuration(value)
+			return
+		}},
+		option{key: "auth.jwt_issuer", usage: "issuer required in the tokens, empty to accept any", set: func(cfg *ConfigServerChi, value string) error {
+			cfg.AuthJWTIssuer = value
+			return nil
+		}},
+	)
+
+	for _, name := range features {
+		name := name
+		opts = append(opts, option{key: "features." + name, usage: "enable the " + name + " endpoints", set: func(cfg *ConfigServerChi, value string) error {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+			if cfg.Features == nil {
+				cfg.Features = make(map[string]bool)
+			}
+			cfg.Features[name] = enabled
+			return nil
+		}})
+	}
+	return opts
+}
+
+// DefaultConfig is a function that returns the configuration used when nothing else is set
+func DefaultConfig() *ConfigServerChi {
+	return &ConfigServerChi{
+		ServerAddress:                 "127.0.0.1:8080",
+		LoaderFilePath:                "docs/db/vehicles_100.json",
+		RepositoryBackend:             RepositoryMemory,
+		ReadTimeout:                   10 * time.Second,
+		WriteTimeout:                  30 * time.Second,
+		IdleTimeout:                   60 * time.Second,
+		ShutdownTimeout:               15 * time.Second,
+		LogLevel:                      "info",
+		AttachmentsDir:                "attachments",
+		AttachmentMaxSize:             10 << 20,
+		MaxBodySize:                   1 << 20,
+		MaxBatchBodySize:              16 << 20,
+		SnapshotDir:                   "snapshots",
+		SnapshotKeep:                  24,
+		ImportAsyncRows:               1000,
+		FinancingMaxRate:              decimal.NewFromInt(200),
+		AuthAccessTokenTTL:            15 * time.Minute,
+		AuthRefreshTokenTTL:           7 * 24 * time.Hour,
+		AuthPasswordResetTTL:          time.Hour,
+		TracingExporter:               tracing.ExporterNone,
+		TracingFile:                   "traces.jsonl",
+		TLSClientAuth:                 certs.ClientAuthNone,
+		TLSMinVersion:                 "1.2",
+		TLSReloadInterval:             10 * time.Second,
+		CORSAllowedMethods:            []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		CORSAllowedHeaders:            []string{"Authorization", "Content-Type", auth.APIKeyHeader, "X-Request-Id", "traceparent"},
+		CORSExposedHeaders:            []string{"X-Request-Id", "ETag", "Retry-After", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"},
+		CORSMaxAge:                    10 * time.Minute,
+		SecurityContentSecurityPolicy: security.DefaultContentSecurityPolicy,
+	}
+}
+
+// LoadConfig is a function that builds the configuration from the command line arguments.
+// Each source overrides the previous one:
+//  1. defaults (DefaultConfig)
+//  2. the config file, YAML or JSON by extension, given by -config or CONCESIONARIA_CONFIG
+//  3. environment variables, CONCESIONARIA_ followed by the key in upper case (e.g. CONCESIONARIA_SERVER_ADDRESS)
+//  4. command line flags, the key with dashes (e.g. -server-address)
+//
+// The result is validated, so a nil error means the server can start with it.
+func LoadConfig(args []string) (cfg *ConfigServerChi, err error) {
+	cfg = DefaultConfig()
+	opts := options()
+
+	// flags
+	fs := flag.NewFlagSet("concesionaria", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "path to a YAML or JSON config file")
+	flagValues := make(map[string]*string)
+	for _, opt := range opts {
+		flagValues[opt.flag()] = fs.String(opt.flag(), "", fmt.Sprintf("%s (env %s)", opt.usage, opt.env()))
+	}
+	if err = fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// - config file
+	if *configPath != "" {
+		var values map[string]string
+		values, err = readConfigFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, opt := range opts {
+			if value, ok := values[opt.key]; ok {
+				if err = opt.set(cfg, value); err != nil {
+					return nil, fmt.Errorf("config file %s: %s: %w", *configPath, opt.key, err)
+				}
+				delete(values, opt.key)
+			}
+		}
+		if len(values) > 0 {
+			unknown := make([]string, 0, len(values))
+			for key := range values {
+				unknown = append(unknown, key)
+			}
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("config file %s: unknown keys: %s", *configPath, strings.Join(unknown, ", "))
+		}
+	}
+
+	// - environment
+	for _, opt := range opts {
+		if value, ok := os.LookupEnv(opt.env()); ok {
+			if err = opt.set(cfg, value); err != nil {
+				return nil, fmt.Errorf("environment %s: %w", opt.env(), err)
+			}
+		}
+	}
+
+	// - flags explicitly set
+	var errFlag error
+	fs.Visit(func(f *flag.Flag) {
+		for _, opt := range opts {
+			if opt.flag() == f.Name && errFlag == nil {
+				if err := opt.set(cfg, *flagValues[f.Name]); err != nil {
+					errFlag = fmt.Errorf("flag -%s: %w", f.Name, err)
+				}
+			}
+		}
+	})
+	if errFlag != nil {
+		return nil, errFlag
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate is a method that checks the configuration and returns every problem found
+func (c *ConfigServerChi) Validate() error {
+	var errs []error
+
+	if host, _, err := net.SplitHostPort(c.ServerAddress); err != nil {
+		errs = append(errs, fmt.Errorf("server_address %q: %w", c.ServerAddress, err))
+	} else if !c.AuthEnabled && !isLoopback(host) {
+		// without credentials every caller is an admin, only the same machine may call
+		errs = append(errs, fmt.Errorf("server_address %q accepts remote connections with auth.enabled false, every caller would be an admin: enable auth or listen on a loopback address such as 127.0.0.1:8080", c.ServerAddress))
+	}
+
+	format, err := loader.ParseFormat(c.LoaderFormat)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("loader_format: %w", err))
+	}
+	if c.LoaderFilePath == "" {
+		errs = append(errs, errors.New("loader_file_path is required"))
+	} else if info, err := os.Stat(c.LoaderFilePath); err != nil {
+		errs = append(errs, fmt.Errorf("loader_file_path: %w", err))
+	} else if c.RepositoryBackend == RepositoryFile {
+		// the changes are written back as a JSON array
+		extFormat, compressed := loader.FormatOf(c.LoaderFilePath)
+		if format == "" {
+			format = extFormat
+		}
+		if info.IsDir() || compressed || format != loader.JSON {
+			errs = append(errs, fmt.Errorf("loader_file_path %q: repository_backend %s needs a plain JSON file", c.LoaderFilePath, RepositoryFile))
+		}
+	}
+	if c.LoaderCSVDelimiter != "" {
+		if _, err := codec.ParseDelimiter(c.LoaderCSVDelimiter); err != nil {
+			errs = append(errs, fmt.Errorf("loader_csv_delimiter %q: must be a character or tab", c.LoaderCSVDelimiter))
+		}
+	}
+	if c.LoaderWatchInterval < 0 {
+		errs = append(errs, errors.New("loader_watch_interval can't be negative"))
+	} else if c.LoaderWatchInterval > 0 && c.RepositoryBackend == RepositoryFile {
+		// the repository writes the file itself, each write would be reloaded
+		errs = append(errs, fmt.Errorf("loader_watch_interval: repository_backend %s writes loader_file_path, it can't be watched", RepositoryFile))
+	}
+
+	switch c.RepositoryBackend {
+	case RepositoryMemory, RepositoryFile:
+	default:
+		errs = append(errs, fmt.Errorf("repository_backend %q: must be %s or %s", c.RepositoryBackend, RepositoryMemory, RepositoryFile))
+	}
+
+	if c.ReadTimeout < 0 || c.WriteTimeout < 0 || c.IdleTimeout < 0 {
+		errs = append(errs, errors.New("read_timeout, write_timeout and idle_timeout can't be negative"))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown_timeout must be positive"))
+	}
+	if c.DrainDelay < 0 {
+		errs = append(errs, errors.New("drain_delay can't be negative"))
+	}
+	if c.FlushInterval < 0 {
+		errs = append(errs, errors.New("flush_interval can't be negative"))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("log_level %q: must be debug, info, warn or error", c.LogLevel))
+	}
+
+	if c.AttachmentMaxSize <= 0 {
+		errs = append(errs, errors.New("attachment_max_size must be positive"))
+	}
+	if c.MaxBodySize <= 0 || c.MaxBatchBodySize <= 0 {
+		errs = append(errs, errors.New("max_body_size and max_batch_body_size must be positive"))
+	}
+	if c.SnapshotInterval < 0 {
+		errs = append(errs, errors.New("snapshot_interval can't be negative"))
+	}
+	if c.SnapshotKeep <= 0 {
+		errs = append(errs, errors.New("snapshot_keep must be positive"))
+	}
+	if c.ImportAsyncRows <= 0 {
+		errs = append(errs, errors.New("import_async_rows must be positive"))
+	}
+	if !c.FinancingMaxRate.IsPositive() {
+		errs = append(errs, errors.New("financing_max_rate must be positive"))
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("tls.cert_file and tls.key_file must be set together"))
+	}
+	switch c.TLSClientAuth {
+	case certs.ClientAuthNone:
+	case certs.ClientAuthOptional, certs.ClientAuthRequire:
+		if c.TLSCertFile == "" {
+			errs = append(errs, fmt.Errorf("tls.client_auth %s requires tls.cert_file and tls.key_file", c.TLSClientAuth))
+		}
+		if c.TLSClientCAFile == "" {
+			errs = append(errs, fmt.Errorf("tls.client_auth %s requires tls.client_ca_file", c.TLSClientAuth))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tls.client_auth %q: must be %s, %s or %s", c.TLSClientAuth, certs.ClientAuthNone, certs.ClientAuthOptional, certs.ClientAuthRequire))
+	}
+	if _, ok := tlsVersions[c.TLSMinVersion]; !ok {
+		errs = append(errs, fmt.Errorf("tls.min_version %q: must be 1.2 or 1.3", c.TLSMinVersion))
+	}
+	if c.TLSReloadInterval <= 0 {
+		errs = append(errs, errors.New("tls.reload_interval must be positive"))
+	}
+	if c.AuthClientCertRole != "" {
+		if !auth.ValidRole(c.AuthClientCertRole) {
+			errs = append(errs, fmt.Errorf("auth.client_cert_role %q: must be viewer, salesperson or admin", c.AuthClientCertRole))
+		}
+		if c.TLSClientAuth == certs.ClientAuthNone {
+			errs = append(errs, errors.New("auth.client_cert_role requires tls.client_auth optional or require"))
+		}
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin == "*" && c.CORSAllowCredentials {
+			errs = append(errs, errors.New("cors.allowed_origins can't be * with cors.allow_credentials, list the origins"))
+			continue
+		}
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins %q: must be * or start with http:// or https://", origin))
+		}
+	}
+	if c.CORSMaxAge < 0 || c.SecurityHSTSMaxAge < 0 {
+		errs = append(errs, errors.New("cors.max_age and security.hsts_max_age can't be negative"))
+	}
+
+	switch c.TracingExporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP:
+	case tracing.ExporterFile:
+		if c.TracingFile == "" {
+			errs = append(errs, errors.New("tracing.file is required by the file exporter"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter %q: must be %s, %s or %s", c.TracingExporter, tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterFile))
+	}
+
+	switch c.AuthJWTAlgorithm {
+	case "":
+	case auth.AlgorithmHS256:
+		// RFC 7518 requires a key at least as long as the hash
+		if len(c.AuthJWTSecret) < 32 {
+			errs = append(errs, errors.New("auth.jwt_secret must have at least 32 bytes for HS256"))
+		}
+	case auth.AlgorithmRS256:
+		if c.AuthJWTPublicKeyFile == "" {
+			errs = append(errs, errors.New("auth.jwt_public_key_file is required for RS256"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("auth.jwt_algorithm %q: must be %s or %s", c.AuthJWTAlgorithm, auth.AlgorithmHS256, auth.AlgorithmRS256))
+	}
+	if c.AuthAccessTokenTTL <= 0 || c.AuthRefreshTokenTTL <= 0 || c.AuthPasswordResetTTL <= 0 {
+		errs = append(errs, errors.New("auth.access_token_ttl, auth.refresh_token_ttl and auth.password_reset_ttl must be positive"))
+	}
+	if c.AuthEnabled && len(c.AuthAPIKeys) == 0 && c.AuthJWTAlgorithm == "" {
+		errs = append(errs, errors.New("auth.enabled requires auth.api_keys or auth.jwt_algorithm, nobody could call the API"))
+	}
+
+	for name := range c.Features {
+		known := false
+		for _, feature := range features {
+			known = known || feature == name
+		}
+		if !known {
+			errs = append(errs, fmt.Errorf("features: unknown feature %q", name))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// FeatureEnabled is a method that tells if a feature is enabled, features not configured are enabled
+func (c *ConfigServerChi) FeatureEnabled(name string) bool {
+	enabled, ok := c.Features[name]
+	return !ok || enabled
+}
+
+// isLoopback is a function that tells if the host of an address only accepts connections from the same machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// readConfigFile reads a YAML or JSON file and returns its values by key,
+// nested objects are flattened with dots (features: {financing: false} is features.financing)
+func readConfigFile(path string) (values map[string]string, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	var raw map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".json":
+		// keep numbers as written, a float64 would print big sizes in exponent notation
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&raw)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension, use .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values = make(map[string]string)
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]any, values map[string]string) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flatten(key, nested, values)
+			continue
+		}
+		// a list is read as its items separated by commas
+		if list, ok := value.([]any); ok {
+			items := make([]string, 0, len(list))
+			for _, item := range list {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+			continue
+		}
+		values[key] = fmt.Sprint(value)
+	}
+}
+
+// splitList is a function that splits a list separated by commas, dropping the empty items
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"app/internal/auth"
+	"app/internal/handler"
+	"app/internal/loader"
+	"app/internal/metrics"
+	"app/internal/openapi"
+	"app/internal/snapshot"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestRoutesDocumented checks that, with every feature enabled, each route is described in the
+// OpenAPI document and each operation of the document is served
+func TestRoutesDocumented(t *testing.T) {
+	// every feature is enabled when none is configured, the user accounts need a key to sign their tokens
+	cfg := DefaultConfig()
+	cfg.LoaderFilePath = "../../docs/db/vehicles_100.json"
+	cfg.AttachmentsDir = t.TempDir()
+	cfg.SnapshotDir = t.TempDir()
+	cfg.AuthJWTAlgorithm = auth.AlgorithmHS256
+	cfg.AuthJWTSecret = "route-coverage-secret"
+	a := &ServerChi{cfg: *cfg}
+
+	authn, err := auth.NewAuthenticator(a.authConfig())
+	if err != nil {
+		t.Fatalf("authentication: %v", err)
+	}
+	spec, err := openapi.New()
+	if err != nil {
+		t.Fatalf("openapi: %v", err)
+	}
+	mt := metrics.NewMetrics()
+	bg := newBackground()
+	defer bg.Stop()
+	rld := loader.NewReloader(a.cfg.LoaderFilePath, a.loaderOptions(), a.cfg.LoaderStrict)
+	sn := snapshot.NewSnapshotter(a.cfg.SnapshotDir, a.cfg.SnapshotKeep)
+	hdHealth := handler.NewHealthDefault(handler.BuildInfo{})
+
+	root := a.newRouter(&apiHandler{}, hdHealth, handler.NewLogLevelDefault(new(slog.LevelVar)), handler.NewReloadDefault(rld, false), handler.NewSnapshotDefault(sn), mt, authn, spec)
+	api, _, err := a.newAPI(bg, hdHealth, mt, authn, spec, rld, sn)
+	if err != nil {
+		t.Fatalf("api: %v", err)
+	}
+
+	if missing := spec.Undocumented(root); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", "))
+	}
+	if missing := spec.Undocumented(api); len(missing) > 0 {
+		t.Errorf("routes missing from the OpenAPI document: %s", strings.Join(missing, ", "))
+	}
+	if extra := spec.Unrouted(root, api); len(extra) > 0 {
+		t.Errorf("operations of the OpenAPI document without a route: %s", strings.Join(extra, ", "))
+	}
+}
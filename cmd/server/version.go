@@ -0,0 +1,39 @@
+package server
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// build information, set at build time with
+// go build -ldflags "-X app/cmd/server.Version=v1.2.3 -X app/cmd/server.Commit=$(git rev-parse HEAD) -X app/cmd/server.BuildTime=$(date -u +%FT%TZ)"
+var (
+	// Version is the released version of the binary
+	Version = "dev"
+	// Commit is the git commit the binary was built from
+	Commit = ""
+	// BuildTime is the moment the binary was built
+	BuildTime = ""
+)
+
+// buildInfo returns the build information, falling back to the VCS stamp
+// the go tool embeds when the variables were not set at build time
+func buildInfo() (version, commit, buildTime, goVersion string) {
+	version, commit, buildTime = Version, Commit, BuildTime
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if commit == "" {
+					commit = setting.Value
+				}
+			case "vcs.time":
+				if buildTime == "" {
+					buildTime = setting.Value
+				}
+			}
+		}
+	}
+	return version, commit, buildTime, runtime.Version()
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// RoleViewer can read the inventory
+	RoleViewer = "viewer"
+	// RoleSalesperson can also change vehicles, quote and manage the day to day operations
+	RoleSalesperson = "salesperson"
+	// RoleAdmin can do everything, including deleting vehicles and batch inserts
+	RoleAdmin = "admin"
+)
+
+// roleRank orders the roles, a role is granted everything the lower ones are
+var roleRank = map[string]int{
+	RoleViewer:      1,
+	RoleSalesperson: 2,
+	RoleAdmin:       3,
+}
+
+// ValidRole is a function that tells if the role exists
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+const (
+	// MethodAPIKey is the authentication with a static API key
+	MethodAPIKey = "api_key"
+	// MethodJWT is the authentication with a signed token
+	MethodJWT = "jwt"
+	// MethodClientCert is the authentication with a TLS client certificate
+	MethodClientCert = "client_cert"
+	// MethodNone is used when the authentication is disabled
+	MethodNone = "none"
+)
+
+// Principal is a struct that represents who is making the request
+type Principal struct {
+	// Subject identifies the caller: the name of the API key, the subject of the token or
+	// the common name of the client certificate
+	Subject string
+	// Role is the role granted to the caller
+	Role string
+	// Method is how the caller was authenticated
+	Method string
+	// UserId is the identifier of the staff account, zero for API keys
+	UserId int
+	// TokenId is the identifier of the access token, to revoke it
+	TokenId string
+	// ExpiresAt is the expiration of the access token
+	ExpiresAt time.Time
+}
+
+// Has is a method that tells if the principal is granted the role
+func (p Principal) Has(role string) bool {
+	required, ok := roleRank[role]
+	return ok && roleRank[p.Role] >= required
+}
+
+// principalKey is the context key of the principal
+type principalKey struct{}
+
+// WithPrincipal is a function that returns a copy of the context carrying the principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext is a function that returns the principal of the request, if authenticated
+func PrincipalFromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(Principal)
+	return
+}
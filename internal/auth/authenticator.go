@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"app/internal/envelope"
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// AlgorithmHS256 signs the tokens with a shared secret
+	AlgorithmHS256 = "HS256"
+	// AlgorithmRS256 signs the tokens with an RSA private key and verifies them with its public key
+	AlgorithmRS256 = "RS256"
+)
+
+// APIKeyHeader is the header that carries a static API key
+const APIKeyHeader = "X-API-Key"
+
+var (
+	// ErrMissingCredentials is returned when the request carries no credentials
+	ErrMissingCredentials = errors.New("Credenciales requeridas")
+	// ErrInvalidCredentials is returned when the credentials are unknown, expired or malformed
+	ErrInvalidCredentials = errors.New("Credenciales inválidas")
+)
+
+// APIKey is a struct that represents a static API key
+type APIKey struct {
+	// Name identifies the key in the logs, e.g. the system that uses it
+	Name string
+	// Role is the role granted to the key
+	Role string
+	// Key is the secret value sent in the X-API-Key header
+	Key string
+}
+
+// ParseAPIKeys is a function that parses a comma separated list of name:role:key entries
+func ParseAPIKeys(value string) (keys []APIKey, err error) {
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("api key %q: must be name:role:key", parts[0])
+		}
+		if !ValidRole(parts[1]) {
+			return nil, fmt.Errorf("api key %q: unknown role %q", parts[0], parts[1])
+		}
+		keys = append(keys, APIKey{Name: parts[0], Role: parts[1], Key: parts[2]})
+	}
+	return keys, nil
+}
+
+// Claims is a struct that represents the claims of the tokens accepted by the server
+type Claims struct {
+	// Role is the role granted to the subject
+	Role string `json:"role"`
+	// UserId is the identifier of the staff account the token was issued to
+	UserId int `json:"uid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Config is a struct that represents how the callers are authenticated
+type Config struct {
+	// Enabled turns the authentication on, when off every caller is an admin
+	Enabled bool
+	// APIKeys are the static API keys accepted
+	APIKeys []APIKey
+	// JWTAlgorithm is HS256 or RS256, empty to not accept tokens
+	JWTAlgorithm string
+	// JWTSecret is the shared secret of HS256
+	JWTSecret string
+	// JWTPublicKeyFile is the PEM file with the RSA public key of RS256
+	JWTPublicKeyFile string
+	// JWTPrivateKeyFile is the PEM file with the RSA private key of RS256, only needed to issue tokens
+	JWTPrivateKeyFile string
+	// JWTIssuer is the issuer required in the tokens, empty to accept any
+	JWTIssuer string
+	// AccessTokenTTL is the lifetime of the tokens issued
+	AccessTokenTTL time.Duration
+	// ClientCertRole is the role granted to a verified TLS client certificate, empty to not accept them
+	ClientCertRole string
+}
+
+// Revocation is an interface that represents the tokens revoked before they expire
+type Revocation interface {
+	// Revoked is a method that tells if the token must be rejected
+	Revoked(ctx context.Context, claims Claims) bool
+}
+
+// NewAuthenticator is a function that returns a new instance of Authenticator
+func NewAuthenticator(cfg Config) (a *Authenticator, err error) {
+	a = &Authenticator{cfg: cfg}
+
+	switch cfg.JWTAlgorithm {
+	case "":
+	case AlgorithmHS256:
+		a.verifyKey = []byte(cfg.JWTSecret)
+	case AlgorithmRS256:
+		var pem []byte
+		pem, err = os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt public key: %w", err)
+		}
+		var key *rsa.PublicKey
+		key, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("jwt public key: %w", err)
+		}
+		a.verifyKey = key
+	default:
+		return nil, fmt.Errorf("unknown jwt algorithm %q", cfg.JWTAlgorithm)
+	}
+
+	if !cfg.Enabled {
+		slog.Warn("AUTHENTICATION DISABLED: every request is treated as an admin, set auth.enabled before exposing the server")
+	}
+	return a, nil
+}
+
+// Authenticator is a struct that identifies the caller of each request
+type Authenticator struct {
+	// cfg is how the callers are authenticated
+	cfg Config
+	// verifyKey checks the signature of the tokens, nil when tokens are not accepted
+	verifyKey any
+	// revocation holds the Revocation set by UseRevocation, if any
+	revocation atomic.Value
+}
+
+// revocationHolder keeps a Revocation in an atomic.Value, which needs a single concrete type
+type revocationHolder struct{ Revocation }
+
+// UseRevocation is a method that sets where the revoked tokens are looked up
+func (a *Authenticator) UseRevocation(r Revocation) {
+	a.revocation.Store(revocationHolder{r})
+}
+
+// Middleware is a method that authenticates the request and stores the principal in its
+// context, requests without valid credentials are rejected with 401
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.Authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="concesionaria"`)
+			envelope.WriteError(w, r, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		trace.SpanFromContext(r.Context()).SetAttributes(
+			attribute.String("enduser.id", p.Subject),
+			attribute.String("enduser.role", p.Role),
+		)
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+// Authenticate is a method that returns the principal of the credentials of the request,
+// an X-API-Key header or an Authorization: Bearer token
+func (a *Authenticator) Authenticate(r *http.Request) (p Principal, err error) {
+	if !a.cfg.Enabled {
+		return Principal{Subject: "anonymous", Role: RoleAdmin, Method: MethodNone}, nil
+	}
+
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.authenticateAPIKey(key)
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return a.authenticateToken(r.Context(), strings.TrimSpace(token))
+	}
+	// the chain was verified during the handshake against the client authorities
+	if a.cfg.ClientCertRole != "" && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		cert := r.TLS.VerifiedChains[0][0]
+		return Principal{Subject: cert.Subject.CommonName, Role: a.cfg.ClientCertRole, Method: MethodClientCert}, nil
+	}
+	return Principal{}, ErrMissingCredentials
+}
+
+// authenticateAPIKey looks the key up comparing in constant time
+func (a *Authenticator) authenticateAPIKey(key string) (p Principal, err error) {
+	for _, k := range a.cfg.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+			return Principal{Subject: k.Name, Role: k.Role, Method: MethodAPIKey}, nil
+		}
+	}
+	return Principal{}, ErrInvalidCredentials
+}
+
+// authenticateToken verifies the signature, the expiration and the issuer of the token
+func (a *Authenticator) authenticateToken(ctx context.Context, token string) (p Principal, err error) {
+	if a.verifyKey == nil {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{a.cfg.JWTAlgorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if a.cfg.JWTIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.cfg.JWTIssuer))
+	}
+
+	var claims Claims
+	_, err = jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return a.verifyKey, nil
+	}, opts...)
+	if err != nil || claims.Subject == "" || !ValidRole(claims.Role) {
+		return Principal{}, ErrInvalidCredentials
+	}
+	if holder, ok := a.revocation.Load().(revocationHolder); ok && holder.Revoked(ctx, claims) {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	p = Principal{Subject: claims.Subject, Role: claims.Role, Method: MethodJWT, UserId: claims.UserId, TokenId: claims.ID}
+	if claims.ExpiresAt != nil {
+		p.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return p, nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"app/internal/envelope"
+	"net/http"
+)
+
+// Require is a function that returns a middleware that only lets through the
+// principals granted the role, it must run after Authenticator.Middleware
+func Require(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="concesionaria"`)
+				envelope.WriteError(w, r, http.StatusUnauthorized, ErrMissingCredentials.Error())
+				return
+			}
+			if !p.Has(role) {
+				envelope.WriteError(w, r, http.StatusForbidden, "Permisos insuficientes")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireByMethod is a middleware that applies the default policy of the API:
+// reads are open to viewers and writes require a salesperson
+func RequireByMethod(next http.Handler) http.Handler {
+	viewer := Require(RoleViewer)(next)
+	salesperson := Require(RoleSalesperson)(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			viewer.ServeHTTP(w, r)
+		default:
+			salesperson.ServeHTTP(w, r)
+		}
+	})
+}
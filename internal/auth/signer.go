@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// NewSigner is a function that returns a new instance of Signer, it needs the
+// secret for HS256 or the private key for RS256
+func NewSigner(cfg Config) (s *Signer, err error) {
+	s = &Signer{algorithm: cfg.JWTAlgorithm, issuer: cfg.JWTIssuer, ttl: cfg.AccessTokenTTL}
+
+	switch cfg.JWTAlgorithm {
+	case AlgorithmHS256:
+		s.method = jwt.SigningMethodHS256
+		s.key = []byte(cfg.JWTSecret)
+	case AlgorithmRS256:
+		s.method = jwt.SigningMethodRS256
+		var pem []byte
+		pem, err = os.ReadFile(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt private key: %w", err)
+		}
+		s.key, err = jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("jwt private key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown jwt algorithm %q", cfg.JWTAlgorithm)
+	}
+	return s, nil
+}
+
+// Signer is a struct that issues the access tokens verified by Authenticator
+type Signer struct {
+	// algorithm is HS256 or RS256
+	algorithm string
+	// method signs the tokens
+	method jwt.SigningMethod
+	// key is the secret or the private key
+	key any
+	// issuer is set in the tokens, empty to leave it out
+	issuer string
+	// ttl is the lifetime of the tokens
+	ttl time.Duration
+}
+
+// TTL is a method that returns the lifetime of the tokens
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign is a method that returns a signed access token for the user
+func (s *Signer) Sign(userId int, username string, role string) (token string, err error) {
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Role:   role,
+		UserId: userId,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(id),
+			Subject:   username,
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+	return jwt.NewWithClaims(s.method, claims).SignedString(s.key)
+}
@@ -0,0 +1,197 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// ClientAuthNone does not ask for client certificates
+	ClientAuthNone = "none"
+	// ClientAuthOptional verifies the client certificate when one is sent
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire rejects the connections without a valid client certificate
+	ClientAuthRequire = "require"
+)
+
+// Config is a struct that represents the configuration of TLS
+type Config struct {
+	// CertFile is the PEM file with the certificate chain of the server
+	CertFile string
+	// KeyFile is the PEM file with the private key of the server
+	KeyFile string
+	// ClientCAFile is the PEM file with the authorities of the client certificates
+	ClientCAFile string
+	// ClientAuth is how the client certificates are requested (none, optional or require)
+	ClientAuth string
+	// MinVersion is the lowest TLS version accepted
+	MinVersion uint16
+}
+
+// NewReloader is a function that returns a new instance of Reloader with the files loaded
+func NewReloader(cfg Config) (*Reloader, error) {
+	r := &Reloader{cfg: cfg}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reloader is a struct that serves the certificates read from disk, they can be reloaded
+// while serving: the new connections use the new files and the open ones keep the old ones
+type Reloader struct {
+	// cfg is the configuration of TLS
+	cfg Config
+	// mu serializes the reloads and protects seen
+	mu sync.Mutex
+	// seen are the modification times of the files last read, valid or not
+	seen map[string]time.Time
+	// loaded is the last valid set of files
+	loaded atomic.Pointer[loaded]
+}
+
+// loaded is the content of the files at a point in time
+type loaded struct {
+	// cert is the certificate of the server
+	cert *tls.Certificate
+	// clientCAs are the authorities of the client certificates, nil without mutual TLS
+	clientCAs *x509.CertPool
+}
+
+// TLSConfig is a method that returns the configuration for the server, it reads the files loaded last
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.cfg.MinVersion,
+		NextProtos: []string{"h2", "http/1.1"},
+		// each handshake takes the files loaded last, HTTP/2 must be offered here as well
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			l := r.loaded.Load()
+			cfg := &tls.Config{
+				MinVersion:   r.cfg.MinVersion,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*l.cert},
+			}
+			if l.clientCAs != nil {
+				cfg.ClientCAs = l.clientCAs
+				cfg.ClientAuth = tls.VerifyClientCertIfGiven
+				if r.cfg.ClientAuth == ClientAuthRequire {
+					cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// Reload is a method that reads the files again, the previous ones are kept if they are not valid
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTimes := make(map[string]time.Time)
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTimes[path] = info.ModTime()
+	}
+	r.seen = modTimes
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("certificate: %w", err)
+	}
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientAuth != ClientAuthNone && r.cfg.ClientAuth != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("client authorities: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client authorities: no certificate found in %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.loaded.Store(&loaded{cert: &cert, clientCAs: clientCAs})
+	slog.Info("tls certificate loaded", "subject", cert.Leaf.Subject.String(), "not_after", cert.Leaf.NotAfter, "client_auth", r.cfg.ClientAuth)
+	if time.Until(cert.Leaf.NotAfter) < 0 {
+		slog.Warn("tls certificate expired", "not_after", cert.Leaf.NotAfter)
+	}
+	return nil
+}
+
+// changed reports whether any file was modified since it was read
+func (r *Reloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			// a file being replaced may be missing for a moment, the next check will see it
+			continue
+		}
+		if !info.ModTime().Equal(r.seen[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// files returns the files the configuration reads
+func (r *Reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientAuth != ClientAuthNone && r.cfg.ClientAuth != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// Watch is a method that reloads the files when they change, checking every interval,
+// or when the process receives SIGHUP, until the context is done. A zero interval
+// only reloads on SIGHUP.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("tls reload requested by SIGHUP")
+		case <-tick:
+			if !r.changed() {
+				continue
+			}
+			slog.Info("tls files changed")
+		}
+		if err := r.Reload(); err != nil {
+			slog.Error("tls reload failed, the previous certificate is still served", "error", err)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package codec
+
+import (
+	"app/pkg/models"
+	"errors"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Format is a representation of the vehicles
+type Format string
+
+const (
+	// JSON is the representation of the API, a document with every vehicle
+	JSON Format = "json"
+	// CSV is a table with a column by field of models.VehicleDoc
+	CSV Format = "csv"
+	// XML is a <vehicles> document with a <vehicle> element by vehicle
+	XML Format = "xml"
+	// NDJSON is a JSON object by line
+	NDJSON Format = "ndjson"
+)
+
+var (
+	// ErrUnknownFormat is returned when the format asked for does not exist
+	ErrUnknownFormat = errors.New("Formato no admitido, debe ser json, csv, xml o ndjson")
+	// ErrNotAcceptable is returned when the client accepts none of the formats
+	ErrNotAcceptable = errors.New("Ningún formato aceptado, debe ser application/json, text/csv, application/xml o application/x-ndjson")
+)
+
+// formats are the formats in order of preference, with the media types that select them
+var formats = []struct {
+	format     Format
+	mediaTypes []string
+}{
+	{JSON, []string{"application/json"}},
+	{CSV, []string{"text/csv"}},
+	{XML, []string{"application/xml", "text/xml"}},
+	{NDJSON, []string{"application/x-ndjson", "application/ndjson"}},
+}
+
+// ParseFormat is a function that returns the format with the name, e.g. csv
+func ParseFormat(name string) (Format, error) {
+	for _, f := range formats {
+		if strings.EqualFold(name, string(f.format)) {
+			return f.format, nil
+		}
+	}
+	return "", ErrUnknownFormat
+}
+
+// ContentType is a method that returns the media type of the responses in the format
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case XML:
+		return "application/xml; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// Negotiate is a function that returns the format of the response to the request: the query
+// parameter format if present, or else the format of the Accept header with the highest
+// quality, JSON when it is missing
+func Negotiate(r *http.Request) (Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		return ParseFormat(name)
+	}
+
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return JSON, nil
+	}
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []mediaRange
+	for _, value := range accept {
+		for _, item := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+			if err != nil {
+				continue
+			}
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	best, bestQuality := Format(""), 0.0
+	for _, f := range formats {
+		// the quality of a media type is the one of the most specific range that matches it
+		quality, specificity := 0.0, -1
+		for _, mt := range f.mediaTypes {
+			for _, rg := range ranges {
+				s := matches(rg.mediaType, mt)
+				if s > specificity {
+					quality, specificity = rg.quality, s
+				}
+			}
+		}
+		// ties keep the preferred format
+		if quality > bestQuality {
+			best, bestQuality = f.format, quality
+		}
+	}
+	if best == "" {
+		return "", ErrNotAcceptable
+	}
+	return best, nil
+}
+
+// matches returns how specific the media range is for the media type: 2 for an exact
+// match, 1 for type/*, 0 for */* and -1 if it does not match
+func matches(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+		return 1
+	default:
+		return -1
+	}
+}
+
+// column is a field of models.VehicleDoc in the tabular formats
+type column struct {
+	// name is the name of the field in JSON
+	name string
+	// index is the position of the field in the struct
+	index int
+}
+
+// columns are the fields of models.VehicleDoc in order of declaration
+var columns = func() (columns []column) {
+	t := reflect.TypeOf(models.VehicleDoc{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, column{name: name, index: i})
+	}
+	return
+}()
+
+// Columns is a function that returns the names of the columns of the tabular formats, the JSON names of models.VehicleDoc
+func Columns() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// fieldValue returns the text of a field of the vehicle
+func fieldValue(v reflect.Value) string {
+	if d, ok := v.Interface().(decimal.Decimal); ok {
+		return d.String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,296 @@
+package codec
+
+import (
+	"app/pkg/models"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shopspring/decimal"
+)
+
+// CSVOptions is a struct that represents how a CSV file of vehicles is read
+type CSVOptions struct {
+	// Delimiter separates the values, the one of the sep= line Excel writes or ',' when zero
+	Delimiter rune
+	// DecimalComma reads the numbers with ',' as the decimal separator and '.' grouping the thousands, e.g. 1.234,5
+	DecimalComma bool
+	// Header maps the names of the columns of the file to the JSON names of models.VehicleDoc,
+	// the columns not mapped are matched by name ignoring case
+	Header map[string]string
+}
+
+// ParseDelimiter is a function that returns the delimiter with the name: a character, or tab
+func ParseDelimiter(name string) (rune, error) {
+	if strings.EqualFold(name, "tab") || name == `\t` {
+		return '\t', nil
+	}
+	d, size := utf8.DecodeRuneInString(name)
+	if name == "" || size != len(name) || !validDelimiter(d) {
+		return 0, fmt.Errorf("Delimitador %q no admitido, debe ser un carácter como , ; | o tab", name)
+	}
+	return d, nil
+}
+
+// validDelimiter tells if the character can separate the values
+func validDelimiter(d rune) bool {
+	return d != utf8.RuneError && d != '"' && d != '\r' && d != '\n'
+}
+
+// ParseHeader is a function that returns the mapping of the columns of a file written as
+// name:field pairs separated by commas, e.g. Marca:brand,Año:year
+func ParseHeader(value string) (map[string]string, error) {
+	header := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return header, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		name, field, ok := strings.Cut(pair, ":")
+		name, field = strings.TrimSpace(name), strings.TrimSpace(field)
+		if !ok || name == "" || field == "" {
+			return nil, fmt.Errorf("Mapeo de columnas %q mal formado, debe ser columna:campo", pair)
+		}
+		if columnIndex(field) < 0 {
+			return nil, fmt.Errorf("Campo %q desconocido, debe ser uno de %s", field, strings.Join(Columns(), ", "))
+		}
+		header[name] = field
+	}
+	return header, nil
+}
+
+// columnIndex returns the position in columns of the column with the name ignoring case, -1 if there is none
+func columnIndex(name string) int {
+	for i, c := range columns {
+		if strings.EqualFold(c.name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// VehicleCSVReader is a struct that reads the vehicles of a CSV file a row at a time.
+// The first row is the header, the columns that are not fields of models.VehicleDoc are ignored.
+type VehicleCSVReader struct {
+	// r is the reader of the records
+	r *csv.Reader
+	// opts are the options of the file
+	opts CSVOptions
+	// offset is the number of lines read before r, e.g. the sep= line
+	offset int
+	// fields are the positions in columns of the columns of the file, -1 for the ones ignored
+	fields []int
+	// ignored are the names of the columns ignored
+	ignored []string
+}
+
+// NewVehicleCSVReader is a function that returns a new instance of VehicleCSVReader, it reads the header of the file
+func NewVehicleCSVReader(r io.Reader, opts CSVOptions) (*VehicleCSVReader, error) {
+	br := bufio.NewReader(r)
+	// Excel starts the files saved as CSV UTF-8 with a byte order mark
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+	// and can declare the delimiter in a first line like sep=;
+	offset := 0
+	if sep, _ := br.Peek(4); strings.EqualFold(string(sep), "sep=") {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		offset = 1
+		if d, err := ParseDelimiter(strings.TrimRight(line[4:], "\r\n")); err == nil && opts.Delimiter == 0 {
+			opts.Delimiter = d
+		}
+	}
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if !validDelimiter(opts.Delimiter) {
+		return nil, fmt.Errorf("Delimitador %q no admitido", opts.Delimiter)
+	}
+
+	c := &VehicleCSVReader{r: csv.NewReader(br), opts: opts, offset: offset}
+	c.r.Comma = opts.Delimiter
+	// the rows with a different number of values are reported by Read
+	c.r.FieldsPerRecord = -1
+	c.r.ReuseRecord = true
+
+	header, err := c.r.Read()
+	if err == io.EOF {
+		return nil, errors.New("El archivo está vacío, debe tener un encabezado")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Encabezado mal formado: %w", err)
+	}
+	mapped := make(map[int]string)
+	for _, name := range header {
+		name = strings.TrimSpace(name)
+		var index int
+		if field, ok := lookupHeader(opts.Header, name); ok {
+			index = columnIndex(field)
+		} else {
+			index = columnIndex(name)
+		}
+		if index < 0 {
+			c.ignored = append(c.ignored, name)
+		} else if other, ok := mapped[index]; ok {
+			return nil, fmt.Errorf("Las columnas %q y %q corresponden al mismo campo %s", other, name, columns[index].name)
+		} else {
+			mapped[index] = name
+		}
+		c.fields = append(c.fields, index)
+	}
+	return c, nil
+}
+
+// lookupHeader returns the field the mapping assigns to the column with the name, exactly or ignoring case
+func lookupHeader(header map[string]string, name string) (string, bool) {
+	if field, ok := header[name]; ok {
+		return field, true
+	}
+	for column, field := range header {
+		if strings.EqualFold(column, name) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// Ignored is a method that returns the names of the columns of the file that are not fields of the vehicles
+func (c *VehicleCSVReader) Ignored() []string {
+	return c.ignored
+}
+
+// Read is a method that returns the next row of the file, with the values that could not be read
+// in its errors, or io.EOF after the last one. The rows with every value blank are skipped.
+func (c *VehicleCSVReader) Read() (row models.ImportRow, err error) {
+	var record []string
+	for {
+		record, err = c.r.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.Line = parseErr.StartLine + c.offset
+			row.Errors = []string{"Fila mal formada: " + parseErr.Err.Error()}
+			return row, nil
+		}
+		if err != nil {
+			return
+		}
+		if !blank(record) {
+			break
+		}
+	}
+	line, _ := c.r.FieldPos(0)
+	row.Line = line + c.offset
+
+	if len(record) != len(c.fields) {
+		row.Errors = append(row.Errors, fmt.Sprintf("La fila tiene %d valores y el encabezado %d columnas", len(record), len(c.fields)))
+		return row, nil
+	}
+	rv := reflect.ValueOf(&row.Vehicle).Elem()
+	for i, value := range record {
+		if c.fields[i] < 0 {
+			continue
+		}
+		col := columns[c.fields[i]]
+		if err := setFieldValue(rv.Field(col.index), value, c.opts.DecimalComma); err != nil {
+			row.Errors = append(row.Errors, col.name+": "+err.Error())
+		}
+	}
+	return row, nil
+}
+
+// blank tells if every value of the record is empty
+func blank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// setFieldValue sets a field of the vehicle from its text, an empty text leaves the zero value
+func setFieldValue(v reflect.Value, value string, decimalComma bool) error {
+	value = strings.TrimSpace(value)
+	if !utf8.ValidString(value) {
+		return errors.New("texto que no es UTF-8, guardar el archivo como CSV UTF-8")
+	}
+	if value == "" {
+		return nil
+	}
+
+	if v.Type() == reflect.TypeOf(decimal.Decimal{}) {
+		number, err := normalizeNumber(value, decimalComma)
+		if err != nil {
+			return fmt.Errorf("número mal formado %q", value)
+		}
+		d, err := decimal.NewFromString(number)
+		if err != nil {
+			return fmt.Errorf("número mal formado %q", value)
+		}
+		v.Set(reflect.ValueOf(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		// the quote the export adds to the texts a spreadsheet would run as a formula
+		if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+			value = value[1:]
+		}
+		v.SetString(value)
+	case reflect.Int, reflect.Int64:
+		number, err := normalizeNumber(value, decimalComma)
+		if err != nil {
+			return fmt.Errorf("número entero mal formado %q", value)
+		}
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return fmt.Errorf("número entero mal formado %q", value)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		number, err := normalizeNumber(value, decimalComma)
+		if err != nil {
+			return fmt.Errorf("número mal formado %q", value)
+		}
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("número mal formado %q", value)
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
+
+// normalizeNumber returns a number written with a decimal comma, e.g. 1.234,5, as 1234.5.
+// The points must group the thousands, 1234.5 is not read as 12345.
+func normalizeNumber(value string, decimalComma bool) (string, error) {
+	if !decimalComma {
+		return value, nil
+	}
+	integer, fraction, hasFraction := strings.Cut(value, ",")
+	if strings.ContainsAny(fraction, ".,") {
+		return "", errors.New("malformed number")
+	}
+	if groups := strings.Split(integer, "."); len(groups) > 1 {
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return "", errors.New("malformed number")
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+	if hasFraction {
+		return integer + "." + fraction, nil
+	}
+	return integer, nil
+}
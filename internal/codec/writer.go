@@ -0,0 +1,153 @@
+package codec
+
+import (
+	"app/pkg/models"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// VehicleWriter is an interface that represents a writer of vehicles one at a time, nothing
+// is kept after a vehicle is written
+type VehicleWriter interface {
+	// Write writes a vehicle
+	Write(v models.VehicleDoc) (err error)
+	// Close writes what the format needs after the last vehicle, it does not close the underlying writer
+	Close() (err error)
+}
+
+// NewVehicleWriter is a function that returns a writer of vehicles in the format, JSON is written as NDJSON
+func NewVehicleWriter(w io.Writer, f Format) VehicleWriter {
+	switch f {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}
+	case XML:
+		return &xmlWriter{enc: xml.NewEncoder(w), w: w}
+	default:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}
+	}
+}
+
+// csvWriter writes a header with the columns and a row by vehicle
+type csvWriter struct {
+	// w is the writer of the table
+	w *csv.Writer
+	// started tells if the header was written
+	started bool
+}
+
+// Write writes the vehicle as a row
+func (c *csvWriter) Write(v models.VehicleDoc) (err error) {
+	if !c.started {
+		c.started = true
+		if err = c.w.Write(Columns()); err != nil {
+			return
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		field := rv.Field(col.index)
+		row[i] = fieldValue(field)
+		if field.Kind() == reflect.String {
+			row[i] = neutralizeFormula(row[i])
+		}
+	}
+	return c.w.Write(row)
+}
+
+// Close writes the header if there were no vehicles and flushes the rows
+func (c *csvWriter) Close() (err error) {
+	if !c.started {
+		c.started = true
+		if err = c.w.Write(Columns()); err != nil {
+			return
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// neutralizeFormula keeps a spreadsheet from running a text as a formula, prefixing it with a quote
+func neutralizeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// xmlWriter writes a <vehicles> document with a <vehicle> element by vehicle
+type xmlWriter struct {
+	// enc is the encoder of the elements
+	enc *xml.Encoder
+	// w is the writer of the document
+	w io.Writer
+	// started tells if the root element was opened
+	started bool
+}
+
+// start opens the document
+func (x *xmlWriter) start() (err error) {
+	if x.started {
+		return
+	}
+	x.started = true
+	if _, err = io.WriteString(x.w, xml.Header); err != nil {
+		return
+	}
+	return x.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "vehicles"}})
+}
+
+// Write writes the vehicle as an element with a child by field
+func (x *xmlWriter) Write(v models.VehicleDoc) (err error) {
+	if err = x.start(); err != nil {
+		return
+	}
+
+	vehicle := xml.StartElement{Name: xml.Name{Local: "vehicle"}}
+	if err = x.enc.EncodeToken(vehicle); err != nil {
+		return
+	}
+	rv := reflect.ValueOf(v)
+	for _, col := range columns {
+		if err = x.enc.EncodeElement(fieldValue(rv.Field(col.index)), xml.StartElement{Name: xml.Name{Local: col.name}}); err != nil {
+			return
+		}
+	}
+	if err = x.enc.EncodeToken(vehicle.End()); err != nil {
+		return
+	}
+	// the encoder buffers, each vehicle is sent as it is written
+	return x.enc.Flush()
+}
+
+// Close closes the document
+func (x *xmlWriter) Close() (err error) {
+	if err = x.start(); err != nil {
+		return
+	}
+	if err = x.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "vehicles"}}); err != nil {
+		return
+	}
+	return x.enc.Flush()
+}
+
+// ndjsonWriter writes a JSON object by line
+type ndjsonWriter struct {
+	// enc is the encoder of the lines
+	enc *json.Encoder
+}
+
+// Write writes the vehicle as a line
+func (n *ndjsonWriter) Write(v models.VehicleDoc) (err error) {
+	return n.enc.Encode(v)
+}
+
+// Close does nothing, the lines are complete
+func (n *ndjsonWriter) Close() (err error) {
+	return
+}
@@ -0,0 +1,168 @@
+package envelope
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Prefix is the path of the versioned API
+const Prefix = "/v1"
+
+// Envelope is a struct that represents the body of every response of the versioned API,
+// exactly one of Data and Error is set
+type Envelope struct {
+	// Data is the result of the request
+	Data any `json:"data"`
+	// Meta describes the result and the request
+	Meta Meta `json:"meta"`
+	// Error is the reason the request failed
+	Error *Error `json:"error"`
+}
+
+// Meta is a struct that represents the metadata of a response
+type Meta struct {
+	// RequestId is the identifier of the request, also sent in X-Request-Id
+	RequestId string `json:"request_id,omitempty"`
+	// Message is the outcome of a change
+	Message string `json:"message,omitempty"`
+	// Count is the number of items of a collection
+	Count *int `json:"count,omitempty"`
+}
+
+// Error is a struct that represents the reason a request failed
+type Error struct {
+	// Code is the status of the response in snake case, e.g. not_found
+	Code string `json:"code"`
+	// Message is the reason in a readable form
+	Message string `json:"message"`
+	// Details describe the reason when the message is not enough, e.g. the rows of a file that are not valid
+	Details any `json:"details,omitempty"`
+}
+
+// versionedKey is the key of the context that marks the requests to the versioned API
+type versionedKey struct{}
+
+// Middleware is a function that marks the requests of the versioned API, their responses are written in the envelope
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), versionedKey{}, true)))
+	})
+}
+
+// Versioned is a function that reports whether the request is to the versioned API
+func Versioned(ctx context.Context) bool {
+	versioned, _ := ctx.Value(versionedKey{}).(bool)
+	return versioned
+}
+
+// Deprecated is a function that marks the responses of the routes replaced by the versioned API,
+// with a link to the route that replaces each one
+func Deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+Prefix+r.URL.RequestURI()+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Write is a function that writes data in the envelope
+func Write(w http.ResponseWriter, r *http.Request, status int, data any, meta Meta) {
+	meta.RequestId = middleware.GetReqID(r.Context())
+	response.JSON(w, status, Envelope{Data: data, Meta: meta})
+}
+
+// NewListWriter is a function that returns a writer of a collection in the envelope an item at a time,
+// the status and the headers are sent right away and meta after the last item
+func NewListWriter(w http.ResponseWriter, r *http.Request, status int, meta Meta) *ListWriter {
+	meta.RequestId = middleware.GetReqID(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return &ListWriter{w: w, enc: json.NewEncoder(w), meta: meta}
+}
+
+// ListWriter is a struct that writes {"data": [...], "meta": {...}, "error": null} without keeping the items
+type ListWriter struct {
+	// w is the body of the response
+	w io.Writer
+	// enc is the encoder of the items
+	enc *json.Encoder
+	// meta is written after the items
+	meta Meta
+	// n is the number of items written
+	n int
+}
+
+// Write writes an item of the collection
+func (l *ListWriter) Write(item any) (err error) {
+	prefix := ","
+	if l.n == 0 {
+		prefix = `{"data":[`
+	}
+	if _, err = io.WriteString(l.w, prefix); err != nil {
+		return
+	}
+	l.n++
+	return l.enc.Encode(item)
+}
+
+// Close writes the end of the collection and the metadata, it does not close the response
+func (l *ListWriter) Close() (err error) {
+	suffix := `],"meta":`
+	if l.n == 0 {
+		suffix = `{"data":[],"meta":`
+	}
+	if _, err = io.WriteString(l.w, suffix); err != nil {
+		return
+	}
+	meta, err := json.Marshal(l.meta)
+	if err != nil {
+		return
+	}
+	if _, err = l.w.Write(meta); err != nil {
+		return
+	}
+	_, err = io.WriteString(l.w, `,"error":null}`)
+	return
+}
+
+// WriteError is a function that writes the reason a request failed, in the envelope for the
+// versioned API or as a bare string for the deprecated routes and the ones outside the API
+func WriteError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if !Versioned(r.Context()) {
+		response.JSON(w, status, message)
+		return
+	}
+	response.JSON(w, status, Envelope{
+		Meta:  Meta{RequestId: middleware.GetReqID(r.Context())},
+		Error: &Error{Code: Code(status), Message: message},
+	})
+}
+
+// WriteErrorDetails is a function that writes the reason a request failed with its details, the
+// deprecated routes send them as {"message", "data"}
+func WriteErrorDetails(w http.ResponseWriter, r *http.Request, status int, message string, details any) {
+	if !Versioned(r.Context()) {
+		response.JSON(w, status, map[string]any{"message": message, "data": details})
+		return
+	}
+	response.JSON(w, status, Envelope{
+		Meta:  Meta{RequestId: middleware.GetReqID(r.Context())},
+		Error: &Error{Code: Code(status), Message: message, Details: details},
+	})
+}
+
+// Code is a function that returns the code of an error status, e.g. not_found for 404
+func Code(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// Count is a function that returns the metadata of a collection of n items
+func Count(n int) Meta {
+	return Meta{Count: &n}
+}
@@ -0,0 +1,147 @@
+package filter
+
+import (
+	"app/pkg/models"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// field is a field of the vehicles that can be filtered, named as in models.VehicleDoc
+type field struct {
+	// text reads a field compared as text ignoring case, like the routes by color, brand or fuel type
+	text func(v models.Vehicle) string
+	// number reads a field compared as a number, exactly or in a range
+	number func(v models.Vehicle) float64
+}
+
+// fields are the fields that can be filtered by their JSON name
+var fields = map[string]field{
+	"id":           {number: func(v models.Vehicle) float64 { return float64(v.Id) }},
+	"brand":        {text: func(v models.Vehicle) string { return v.Brand }},
+	"model":        {text: func(v models.Vehicle) string { return v.Model }},
+	"registration": {text: func(v models.Vehicle) string { return v.Registration }},
+	"color":        {text: func(v models.Vehicle) string { return v.Color }},
+	"year":         {number: func(v models.Vehicle) float64 { return float64(v.FabricationYear) }},
+	"passengers":   {number: func(v models.Vehicle) float64 { return float64(v.Capacity) }},
+	"max_speed":    {number: func(v models.Vehicle) float64 { return v.MaxSpeed }},
+	"fuel_type":    {text: func(v models.Vehicle) string { return v.FuelType }},
+	"transmission": {text: func(v models.Vehicle) string { return v.Transmission }},
+	"weight":       {number: func(v models.Vehicle) float64 { return v.Weight }},
+	"height":       {number: func(v models.Vehicle) float64 { return v.Height }},
+	"length":       {number: func(v models.Vehicle) float64 { return v.Length }},
+	"width":        {number: func(v models.Vehicle) float64 { return v.Width }},
+	"price":        {number: func(v models.Vehicle) float64 { return v.Price.InexactFloat64() }},
+	"mileage":      {number: func(v models.Vehicle) float64 { return float64(v.Mileage) }},
+	"branch_id":    {number: func(v models.Vehicle) float64 { return float64(v.BranchId) }},
+	"status":       {text: func(v models.Vehicle) string { return v.Status }},
+}
+
+// Fields is a function that returns the names of the fields that can be filtered, sorted
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// condition is a criterion on one field
+type condition struct {
+	// field is how the value of the vehicle is read
+	field field
+	// text is the value of a text field
+	text string
+	// min and max are the bounds of a number field, both included
+	min, max float64
+}
+
+// match tells if the vehicle meets the condition
+func (c condition) match(v models.Vehicle) bool {
+	if c.field.text != nil {
+		return strings.EqualFold(c.field.text(v), c.text)
+	}
+	n := c.field.number(v)
+	return n >= c.min && n <= c.max
+}
+
+// Filter is a struct that represents the criteria of a query of vehicles, a vehicle matches when it
+// meets all of them
+type Filter struct {
+	// conditions are the criteria by field
+	conditions []condition
+}
+
+// Parse is a function that returns the filter of the query parameters, with the syntax of the API:
+// the JSON name of a field and its value, compared ignoring case for the texts (color=red), and for
+// the numbers a value (year=2008) or a range min-max with both included, like the dimensions route
+// (length=100-250.5). Several values of a parameter are all required.
+func Parse(query url.Values) (f Filter, err error) {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fl, ok := fields[name]
+		if !ok {
+			return Filter{}, fmt.Errorf("unknown filter %q, must be one of %s", name, strings.Join(Fields(), ", "))
+		}
+		for _, value := range query[name] {
+			c := condition{field: fl}
+			if fl.text != nil {
+				c.text = strings.TrimSpace(value)
+			} else if c.min, c.max, err = parseRange(value); err != nil {
+				return Filter{}, fmt.Errorf("filter %s: %w", name, err)
+			}
+			f.conditions = append(f.conditions, c)
+		}
+	}
+	return
+}
+
+// parseRange returns the bounds of a number, or of a range min-max
+func parseRange(value string) (min, max float64, err error) {
+	value = strings.TrimSpace(value)
+	if low, high, ok := strings.Cut(value, "-"); ok && low != "" {
+		if min, err = strconv.ParseFloat(strings.TrimSpace(low), 64); err != nil {
+			return 0, 0, fmt.Errorf("malformed range %q, must be min-max", value)
+		}
+		if max, err = strconv.ParseFloat(strings.TrimSpace(high), 64); err != nil {
+			return 0, 0, fmt.Errorf("malformed range %q, must be min-max", value)
+		}
+		if min > max {
+			return 0, 0, fmt.Errorf("range %q: min is greater than max", value)
+		}
+		return
+	}
+	if min, err = strconv.ParseFloat(value, 64); err != nil {
+		return 0, 0, fmt.Errorf("malformed number %q", value)
+	}
+	return min, min, nil
+}
+
+// Match is a method that tells if the vehicle meets every criterion of the filter
+func (f Filter) Match(v models.Vehicle) bool {
+	for _, c := range f.conditions {
+		if !c.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply is a method that returns the vehicles that match the filter
+func (f Filter) Apply(v map[int]models.Vehicle) map[int]models.Vehicle {
+	matched := make(map[int]models.Vehicle)
+	for id, vehicle := range v {
+		if f.Match(vehicle) {
+			matched[id] = vehicle
+		}
+	}
+	return matched
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"app/internal/service"
+	"app/pkg/models"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/bootcamp-go/web/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// multipartOverhead is the room left in the body limit for the multipart headers and fields
+const multipartOverhead = 1 << 20
+
+// NewAttachmentDefault is a function that returns a new instance of AttachmentDefault
+func NewAttachmentDefault(sv service.AttachmentService, maxSize int64) *AttachmentDefault {
+	return &AttachmentDefault{sv: sv, maxSize: maxSize}
+}
+
+// AttachmentDefault is a struct with methods that represent handlers for vehicle attachments
+type AttachmentDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.AttachmentService
+	// maxSize is the maximum size of an uploaded file in bytes
+	maxSize int64
+}
+
+// Upload is a method that returns a handler for the route POST /vehicles/{id}/attachments
+// The body is a multipart form with a "kind" field (photo, title or inspection) followed by a "file" part.
+// The kind can also be sent as a query parameter.
+func (h *AttachmentDefault) Upload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+		reader, err := r.MultipartReader()
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Se esperaba un formulario multipart")
+			return
+		}
+
+		// stream the parts, the file is never fully buffered in memory
+		kind := r.URL.Query().Get("kind")
+		var attachment models.Attachment
+		uploaded := false
+		for !uploaded {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			switch part.FormName() {
+			case "kind":
+				value, err := io.ReadAll(io.LimitReader(part, 64))
+				if err != nil {
+					writeError(w, r, http.StatusBadRequest, err.Error())
+					return
+				}
+				kind = string(value)
+			case "file":
+				attachment, err = h.sv.Upload(r.Context(), id, kind, part.FileName(), part)
+				if err != nil {
+					writeAttachmentError(w, r, err)
+					return
+				}
+				uploaded = true
+			}
+			part.Close()
+		}
+
+		if !uploaded {
+			writeError(w, r, http.StatusBadRequest, "El campo 'file' es requerido")
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Adjunto guardado exitosamente", mapAttachmentToDoc(attachment))
+	}
+}
+
+// FindAttachmentsByVehicle is a method that returns a handler for the route GET /vehicles/{id}/attachments
+func (h *AttachmentDefault) FindAttachmentsByVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		attachments, err := h.sv.FindAttachmentsByVehicle(r.Context(), id)
+		if err != nil {
+			writeAttachmentError(w, r, err)
+			return
+		}
+
+		data := make(map[int]models.AttachmentDoc)
+		for key, value := range attachments {
+			data[key] = mapAttachmentToDoc(value)
+		}
+		writeList(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// Download is a method that returns a handler for the route GET /vehicles/{id}/attachments/{attachment_id}
+func (h *AttachmentDefault) Download() http.HandlerFunc {
+	return h.serve(false)
+}
+
+// Thumbnail is a method that returns a handler for the route GET /vehicles/{id}/attachments/{attachment_id}/thumbnail
+func (h *AttachmentDefault) Thumbnail() http.HandlerFunc {
+	return h.serve(true)
+}
+
+// DeleteAttachment is a method that returns a handler for the route DELETE /vehicles/{id}/attachments/{attachment_id}
+func (h *AttachmentDefault) DeleteAttachment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+		attachmentId, err := strconv.Atoi(chi.URLParam(r, "attachment_id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del adjunto mal formado")
+			return
+		}
+
+		err = h.sv.DeleteAttachment(r.Context(), id, attachmentId)
+		if err != nil {
+			writeAttachmentError(w, r, err)
+			return
+		}
+		response.JSON(w, http.StatusNoContent, nil)
+	}
+}
+
+func (h *AttachmentDefault) serve(thumbnail bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+		attachmentId, err := strconv.Atoi(chi.URLParam(r, "attachment_id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del adjunto mal formado")
+			return
+		}
+
+		attachment, content, err := h.sv.Open(r.Context(), id, attachmentId, thumbnail)
+		if err != nil {
+			writeAttachmentError(w, r, err)
+			return
+		}
+		defer content.Close()
+
+		contentType, etag := attachment.ContentType, attachment.Hash
+		if thumbnail {
+			contentType, etag = "image/jpeg", attachment.ThumbnailHash
+		} else {
+			w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+		w.Header().Set("ETag", `"`+etag+`"`)
+		w.WriteHeader(http.StatusOK)
+		io.Copy(w, content)
+	}
+}
+
+// writeAttachmentError writes the status code that matches an attachment error
+func writeAttachmentError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "El archivo supera el tamaño máximo permitido")
+		return
+	}
+
+	switch err.Error() {
+	case "Vehicle not found":
+		writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+	case "Attachment not found", "Blob not found":
+		writeError(w, r, http.StatusNotFound, "No se encontró el adjunto")
+	case "El vehículo no tiene adjuntos", "El adjunto no tiene miniatura":
+		writeError(w, r, http.StatusNotFound, err.Error())
+	case "Tipo de adjunto no admitido", "El archivo está vacío", "Imagen mal formada":
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	case "Formato de archivo no admitido":
+		writeError(w, r, http.StatusUnsupportedMediaType, err.Error())
+	case "El archivo supera el tamaño máximo permitido", "La imagen supera las dimensiones máximas permitidas":
+		writeError(w, r, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func mapAttachmentToDoc(attachment models.Attachment) models.AttachmentDoc {
+	return models.AttachmentDoc{
+		ID:           attachment.Id,
+		VehicleId:    attachment.VehicleId,
+		Kind:         attachment.Kind,
+		FileName:     attachment.FileName,
+		ContentType:  attachment.ContentType,
+		Size:         attachment.Size,
+		Hash:         attachment.Hash,
+		HasThumbnail: attachment.ThumbnailHash != "",
+		CreatedAt:    attachment.CreatedAt,
+	}
+}
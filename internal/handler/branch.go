@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"app/internal/service"
+	"app/pkg/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewBranchDefault is a function that returns a new instance of BranchDefault
+func NewBranchDefault(sv service.BranchService) *BranchDefault {
+	return &BranchDefault{sv: sv}
+}
+
+// BranchDefault is a struct with methods that represent handlers for branches
+type BranchDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.BranchService
+}
+
+// GetAll is a method that returns a handler for the route GET /branches
+func (h *BranchDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, err := h.sv.FindAll(r.Context())
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		data := make(map[int]models.BranchDoc)
+		for key, value := range b {
+			data[key] = mapBranchToDoc(value)
+		}
+		writeList(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// AddBranch is a method that returns a handler for the route POST /branches
+func (h *BranchDefault) AddBranch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var branchDoc models.BranchDoc
+		err := json.NewDecoder(r.Body).Decode(&branchDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		branch, err := h.sv.AddBranch(r.Context(), branchDoc)
+		if err != nil {
+			if err.Error() == "Datos de la sucursal incompletos" {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Sucursal creada exitosamente", mapBranchToDoc(branch))
+	}
+}
+
+// GetBranchById is a method that returns a handler for the route GET /branches/{id}
+func (h *BranchDefault) GetBranchById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador de sucursal mal formado")
+			return
+		}
+
+		branch, err := h.sv.GetBranchById(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Branch not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró la sucursal")
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeData(w, r, http.StatusOK, "success", mapBranchToDoc(branch))
+	}
+}
+
+// FindVehiclesByBranch is a method that returns a handler for the route GET /branches/{id}/vehicles
+func (h *BranchDefault) FindVehiclesByBranch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador de sucursal mal formado")
+			return
+		}
+
+		vehicles, err := h.sv.FindVehiclesByBranch(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Branch not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró la sucursal")
+			} else if err.Error() == "No se encontraron vehículos en esa sucursal" {
+				writeError(w, r, http.StatusNotFound, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeVehicles(w, r, http.StatusOK, vehicles)
+	}
+}
+
+func mapBranchToDoc(branch models.Branch) models.BranchDoc {
+	return models.BranchDoc{
+		ID:      branch.Id,
+		Name:    branch.Name,
+		Address: branch.Address,
+		City:    branch.City,
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"app/internal/service"
+	"app/pkg/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+)
+
+// NewFinancingDefault is a function that returns a new instance of FinancingDefault
+func NewFinancingDefault(sv service.FinancingService) *FinancingDefault {
+	return &FinancingDefault{sv: sv}
+}
+
+// FinancingDefault is a struct with methods that represent handlers for financing plans and quotes
+type FinancingDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.FinancingService
+}
+
+// Simulate is a method that returns a handler for the route GET /vehicles/{id}/financing
+func (h *FinancingDefault) Simulate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		query := r.URL.Query()
+		req := models.FinancingRequest{VehicleId: id, System: query.Get("system")}
+
+		req.TermMonths, err = strconv.Atoi(query.Get("term"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Plazo mal formado")
+			return
+		}
+		req.DownPayment, err = parseDecimalParam(query.Get("down_payment"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Anticipo mal formado")
+			return
+		}
+		req.AnnualRate, err = parseDecimalParam(query.Get("annual_rate"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Tasa anual mal formada")
+			return
+		}
+		req.OpeningFee, err = parseDecimalParam(query.Get("opening_fee"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Gastos de otorgamiento mal formados")
+			return
+		}
+
+		// process
+		plan, err := h.sv.Simulate(r.Context(), req)
+		if err != nil {
+			writeFinancingError(w, r, err)
+			return
+		}
+
+		// response
+		writeData(w, r, http.StatusOK, "success", plan)
+	}
+}
+
+// SaveQuote is a method that returns a handler for the route POST /quotes
+func (h *FinancingDefault) SaveQuote() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var quoteDoc models.QuoteDoc
+		err := json.NewDecoder(r.Body).Decode(&quoteDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		quote, err := h.sv.SaveQuote(r.Context(), quoteDoc)
+		if err != nil {
+			if err.Error() == "Datos del cliente incompletos" {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeFinancingError(w, r, err)
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Cotización guardada exitosamente", mapQuoteToDoc(quote))
+	}
+}
+
+// GetQuoteById is a method that returns a handler for the route GET /quotes/{id}
+func (h *FinancingDefault) GetQuoteById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador de la cotización mal formado")
+			return
+		}
+
+		quote, err := h.sv.GetQuoteById(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Quote not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró la cotización")
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeData(w, r, http.StatusOK, "success", mapQuoteToDoc(quote))
+	}
+}
+
+// FindQuotes is a method that returns a handler for the route GET /quotes
+// filtered by the query parameter customer_document or vehicle_id
+func (h *FinancingDefault) FindQuotes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		document := r.URL.Query().Get("customer_document")
+		vehicleId := r.URL.Query().Get("vehicle_id")
+
+		var quotes map[int]models.Quote
+		var err error
+		switch {
+		case document != "":
+			quotes, err = h.sv.FindQuotesByCustomer(r.Context(), document)
+		case vehicleId != "":
+			id, errConv := strconv.Atoi(vehicleId)
+			if errConv != nil {
+				writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+				return
+			}
+			quotes, err = h.sv.FindQuotesByVehicle(r.Context(), id)
+		default:
+			writeError(w, r, http.StatusBadRequest, "Parámetro 'customer_document' o 'vehicle_id' requerido")
+			return
+		}
+		if err != nil {
+			if err.Error() == "No se encontraron cotizaciones con esos criterios" {
+				writeError(w, r, http.StatusNotFound, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		data := make(map[int]models.QuoteDoc)
+		for key, value := range quotes {
+			data[key] = mapQuoteToDoc(value)
+		}
+		writeList(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// writeFinancingError writes the status code that matches a simulation error
+func writeFinancingError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err.Error() {
+	case "Vehicle not found":
+		writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+	case "Sistema de amortización no admitido",
+		"Parámetros de financiación mal formados o fuera de rango",
+		"La tasa anual supera la máxima admitida",
+		"El anticipo debe ser menor al precio del vehículo":
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	case "El vehículo no tiene precio de lista",
+		"El vehículo está en tránsito entre sucursales":
+		writeError(w, r, http.StatusConflict, err.Error())
+	default:
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// parseDecimalParam parses an optional decimal query parameter, empty means zero
+func parseDecimalParam(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(value)
+}
+
+func mapQuoteToDoc(quote models.Quote) models.QuoteDoc {
+	plan := quote.Plan
+	return models.QuoteDoc{
+		ID:               quote.Id,
+		CustomerName:     quote.CustomerName,
+		CustomerDocument: quote.CustomerDocument,
+		VehicleId:        plan.VehicleId,
+		DownPayment:      plan.DownPayment,
+		TermMonths:       plan.TermMonths,
+		AnnualRate:       plan.AnnualRate,
+		OpeningFee:       plan.OpeningFee,
+		System:           plan.System,
+		CreatedAt:        quote.CreatedAt,
+		SalespersonId:    quote.SalespersonId,
+		Salesperson:      quote.Salesperson,
+		Plan:             &plan,
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// BuildInfo is a struct that represents the build of the running binary
+type BuildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	BuildTime  string `json:"build_time"`
+	GoVersion  string `json:"go_version"`
+	Repository string `json:"repository"`
+}
+
+// NewHealthDefault is a function that returns a new instance of HealthDefault
+func NewHealthDefault(info BuildInfo) *HealthDefault {
+	return &HealthDefault{info: info}
+}
+
+// HealthDefault is a struct with methods that represent the probes of the load balancer
+type HealthDefault struct {
+	// info is the build of the running binary
+	info BuildInfo
+	// ready tells if the data is loaded and the routes are serving
+	ready atomic.Bool
+	// draining tells if the server is shutting down
+	draining atomic.Bool
+	// mu protects checks
+	mu sync.RWMutex
+	// checks are the dependencies that must answer for the server to be ready
+	checks map[string]func(ctx context.Context) error
+}
+
+// AddCheck is a method that registers a dependency checked by the readiness probe
+func (h *HealthDefault) AddCheck(name string, check func(ctx context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.checks == nil {
+		h.checks = make(map[string]func(ctx context.Context) error)
+	}
+	h.checks[name] = check
+}
+
+// MarkReady is a method that tells the readiness probe the startup finished
+func (h *HealthDefault) MarkReady() {
+	h.ready.Store(true)
+}
+
+// MarkDraining is a method that tells the readiness probe the server is shutting down
+func (h *HealthDefault) MarkDraining() {
+	h.draining.Store(true)
+}
+
+// Healthz is a method that returns a handler for the route GET /healthz, it answers while the process is alive
+func (h *HealthDefault) Healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, map[string]any{"status": "ok"})
+	}
+}
+
+// Readyz is a method that returns a handler for the route GET /readyz, it fails while
+// the data is loading, while the server drains and when a dependency check fails
+func (h *HealthDefault) Readyz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.draining.Load() {
+			response.JSON(w, http.StatusServiceUnavailable, map[string]any{"status": "draining"})
+			return
+		}
+		if !h.ready.Load() {
+			response.JSON(w, http.StatusServiceUnavailable, map[string]any{"status": "loading"})
+			return
+		}
+
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+
+		status := http.StatusOK
+		checks := make(map[string]string)
+		for name, check := range h.checks {
+			if err := check(r.Context()); err != nil {
+				status = http.StatusServiceUnavailable
+				checks[name] = err.Error()
+				continue
+			}
+			checks[name] = "ok"
+		}
+
+		body := map[string]any{"status": "ready", "checks": checks}
+		if status != http.StatusOK {
+			body["status"] = "unavailable"
+		}
+		response.JSON(w, status, body)
+	}
+}
+
+// Version is a method that returns a handler for the route GET /version
+func (h *HealthDefault) Version() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, h.info)
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"app/internal/codec"
+	"app/internal/service"
+	"app/pkg/models"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewImportDefault is a function that returns a new instance of ImportDefault
+func NewImportDefault(sv service.ImportService, asyncRows int) *ImportDefault {
+	return &ImportDefault{sv: sv, asyncRows: asyncRows}
+}
+
+// ImportDefault is a struct with methods that represent handlers for the bulk import of vehicles
+type ImportDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.ImportService
+	// asyncRows is the number of rows from which a file is imported in the background
+	asyncRows int
+}
+
+// Import is a method that returns a handler for the route POST /vehicles/import. The body is a CSV file
+// with a header, read with the query parameters delimiter, decimal_comma and map (e.g. Marca:brand,Año:year).
+// With dry_run=true the rows are only validated.
+func (h *ImportDefault) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || !importMediaType(mediaType) {
+				writeError(w, r, http.StatusUnsupportedMediaType, "Tipo de contenido no admitido, debe ser text/csv")
+				return
+			}
+		}
+		query := r.URL.Query()
+		var opts codec.CSVOptions
+		var err error
+		if value := query.Get("delimiter"); value != "" {
+			if opts.Delimiter, err = codec.ParseDelimiter(value); err != nil {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		if opts.DecimalComma, err = queryBool(r, "decimal_comma"); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if opts.Header, err = codec.ParseHeader(query.Get("map")); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		dryRun, err := queryBool(r, "dry_run")
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// - the rows of the file
+		cr, err := codec.NewVehicleCSVReader(r.Body, opts)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		file := models.ImportFile{IgnoredColumns: cr.Ignored()}
+		for {
+			row, err := cr.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			file.Rows = append(file.Rows, row)
+		}
+
+		// process
+		if dryRun {
+			writeData(w, r, http.StatusOK, "success", h.sv.Validate(r.Context(), file))
+			return
+		}
+		job, err := h.sv.Import(r.Context(), file, len(file.Rows) >= h.asyncRows)
+		if err != nil {
+			switch err.Error() {
+			case "Algunas filas del archivo no son válidas":
+				writeErrorDetails(w, r, http.StatusUnprocessableEntity, err.Error(), job.Report)
+			case "El archivo no tiene vehículos":
+				writeError(w, r, http.StatusBadRequest, err.Error())
+			default:
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		// response
+		switch job.Status {
+		case models.ImportSucceeded:
+			writeData(w, r, http.StatusCreated, "Vehículos importados exitosamente", mapImportJobToDoc(job))
+		case models.ImportFailed:
+			status := http.StatusInternalServerError
+			if strings.Contains(job.Error, "Algún vehículo tiene un identificador ya existente") {
+				status = http.StatusConflict
+			}
+			writeErrorDetails(w, r, status, job.Error, mapImportJobToDoc(job))
+		default:
+			// large files are added in the background, the job is polled at its route
+			w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+strconv.Itoa(job.Id))
+			writeData(w, r, http.StatusAccepted, "Importación en curso", mapImportJobToDoc(job))
+		}
+	}
+}
+
+// GetJobById is a method that returns a handler for the route GET /vehicles/import/{job_id}
+func (h *ImportDefault) GetJobById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "job_id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador de la importación mal formado")
+			return
+		}
+
+		job, err := h.sv.GetJobById(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Import job not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró la importación")
+				return
+			}
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeData(w, r, http.StatusOK, "success", mapImportJobToDoc(job))
+	}
+}
+
+// importMediaType tells if a file of the media type can be imported, spreadsheets send CSV files with several of them
+func importMediaType(mediaType string) bool {
+	switch mediaType {
+	case "text/csv", "text/plain", "application/csv", "application/vnd.ms-excel", "application/octet-stream":
+		return true
+	}
+	return false
+}
+
+// queryBool returns the boolean query parameter with the name, false when it is missing
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("El parámetro " + name + " debe ser true o false")
+	}
+	return b, nil
+}
+
+func mapImportJobToDoc(job models.ImportJob) models.ImportJobDoc {
+	return models.ImportJobDoc{
+		ID:         job.Id,
+		Status:     job.Status,
+		Report:     job.Report,
+		Total:      job.Total,
+		Created:    job.Created,
+		LastLine:   job.LastLine,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// LogLevelDoc is a struct that represents the log level in JSON format
+type LogLevelDoc struct {
+	Level string `json:"level"`
+}
+
+// NewLogLevelDefault is a function that returns a new instance of LogLevelDefault
+func NewLogLevelDefault(level *slog.LevelVar) *LogLevelDefault {
+	return &LogLevelDefault{level: level}
+}
+
+// LogLevelDefault is a struct with methods that read and change the log level while the server runs
+type LogLevelDefault struct {
+	// level is the minimum level of the logger
+	level *slog.LevelVar
+}
+
+// Get is a method that returns a handler for the route GET /admin/log_level
+func (h *LogLevelDefault) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, LogLevelDoc{Level: strings.ToLower(h.level.Level().String())})
+	}
+}
+
+// Set is a method that returns a handler for the route PUT /admin/log_level
+func (h *LogLevelDefault) Set() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body LogLevelDoc
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			response.JSON(w, http.StatusBadRequest, "Cuerpo de la petición mal formado")
+			return
+		}
+
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(body.Level)); err != nil {
+			response.JSON(w, http.StatusBadRequest, "Nivel de log inválido, debe ser debug, info, warn o error")
+			return
+		}
+
+		previous := h.level.Level()
+		h.level.Set(level)
+		slog.WarnContext(r.Context(), "log level changed", "from", previous.String(), "to", level.String())
+		response.JSON(w, http.StatusOK, LogLevelDoc{Level: strings.ToLower(level.String())})
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"app/internal/service"
+	"app/pkg/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewMaintenanceDefault is a function that returns a new instance of MaintenanceDefault
+func NewMaintenanceDefault(sv service.MaintenanceService) *MaintenanceDefault {
+	return &MaintenanceDefault{sv: sv}
+}
+
+// MaintenanceDefault is a struct with methods that represent handlers for maintenance records
+type MaintenanceDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.MaintenanceService
+}
+
+// AddRecord is a method that returns a handler for the route POST /vehicles/{id}/maintenance
+func (h *MaintenanceDefault) AddRecord() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		var recordDoc models.MaintenanceRecordDoc
+		err = json.NewDecoder(r.Body).Decode(&recordDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		record, err := h.sv.AddRecord(r.Context(), id, recordDoc)
+		if err != nil {
+			switch err.Error() {
+			case "Vehicle not found":
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			case "Registro de mantenimiento mal formado o incompleto",
+				"La fecha de la lectura no puede ser futura":
+				writeError(w, r, http.StatusBadRequest, err.Error())
+			case "La fecha de la lectura es anterior a la última registrada",
+				"La lectura del odómetro no puede ser menor a la anterior":
+				writeError(w, r, http.StatusConflict, err.Error())
+			default:
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Registro de mantenimiento creado exitosamente", mapRecordToDoc(record))
+	}
+}
+
+// FindRecordsByVehicle is a method that returns a handler for the route GET /vehicles/{id}/maintenance
+func (h *MaintenanceDefault) FindRecordsByVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		records, err := h.sv.FindRecordsByVehicle(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Vehicle not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else if err.Error() == "El vehículo no tiene registros de mantenimiento" {
+				writeError(w, r, http.StatusNotFound, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		data := make([]models.MaintenanceRecordDoc, 0, len(records))
+		for _, record := range records {
+			data = append(data, mapRecordToDoc(record))
+		}
+		writeItems(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// GetReconditioningCost is a method that returns a handler for the route GET /vehicles/{id}/maintenance/cost
+func (h *MaintenanceDefault) GetReconditioningCost() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		cost, err := h.sv.GetReconditioningCost(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Vehicle not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeData(w, r, http.StatusOK, "success", cost)
+	}
+}
+
+// AddSchedule is a method that returns a handler for the route POST /vehicles/{id}/maintenance/schedules
+func (h *MaintenanceDefault) AddSchedule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		var scheduleDoc models.MaintenanceScheduleDoc
+		err = json.NewDecoder(r.Body).Decode(&scheduleDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		schedule, err := h.sv.AddSchedule(r.Context(), id, scheduleDoc)
+		if err != nil {
+			if err.Error() == "Vehicle not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else if err.Error() == "Programación de mantenimiento mal formada o sin intervalo" {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Mantenimiento programado exitosamente", mapScheduleToDoc(schedule))
+	}
+}
+
+// FindSchedulesByVehicle is a method that returns a handler for the route GET /vehicles/{id}/maintenance/schedules
+func (h *MaintenanceDefault) FindSchedulesByVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		schedules, err := h.sv.FindSchedulesByVehicle(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Vehicle not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else if err.Error() == "El vehículo no tiene mantenimientos programados" {
+				writeError(w, r, http.StatusNotFound, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		data := make(map[int]models.MaintenanceScheduleDoc)
+		for key, value := range schedules {
+			data[key] = mapScheduleToDoc(value)
+		}
+		writeList(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// FindOverdue is a method that returns a handler for the route GET /vehicles/maintenance/overdue
+func (h *MaintenanceDefault) FindOverdue() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		overdue, err := h.sv.FindOverdue(r.Context())
+		if err != nil {
+			if err.Error() == "No hay vehículos con mantenimiento vencido" {
+				writeError(w, r, http.StatusNotFound, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeItems(w, r, http.StatusOK, "success", overdue)
+	}
+}
+
+func mapRecordToDoc(record models.MaintenanceRecord) models.MaintenanceRecordDoc {
+	parts := record.Parts
+	if parts == nil {
+		parts = []models.MaintenancePart{}
+	}
+	return models.MaintenanceRecordDoc{
+		ID:         record.Id,
+		VehicleId:  record.VehicleId,
+		Date:       record.Date,
+		Type:       record.Type,
+		Parts:      parts,
+		LaborCost:  record.LaborCost,
+		Odometer:   record.Odometer,
+		Notes:      record.Notes,
+		TotalCost:  record.PartsCost().Add(record.LaborCost),
+		RecordedBy: record.RecordedBy,
+	}
+}
+
+func mapScheduleToDoc(schedule models.MaintenanceSchedule) models.MaintenanceScheduleDoc {
+	return models.MaintenanceScheduleDoc{
+		ID:            schedule.Id,
+		VehicleId:     schedule.VehicleId,
+		Type:          schedule.Type,
+		IntervalDays:  schedule.IntervalDays,
+		IntervalKm:    schedule.IntervalKm,
+		StartDate:     schedule.StartDate,
+		StartOdometer: schedule.StartOdometer,
+	}
+}
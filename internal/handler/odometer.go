@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"app/internal/service"
+	"app/pkg/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewOdometerDefault is a function that returns a new instance of OdometerDefault
+func NewOdometerDefault(sv service.OdometerService) *OdometerDefault {
+	return &OdometerDefault{sv: sv}
+}
+
+// OdometerDefault is a struct with methods that represent handlers for odometer readings
+type OdometerDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.OdometerService
+}
+
+// AddReading is a method that returns a handler for the route POST /vehicles/{id}/odometer
+func (h *OdometerDefault) AddReading() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		var readingDoc models.OdometerReadingDoc
+		err = json.NewDecoder(r.Body).Decode(&readingDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		reading, err := h.sv.AddReading(r.Context(), id, readingDoc)
+		if err != nil {
+			switch err.Error() {
+			case "Vehicle not found":
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			case "Lectura de odómetro mal formada o incompleta",
+				"La fecha de la lectura no puede ser futura",
+				"El reemplazo de odómetro requiere un motivo":
+				writeError(w, r, http.StatusBadRequest, err.Error())
+			case "La fecha de la lectura es anterior a la última registrada",
+				"La lectura del odómetro no puede ser menor a la anterior":
+				writeError(w, r, http.StatusConflict, err.Error())
+			default:
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Lectura de odómetro registrada exitosamente", mapReadingToDoc(reading))
+	}
+}
+
+// FindReadingsByVehicle is a method that returns a handler for the route GET /vehicles/{id}/odometer
+func (h *OdometerDefault) FindReadingsByVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		readings, err := h.sv.FindReadingsByVehicle(r.Context(), id)
+		if err != nil {
+			if err.Error() == "Vehicle not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else if err.Error() == "El vehículo no tiene lecturas de odómetro" {
+				writeError(w, r, http.StatusNotFound, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		data := make([]models.OdometerReadingDoc, 0, len(readings))
+		for _, reading := range readings {
+			data = append(data, mapReadingToDoc(reading))
+		}
+		writeItems(w, r, http.StatusOK, "success", data)
+	}
+}
+
+func mapReadingToDoc(reading models.OdometerReading) models.OdometerReadingDoc {
+	return models.OdometerReadingDoc{
+		ID:             reading.Id,
+		VehicleId:      reading.VehicleId,
+		Value:          reading.Value,
+		Date:           reading.Date,
+		Source:         reading.Source,
+		Override:       reading.Override,
+		OverrideReason: reading.OverrideReason,
+		PreviousValue:  reading.PreviousValue,
+		RecordedBy:     reading.RecordedBy,
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"app/internal/loader"
+	"errors"
+	"net/http"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// NewReloadDefault is a function that returns a new instance of ReloadDefault
+func NewReloadDefault(rl *loader.Reloader, merge bool) *ReloadDefault {
+	return &ReloadDefault{rl: rl, merge: merge}
+}
+
+// ReloadDefault is a struct with methods that reload the vehicles from their files while the server runs
+type ReloadDefault struct {
+	// rl loads the files and swaps the vehicles into the repository
+	rl *loader.Reloader
+	// merge keeps the changes made through the API unless the request says otherwise
+	merge bool
+}
+
+// Reload is a method that returns a handler for the route POST /admin/reload, the query parameter
+// merge overrides whether the changes made through the API are kept
+func (h *ReloadDefault) Reload() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		merge := h.merge
+		if r.URL.Query().Has("merge") {
+			var err error
+			if merge, err = queryBool(r, "merge"); err != nil {
+				response.JSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		report, err := h.rl.Reload(r.Context(), merge)
+		switch {
+		case errors.Is(err, loader.ErrNotReady):
+			w.Header().Set("Retry-After", "1")
+			response.JSON(w, http.StatusServiceUnavailable, "Los vehículos todavía se están cargando")
+		case errors.Is(err, loader.ErrInvalidRecords):
+			// the report tells which records were rejected
+			response.JSON(w, http.StatusUnprocessableEntity, report)
+		case err != nil:
+			response.JSON(w, http.StatusInternalServerError, "No se pudieron recargar los vehículos, se mantienen los actuales: "+err.Error())
+		default:
+			response.JSON(w, http.StatusOK, report)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"app/internal/codec"
+	"app/internal/envelope"
+	"app/pkg/models"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// The versioned API writes every response in the envelope {data, meta, error} with the
+// collections as arrays ordered by id, the deprecated routes keep the body they always had.
+
+// writeError writes the reason a request failed
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	envelope.WriteError(w, r, status, message)
+}
+
+// writeErrorDetails writes the reason a request failed with the details that explain it
+func writeErrorDetails(w http.ResponseWriter, r *http.Request, status int, message string, details any) {
+	envelope.WriteErrorDetails(w, r, status, message, details)
+}
+
+// write writes data in the envelope for the versioned API, or legacy for the deprecated routes
+func write(w http.ResponseWriter, r *http.Request, status int, legacy any, data any, meta envelope.Meta) {
+	if !envelope.Versioned(r.Context()) {
+		response.JSON(w, status, legacy)
+		return
+	}
+	envelope.Write(w, r, status, data, meta)
+}
+
+// writeData writes a result that the deprecated routes send as {"message", "data"}
+func writeData(w http.ResponseWriter, r *http.Request, status int, message string, data any) {
+	meta := envelope.Meta{}
+	if message != "success" {
+		meta.Message = message
+	}
+	write(w, r, status, map[string]any{"message": message, "data": data}, data, meta)
+}
+
+// writeMessage writes the outcome of a change that has no data, the deprecated routes send it as a bare string
+func writeMessage(w http.ResponseWriter, r *http.Request, status int, message string) {
+	write(w, r, status, message, nil, envelope.Meta{Message: message})
+}
+
+// writeList writes a collection keyed by id that the deprecated routes send as {"message", "data"}
+func writeList[T any](w http.ResponseWriter, r *http.Request, status int, message string, data map[int]T) {
+	list := sortedById(data)
+	write(w, r, status, map[string]any{"message": message, "data": data}, list, envelope.Count(len(list)))
+}
+
+// writeItems writes a collection that the deprecated routes send as {"message", "data"}
+func writeItems[T any](w http.ResponseWriter, r *http.Request, status int, message string, data []T) {
+	write(w, r, status, map[string]any{"message": message, "data": data}, data, envelope.Count(len(data)))
+}
+
+// writeVehicles writes vehicles that the deprecated routes send as a bare map of models.Vehicle
+func writeVehicles(w http.ResponseWriter, r *http.Request, status int, vehicles map[int]models.Vehicle) {
+	streamVehicles(w, r, status, vehicles, func() any {
+		return vehicles
+	})
+}
+
+// writeVehicleDocs writes vehicles that the deprecated routes send as {"message", "data"} keyed by id
+func writeVehicleDocs(w http.ResponseWriter, r *http.Request, status int, vehicles map[int]models.Vehicle) {
+	streamVehicles(w, r, status, vehicles, func() any {
+		data := make(map[int]models.VehicleDoc)
+		for key, value := range vehicles {
+			data[key] = mapVehicleToDoc(value)
+		}
+		return map[string]any{"message": "success", "data": data}
+	})
+}
+
+// streamVehicles writes vehicles in the format the client asks for with Accept or the query parameter
+// format, a vehicle at a time. In JSON the deprecated routes send the body of legacy.
+func streamVehicles(w http.ResponseWriter, r *http.Request, status int, vehicles map[int]models.Vehicle, legacy func() any) {
+	w.Header().Add("Vary", "Accept")
+	f, err := codec.Negotiate(r)
+	if err != nil {
+		if errors.Is(err, codec.ErrNotAcceptable) {
+			writeError(w, r, http.StatusNotAcceptable, err.Error())
+			return
+		}
+		writeError(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var vw codec.VehicleWriter
+	switch {
+	case f == codec.JSON && !envelope.Versioned(r.Context()):
+		response.JSON(w, status, legacy())
+		return
+	case f == codec.JSON:
+		// the count is known before the first vehicle, the envelope is written around them
+		vw = vehicleListWriter{envelope.NewListWriter(w, r, status, envelope.Count(len(vehicles)))}
+	default:
+		w.Header().Set("Content-Type", f.ContentType())
+		if f == codec.CSV {
+			w.Header().Set("Content-Disposition", `attachment; filename="vehicles.csv"`)
+		}
+		w.WriteHeader(status)
+		vw = codec.NewVehicleWriter(w, f)
+	}
+	for _, id := range sortedIds(vehicles) {
+		if err := vw.Write(mapVehicleToDoc(vehicles[id])); err != nil {
+			// the status is sent, the client only sees the output cut short
+			slog.WarnContext(r.Context(), "vehicles export interrupted", "format", f, "error", err)
+			return
+		}
+	}
+	if err := vw.Close(); err != nil {
+		slog.WarnContext(r.Context(), "vehicles export interrupted", "format", f, "error", err)
+	}
+}
+
+// vehicleListWriter is a struct that writes the vehicles in the envelope of the versioned API
+type vehicleListWriter struct {
+	*envelope.ListWriter
+}
+
+// Write writes a vehicle
+func (v vehicleListWriter) Write(doc models.VehicleDoc) error {
+	return v.ListWriter.Write(doc)
+}
+
+// sortedById returns the values of a collection keyed by id ordered by id
+func sortedById[T any](data map[int]T) []T {
+	list := make([]T, 0, len(data))
+	for _, id := range sortedIds(data) {
+		list = append(list, data[id])
+	}
+	return list
+}
+
+// sortedIds returns the ids of a collection keyed by id in order
+func sortedIds[T any](data map[int]T) []int {
+	ids := make([]int, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
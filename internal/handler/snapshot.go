@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"app/internal/loader"
+	"app/internal/snapshot"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// NewSnapshotDefault is a function that returns a new instance of SnapshotDefault
+func NewSnapshotDefault(sn *snapshot.Snapshotter) *SnapshotDefault {
+	return &SnapshotDefault{sn: sn}
+}
+
+// SnapshotDefault is a struct with methods that export all the vehicles of the running server
+type SnapshotDefault struct {
+	// sn takes the copies of the vehicles
+	sn *snapshot.Snapshotter
+}
+
+// Get is a method that returns a handler for the route GET /admin/snapshot, the vehicles as a JSON
+// file the loader reads back
+func (h *SnapshotDefault) Get() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, at, err := h.sn.Take(r.Context())
+		if err != nil {
+			if errors.Is(err, snapshot.ErrNotReady) {
+				w.Header().Set("Retry-After", "1")
+				response.JSON(w, http.StatusServiceUnavailable, "Los vehículos todavía se están cargando")
+				return
+			}
+			response.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+snapshot.Name(at)+`"`)
+		w.Header().Set("Last-Modified", at.UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		if err := loader.WriteJSON(w, v); err != nil {
+			// the status is already sent, the client sees a truncated file
+			slog.ErrorContext(r.Context(), "writing snapshot", "error", err)
+			return
+		}
+		slog.InfoContext(r.Context(), "snapshot exported", "count", len(v))
+	}
+}
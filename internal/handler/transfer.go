@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"app/internal/service"
+	"app/pkg/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewTransferDefault is a function that returns a new instance of TransferDefault
+func NewTransferDefault(sv service.TransferService) *TransferDefault {
+	return &TransferDefault{sv: sv}
+}
+
+// TransferDefault is a struct with methods that represent handlers for transfers between branches
+type TransferDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.TransferService
+}
+
+// RequestTransfer is a method that returns a handler for the route POST /transfers
+func (h *TransferDefault) RequestTransfer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var transferDoc models.TransferDoc
+		err := json.NewDecoder(r.Body).Decode(&transferDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		transfer, err := h.sv.RequestTransfer(r.Context(), transferDoc)
+		if err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Traslado solicitado exitosamente", mapTransferToDoc(transfer))
+	}
+}
+
+// Dispatch is a method that returns a handler for the route PUT /transfers/{id}/dispatch
+func (h *TransferDefault) Dispatch() http.HandlerFunc {
+	return h.transition(h.sv.Dispatch, "Vehículo despachado exitosamente")
+}
+
+// Receive is a method that returns a handler for the route PUT /transfers/{id}/receive
+func (h *TransferDefault) Receive() http.HandlerFunc {
+	return h.transition(h.sv.Receive, "Vehículo recibido exitosamente")
+}
+
+// Cancel is a method that returns a handler for the route PUT /transfers/{id}/cancel
+func (h *TransferDefault) Cancel() http.HandlerFunc {
+	return h.transition(h.sv.Cancel, "Traslado cancelado exitosamente")
+}
+
+// GetTransferById is a method that returns a handler for the route GET /transfers/{id}
+func (h *TransferDefault) GetTransferById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del traslado mal formado")
+			return
+		}
+
+		transfer, err := h.sv.GetTransferById(r.Context(), id)
+		if err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		writeData(w, r, http.StatusOK, "success", mapTransferToDoc(transfer))
+	}
+}
+
+// FindTransfers is a method that returns a handler for the route GET /transfers, optionally filtered by status
+func (h *TransferDefault) FindTransfers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transfers, err := h.sv.FindTransfers(r.Context(), r.URL.Query().Get("status"))
+		if err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		data := make(map[int]models.TransferDoc)
+		for key, value := range transfers {
+			data[key] = mapTransferToDoc(value)
+		}
+		writeList(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// FindTransfersByVehicle is a method that returns a handler for the route GET /vehicles/{id}/transfers
+func (h *TransferDefault) FindTransfersByVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		transfers, err := h.sv.FindTransfersByVehicle(r.Context(), id)
+		if err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		data := make([]models.TransferDoc, 0, len(transfers))
+		for _, transfer := range transfers {
+			data = append(data, mapTransferToDoc(transfer))
+		}
+		writeItems(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// transition returns a handler that applies a workflow step to the transfer in the URL
+func (h *TransferDefault) transition(step func(ctx context.Context, id int) (models.Transfer, error), message string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del traslado mal formado")
+			return
+		}
+
+		transfer, err := step(r.Context(), id)
+		if err != nil {
+			writeTransferError(w, r, err)
+			return
+		}
+
+		writeData(w, r, http.StatusOK, message, mapTransferToDoc(transfer))
+	}
+}
+
+// writeTransferError writes the status code that matches a transfer error
+func writeTransferError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err.Error() {
+	case "Vehicle not found":
+		writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+	case "Branch not found":
+		writeError(w, r, http.StatusNotFound, "No se encontró la sucursal")
+	case "Transfer not found":
+		writeError(w, r, http.StatusNotFound, "No se encontró el traslado")
+	case "No se encontraron traslados con esos criterios":
+		writeError(w, r, http.StatusNotFound, err.Error())
+	case "El vehículo ya se encuentra en esa sucursal",
+		"El vehículo ya tiene un traslado en curso",
+		"El traslado no admite esa transición":
+		writeError(w, r, http.StatusConflict, err.Error())
+	default:
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func mapTransferToDoc(transfer models.Transfer) models.TransferDoc {
+	return models.TransferDoc{
+		ID:           transfer.Id,
+		VehicleId:    transfer.VehicleId,
+		FromBranchId: transfer.FromBranchId,
+		ToBranchId:   transfer.ToBranchId,
+		Status:       transfer.Status,
+		Notes:        transfer.Notes,
+		RequestedAt:  transfer.RequestedAt,
+		DispatchedAt: transfer.DispatchedAt,
+		ReceivedAt:   transfer.ReceivedAt,
+		CancelledAt:  transfer.CancelledAt,
+		RequestedBy:  transfer.RequestedBy,
+		DispatchedBy: transfer.DispatchedBy,
+		ReceivedBy:   transfer.ReceivedBy,
+		CancelledBy:  transfer.CancelledBy,
+	}
+}
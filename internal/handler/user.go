@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"app/internal/envelope"
+	"app/internal/service"
+	"app/pkg/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewUserDefault is a function that returns a new instance of UserDefault
+func NewUserDefault(sv service.UserService) *UserDefault {
+	return &UserDefault{sv: sv}
+}
+
+// UserDefault is a struct with methods that represent handlers for users and their sessions
+type UserDefault struct {
+	// sv is the service that will be used by the handler
+	sv service.UserService
+}
+
+// GetAll is a method that returns a handler for the route GET /users
+func (h *UserDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, err := h.sv.FindAll(r.Context())
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		data := make(map[int]models.UserDoc)
+		for key, value := range u {
+			data[key] = mapUserToDoc(value)
+		}
+		writeList(w, r, http.StatusOK, "success", data)
+	}
+}
+
+// CreateUser is a method that returns a handler for the route POST /users
+func (h *UserDefault) CreateUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var userDoc models.UserDoc
+		if err := json.NewDecoder(r.Body).Decode(&userDoc); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		user, err := h.sv.CreateUser(r.Context(), userDoc)
+		if err != nil {
+			writeUserError(w, r, err)
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Usuario creado exitosamente", mapUserToDoc(user))
+	}
+}
+
+// GetUserById is a method that returns a handler for the route GET /users/{id}
+func (h *UserDefault) GetUserById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del usuario mal formado")
+			return
+		}
+
+		user, err := h.sv.GetUserById(r.Context(), id)
+		if err != nil {
+			writeUserError(w, r, err)
+			return
+		}
+
+		writeData(w, r, http.StatusOK, "success", mapUserToDoc(user))
+	}
+}
+
+// RevokeTokens is a method that returns a handler for the route POST /users/{id}/revoke_tokens
+func (h *UserDefault) RevokeTokens() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del usuario mal formado")
+			return
+		}
+
+		if err = h.sv.RevokeTokens(r.Context(), id); err != nil {
+			writeUserError(w, r, err)
+			return
+		}
+
+		writeMessage(w, r, http.StatusOK, "Sesiones del usuario revocadas exitosamente")
+	}
+}
+
+// RequestPasswordReset is a method that returns a handler for the route POST /users/{id}/password_reset.
+// The token is returned to the admin, who hands it to the user.
+func (h *UserDefault) RequestPasswordReset() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del usuario mal formado")
+			return
+		}
+
+		reset, err := h.sv.RequestPasswordReset(r.Context(), id)
+		if err != nil {
+			writeUserError(w, r, err)
+			return
+		}
+
+		writeData(w, r, http.StatusCreated, "Token de restablecimiento generado exitosamente", reset)
+	}
+}
+
+// Login is a method that returns a handler for the route POST /auth/login
+func (h *UserDefault) Login() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		tokens, err := h.sv.Login(r.Context(), body.Username, body.Password)
+		if err != nil {
+			writeUserError(w, r, err)
+			return
+		}
+
+		write(w, r, http.StatusOK, tokens, tokens, envelope.Meta{})
+	}
+}
+
+// Refresh is a method that returns a handler for the route POST /auth/refresh
+func (h *UserDefault) Refresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		tokens, err := h.sv.Refresh(r.Context(), body.RefreshToken)
+		if err != nil {
+			writeUserError(w, r, err)
+			return
+		}
+
+		write(w, r, http.StatusOK, tokens, tokens, envelope.Meta{})
+	}
+}
+
+// Logout is a method that returns a handler for the route POST /auth/logout
+func (h *UserDefault) Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the refresh token is optional
+		var body struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		if err := h.sv.Logout(r.Context(), body.RefreshToken); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeMessage(w, r, http.StatusOK, "Sesión cerrada exitosamente")
+	}
+}
+
+// ResetPassword is a method that returns a handler for the route POST /auth/password_reset
+func (h *UserDefault) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Token    string `json:"token"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := h.sv.ResetPassword(r.Context(), body.Token, body.Password); err != nil {
+			writeUserError(w, r, err)
+			return
+		}
+
+		writeMessage(w, r, http.StatusOK, "Contraseña actualizada exitosamente")
+	}
+}
+
+func writeUserError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err.Error() {
+	case "User not found":
+		writeError(w, r, http.StatusNotFound, "No se encontró el usuario")
+	case "Username already exists":
+		writeError(w, r, http.StatusConflict, "El nombre de usuario ya existe")
+	case "Datos del usuario incompletos o mal formados",
+		"Rol inválido, debe ser viewer, salesperson o admin",
+		"La contraseña debe tener entre 8 y 72 caracteres",
+		"Token de restablecimiento inválido o vencido":
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	case "Usuario o contraseña incorrectos",
+		"Token de refresco inválido o vencido":
+		writeError(w, r, http.StatusUnauthorized, err.Error())
+	default:
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func mapUserToDoc(user models.User) models.UserDoc {
+	return models.UserDoc{
+		ID:        user.Id,
+		Username:  user.Username,
+		FullName:  user.FullName,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+	}
+}
@@ -1,14 +1,15 @@
 package handler
 
 import (
+	"app/internal/envelope"
 	"app/internal/service"
 	"app/pkg/models"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/bootcamp-go/web/response"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -31,36 +32,20 @@ func (h *VehicleDefault) GetAll() http.HandlerFunc {
 
 		// process
 		// - get all vehicles
-		v, err := h.sv.FindAll()
+		v, err := h.sv.FindAll(r.Context())
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, nil)
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
-		// response
-		data := make(map[int]models.VehicleDoc)
-		for key, value := range v {
-			data[key] = models.VehicleDoc{
-				ID:              value.Id,
-				Brand:           value.Brand,
-				Model:           value.Model,
-				Registration:    value.Registration,
-				Color:           value.Color,
-				FabricationYear: value.FabricationYear,
-				Capacity:        value.Capacity,
-				MaxSpeed:        value.MaxSpeed,
-				FuelType:        value.FuelType,
-				Transmission:    value.Transmission,
-				Weight:          value.Weight,
-				Height:          value.Height,
-				Length:          value.Length,
-				Width:           value.Width,
-			}
+		v, err = filterByBranch(r, v)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
 		}
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
-		})
+
+		// response
+		writeVehicleDocs(w, r, http.StatusOK, v)
 	}
 }
 
@@ -72,22 +57,22 @@ func (h *VehicleDefault) AddVehicle() http.HandlerFunc {
 		vehicle := models.VehicleDoc{}
 		err := json.NewDecoder(body).Decode(&vehicle)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, err.Error())
+			writeError(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		_, err = h.sv.AddVehicle(vehicle)
+		_, err = h.sv.AddVehicle(r.Context(), vehicle)
 		if err != nil {
 			if err.Error() == "Identificador del vehículo ya existente" {
-				response.JSON(w, http.StatusConflict, err.Error())
-			} else if err.Error() == "Campos incompletos o mal formados" {
-				response.JSON(w, http.StatusBadRequest, err.Error())
+				writeError(w, r, http.StatusConflict, err.Error())
+			} else if err.Error() == "Campos incompletos o mal formados" || err.Error() == "Un vehículo nuevo solo puede estar disponible" || err.Error() == "La sucursal del vehículo no existe" {
+				writeError(w, r, http.StatusBadRequest, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusCreated, "Vehículo creado exitosamente")
+		writeMessage(w, r, http.StatusCreated, "Vehículo creado exitosamente")
 	}
 }
 
@@ -99,42 +84,25 @@ func (h *VehicleDefault) FindVehiclesByColorAndYear() http.HandlerFunc {
 		year := chi.URLParam(r, "year")
 
 		// - get vehicles filtered by color and year
-		v, err := h.sv.FindVehiclesByColorAndYear(color, year)
+		v, err := h.sv.FindVehiclesByColorAndYear(r.Context(), color, year)
 		if err != nil {
 			// specify error
 			if err.Error() == "No se encontraron vehículos con esos criterios" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
 
-		// response
-		data := make(map[int]models.VehicleDoc)
-
-		for key, value := range v {
-			data[key] = models.VehicleDoc{
-				ID:              value.Id,
-				Brand:           value.Brand,
-				Model:           value.Model,
-				Registration:    value.Registration,
-				Color:           value.Color,
-				FabricationYear: value.FabricationYear,
-				Capacity:        value.Capacity,
-				MaxSpeed:        value.MaxSpeed,
-				FuelType:        value.FuelType,
-				Transmission:    value.Transmission,
-				Weight:          value.Weight,
-				Height:          value.Height,
-				Length:          value.Length,
-				Width:           value.Width,
-			}
+		v, err = filterByBranch(r, v)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
 		}
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
-		})
+
+		// response
+		writeVehicleDocs(w, r, http.StatusOK, v)
 	}
 }
 
@@ -144,25 +112,33 @@ func (h *VehicleDefault) FindVhehiclesByBrandAndRangeYears() http.HandlerFunc {
 
 		startYear, err := strconv.Atoi(chi.URLParam(r, "start_year"))
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "Eror al convertir año de inicio")
+			writeError(w, r, http.StatusBadRequest, "Año de inicio mal formado")
+			return
 		}
 
 		endYear, err := strconv.Atoi(chi.URLParam(r, "end_year"))
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, "Eror al convertir año de finalización")
+			writeError(w, r, http.StatusBadRequest, "Año de finalización mal formado")
+			return
 		}
 
-		vehicles, err := h.sv.FindVehiclesByBrandAndRangeYears(brand, startYear, endYear)
+		vehicles, err := h.sv.FindVehiclesByBrandAndRangeYears(r.Context(), brand, startYear, endYear)
 
 		if err != nil {
 			if err.Error() == "No se encontraron vehículos con esos criterios" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusOK, vehicles)
+		vehicles, err = filterByBranch(r, vehicles)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeVehicles(w, r, http.StatusOK, vehicles)
 	}
 }
 
@@ -170,18 +146,18 @@ func (h *VehicleDefault) FindAverageOfSpeedByBrand() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		brand := chi.URLParam(r, "brand")
 
-		average, err := h.sv.FindAverageOfSpeedByBrand(brand)
+		average, err := h.sv.FindAverageOfSpeedByBrand(r.Context(), brand)
 
 		if err != nil {
 			if err.Error() == "No se encontraron vehículos de esa marca" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
 
-		response.JSON(w, http.StatusOK, average)
+		write(w, r, http.StatusOK, average, map[string]any{"brand": brand, "average_speed": average}, envelope.Meta{})
 	}
 }
 
@@ -191,22 +167,22 @@ func (h *VehicleDefault) AddMultipleVehicles() http.HandlerFunc {
 		var vehicles []models.VehicleDoc
 		err := json.NewDecoder(body).Decode(&vehicles)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
-		err = h.sv.AddMultipleVehicles(vehicles)
+		err = h.sv.AddMultipleVehicles(r.Context(), vehicles)
 		if err != nil {
 			if err.Error() == "Algún vehículo tiene un identificador ya existente" {
-				response.JSON(w, http.StatusConflict, err.Error())
-			} else if err.Error() == "Datos de algún vehículo mal formados o incompletos" {
-				response.JSON(w, http.StatusBadRequest, err.Error())
+				writeError(w, r, http.StatusConflict, err.Error())
+			} else if err.Error() == "Datos de algún vehículo mal formados o incompletos" || err.Error() == "Un vehículo nuevo solo puede estar disponible" || err.Error() == "La sucursal del vehículo no existe" {
+				writeError(w, r, http.StatusBadRequest, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusCreated, "Vehículos creados exitosamente")
+		writeMessage(w, r, http.StatusCreated, "Vehículos creados exitosamente")
 	}
 }
 
@@ -214,7 +190,8 @@ func (h *VehicleDefault) UpdateMaxSpeed() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
 		}
 
 		body := r.Body
@@ -222,22 +199,23 @@ func (h *VehicleDefault) UpdateMaxSpeed() http.HandlerFunc {
 		var vehicleDoc models.VehicleDoc
 		err = json.NewDecoder(body).Decode(&vehicleDoc)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, "Cuerpo de la petición mal formado")
+			return
 		}
 
-		err = h.sv.UpdateMaxSpeed(id, vehicleDoc.MaxSpeed)
+		err = h.sv.UpdateMaxSpeed(r.Context(), id, vehicleDoc.MaxSpeed)
 		if err != nil {
 			if err.Error() == "Velocidad mal formada o fuera de rango." {
-				response.JSON(w, http.StatusBadRequest, err.Error())
+				writeError(w, r, http.StatusBadRequest, err.Error())
 			} else if err.Error() == "Vehicle not found" {
-				response.JSON(w, http.StatusNotFound, "No se encontró el vehículo")
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
 
-		response.JSON(w, http.StatusOK, "Velocidad del vehículo actualizada exitosamente")
+		writeMessage(w, r, http.StatusOK, "Velocidad del vehículo actualizada exitosamente")
 	}
 }
 
@@ -245,15 +223,21 @@ func (h *VehicleDefault) GetVehicleById() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
 		}
 
-		vehicle, err := h.sv.GetVehicleById(id)
+		vehicle, err := h.sv.GetVehicleById(r.Context(), id)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, err.Error())
+			if err.Error() == "Vehicle not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
 		}
 
-		response.JSON(w, http.StatusOK, vehicle)
+		write(w, r, http.StatusOK, vehicle, mapVehicleToDoc(vehicle), envelope.Meta{})
 	}
 }
 
@@ -261,17 +245,23 @@ func (h *VehicleDefault) FindVehiclesByFuel() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		fuel := chi.URLParam(r, "type")
 
-		vehicles, err := h.sv.FindVehiclesByFuel(fuel)
+		vehicles, err := h.sv.FindVehiclesByFuel(r.Context(), fuel)
 		if err != nil {
 			if err.Error() == "No se encontraron vehículos con ese tipo de combustible" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusBadRequest, err.Error())
+				writeError(w, r, http.StatusBadRequest, err.Error())
 			}
 			return
 		}
 
-		response.JSON(w, http.StatusOK, vehicles)
+		vehicles, err = filterByBranch(r, vehicles)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeVehicles(w, r, http.StatusOK, vehicles)
 	}
 }
 
@@ -279,19 +269,22 @@ func (h *VehicleDefault) DeleteVehicle() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
 		}
 
-		err = h.sv.DeleteVehicle(id)
+		err = h.sv.DeleteVehicle(r.Context(), id)
 		if err != nil {
 			if err.Error() == "Vehicle not found" {
-				response.JSON(w, http.StatusNotFound, "No se encontró el vehículo")
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else if err.Error() == "El vehículo tiene un traslado en curso" {
+				writeError(w, r, http.StatusConflict, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusNoContent, map[string]string{"message": "Vehículo eliminado exitosamente"})
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
@@ -299,16 +292,22 @@ func (h *VehicleDefault) FindVehiclesBytransmission() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		transmisiion := chi.URLParam(r, "type")
 
-		vehicles, err := h.sv.FindVehiclesByTransmission(transmisiion)
+		vehicles, err := h.sv.FindVehiclesByTransmission(r.Context(), transmisiion)
 		if err != nil {
 			if err.Error() == "No se encontraron vehículos con ese tipo de transmisión" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusOK, vehicles)
+		vehicles, err = filterByBranch(r, vehicles)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeVehicles(w, r, http.StatusOK, vehicles)
 	}
 }
 
@@ -316,25 +315,30 @@ func (h *VehicleDefault) UpdateFuel() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
 		}
 
 		var vehicleDoc models.VehicleDoc
 		body := r.Body
 		err = json.NewDecoder(body).Decode(&vehicleDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Cuerpo de la petición mal formado")
+			return
+		}
 
-		err = h.sv.UpdateFuel(id, vehicleDoc)
+		err = h.sv.UpdateFuel(r.Context(), id, vehicleDoc)
 		if err != nil {
 			if err.Error() == "Vehicle not found" {
-				response.JSON(w, http.StatusNotFound, "No se encontró el vehículo")
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
 			} else if err.Error() == "Tipo de combustible mal formado o no admitido" {
-				response.JSON(w, http.StatusBadRequest, err.Error())
+				writeError(w, r, http.StatusBadRequest, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusOK, "Tipo de combustible del vehículo actualizado exitosamente")
+		writeMessage(w, r, http.StatusOK, "Tipo de combustible del vehículo actualizado exitosamente")
 	}
 
 }
@@ -343,16 +347,16 @@ func (h *VehicleDefault) GetAveragePeopleCapacityByBrand() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		brand := chi.URLParam(r, "brand")
 
-		average, err := h.sv.GetAveragePeopleCapacityByBrand(brand)
+		average, err := h.sv.GetAveragePeopleCapacityByBrand(r.Context(), brand)
 		if err != nil {
 			if err.Error() == "No se encontraron vehículos de esa marca" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusOK, average)
+		write(w, r, http.StatusOK, average, map[string]any{"brand": brand, "average_capacity": average}, envelope.Meta{})
 	}
 }
 
@@ -365,44 +369,50 @@ func (h *VehicleDefault) FindVehiclesByDimensions() http.HandlerFunc {
 		widthRangeArr := strings.Split(width, "-")
 
 		if len(lengthRangeArr) != 2 || len(widthRangeArr) != 2 {
-			response.JSON(w, http.StatusBadRequest, "Rango de longitud o ancho mal formado")
+			writeError(w, r, http.StatusBadRequest, "Rango de longitud o ancho mal formado")
 			return
 		}
 
 		minLength, err := strconv.ParseFloat(lengthRangeArr[0], 64)
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, err.Error())
+			writeError(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
 		maxLength, err := strconv.ParseFloat(lengthRangeArr[1], 64)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "Longitud máxima inválida")
+			writeError(w, r, http.StatusBadRequest, "Longitud máxima inválida")
 			return
 		}
 
 		minWidth, err := strconv.ParseFloat(widthRangeArr[0], 64)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "Ancho mínimo inválido")
+			writeError(w, r, http.StatusBadRequest, "Ancho mínimo inválido")
 			return
 		}
 
 		maxWidth, err := strconv.ParseFloat(widthRangeArr[1], 64)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "Ancho máximo inválido")
+			writeError(w, r, http.StatusBadRequest, "Ancho máximo inválido")
 			return
 		}
 
-		vehicles, err := h.sv.FindVehiclesByDimensions(minLength, maxLength, minWidth, maxWidth)
+		vehicles, err := h.sv.FindVehiclesByDimensions(r.Context(), minLength, maxLength, minWidth, maxWidth)
 		if err != nil {
 			if err.Error() == "No se encontraron vehículos con esas dimensiones" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
-		response.JSON(w, http.StatusOK, vehicles)
+		vehicles, err = filterByBranch(r, vehicles)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeVehicles(w, r, http.StatusOK, vehicles)
 	}
 }
 
@@ -412,30 +422,157 @@ func (h *VehicleDefault) FindVehiclesByWeigth() http.HandlerFunc {
 		max := r.URL.Query().Get("max")
 
 		if min == "" || max == "" {
-			response.JSON(w, http.StatusBadRequest, "Parámetros 'min' y 'max' son requeridos")
+			writeError(w, r, http.StatusBadRequest, "Parámetros 'min' y 'max' son requeridos")
 			return
 		}
 
 		minWeigth, err := strconv.ParseFloat(min, 64)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "peso minimo invalido")
+			writeError(w, r, http.StatusBadRequest, "Peso mínimo inválido")
+			return
 		}
 
 		maxWeigth, err := strconv.ParseFloat(max, 64)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, "peso maximo invalido")
+			writeError(w, r, http.StatusBadRequest, "Peso máximo inválido")
+			return
 		}
 
-		vehicles, err := h.sv.FindVehiclesByWeigth(minWeigth, maxWeigth)
+		vehicles, err := h.sv.FindVehiclesByWeigth(r.Context(), minWeigth, maxWeigth)
 		if err != nil {
 			if err.Error() == "No se encontraron vehículos en ese rango de peso" {
-				response.JSON(w, http.StatusNotFound, err.Error())
+				writeError(w, r, http.StatusNotFound, err.Error())
 			} else {
-				response.JSON(w, http.StatusInternalServerError, err.Error())
+				writeError(w, r, http.StatusInternalServerError, err.Error())
 			}
 			return
 		}
 
-		response.JSON(w, http.StatusOK, vehicles)
+		vehicles, err = filterByBranch(r, vehicles)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeVehicles(w, r, http.StatusOK, vehicles)
+	}
+}
+
+func (h *VehicleDefault) UpdatePrice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Identificador del vehículo mal formado")
+			return
+		}
+
+		var vehicleDoc models.VehicleDoc
+		err = json.NewDecoder(r.Body).Decode(&vehicleDoc)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		err = h.sv.UpdatePrice(r.Context(), id, vehicleDoc.Price)
+		if err != nil {
+			if err.Error() == "Precio mal formado o fuera de rango" {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+			} else if err.Error() == "Vehicle not found" {
+				writeError(w, r, http.StatusNotFound, "No se encontró el vehículo")
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeMessage(w, r, http.StatusOK, "Precio del vehículo actualizado exitosamente")
+	}
+}
+
+func (h *VehicleDefault) FindVehiclesByMileage() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		min := r.URL.Query().Get("min")
+		max := r.URL.Query().Get("max")
+
+		if min == "" || max == "" {
+			writeError(w, r, http.StatusBadRequest, "Parámetros 'min' y 'max' son requeridos")
+			return
+		}
+
+		minMileage, err := strconv.Atoi(min)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Kilometraje mínimo inválido")
+			return
+		}
+
+		maxMileage, err := strconv.Atoi(max)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "Kilometraje máximo inválido")
+			return
+		}
+
+		vehicles, err := h.sv.FindVehiclesByMileage(r.Context(), minMileage, maxMileage)
+		if err != nil {
+			if err.Error() == "No se encontraron vehículos en ese rango de kilometraje" {
+				writeError(w, r, http.StatusNotFound, err.Error())
+			} else if err.Error() == "Rango de kilometraje mal formado" {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+			} else {
+				writeError(w, r, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		vehicles, err = filterByBranch(r, vehicles)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writeVehicles(w, r, http.StatusOK, vehicles)
+	}
+}
+
+func mapVehicleToDoc(vehicle models.Vehicle) models.VehicleDoc {
+	return models.VehicleDoc{
+		ID:              vehicle.Id,
+		Brand:           vehicle.Brand,
+		Model:           vehicle.Model,
+		Registration:    vehicle.Registration,
+		Color:           vehicle.Color,
+		FabricationYear: vehicle.FabricationYear,
+		Capacity:        vehicle.Capacity,
+		MaxSpeed:        vehicle.MaxSpeed,
+		FuelType:        vehicle.FuelType,
+		Transmission:    vehicle.Transmission,
+		Weight:          vehicle.Weight,
+		Height:          vehicle.Height,
+		Length:          vehicle.Length,
+		Width:           vehicle.Width,
+		Price:           vehicle.Price,
+		Mileage:         vehicle.Mileage,
+		BranchId:        vehicle.BranchId,
+		Status:          vehicle.Status,
+	}
+}
+
+// filterByBranch keeps the vehicles of the branch in the query parameter branch_id, if it is present
+func filterByBranch(r *http.Request, vehicles map[int]models.Vehicle) (map[int]models.Vehicle, error) {
+	branch := r.URL.Query().Get("branch_id")
+	if branch == "" {
+		return vehicles, nil
+	}
+
+	branchId, err := strconv.Atoi(branch)
+	if err != nil {
+		return nil, errors.New("Identificador de sucursal mal formado")
+	}
+
+	filtered := make(map[int]models.Vehicle)
+	for key, value := range vehicles {
+		if value.BranchId == branchId {
+			filtered[key] = value
+		}
 	}
+	return filtered, nil
 }
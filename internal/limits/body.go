@@ -0,0 +1,83 @@
+package limits
+
+import (
+	"app/internal/envelope"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// BodySize is a struct that represents the maximum body size of the requests by route
+type BodySize struct {
+	// Default is the maximum size in bytes of the routes not listed, 0 is unlimited
+	Default int64
+	// Routes are the maximum sizes by "METHOD pattern" of the router, e.g. "POST /vehicles/batch".
+	// A size of 0 leaves the limit to the handler, e.g. for uploads streamed to the storage.
+	Routes map[string]int64
+}
+
+// Middleware is a method that returns a middleware for the router rt that reads the body of
+// each request up to the size of its route before the handler decodes it, and rejects the
+// larger ones with 413. It must be installed with Use on rt to resolve the route in advance.
+func (s BodySize) Middleware(rt chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			limit := s.limit(rt, r)
+			if limit <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.ContentLength > limit {
+				writeTooLarge(w, r, limit)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					writeTooLarge(w, r, limit)
+					return
+				}
+				envelope.WriteError(w, r, http.StatusBadRequest, "No se pudo leer el cuerpo de la petición")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limit returns the maximum body size of the route the request matches in rt
+func (s BodySize) limit(rt chi.Routes, r *http.Request) int64 {
+	// the path left to rt when it is mounted in another router
+	path := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+		path = rctx.RoutePath
+	}
+
+	tctx := chi.NewRouteContext()
+	if !rt.Match(tctx, r.Method, path) {
+		return s.Default
+	}
+	if size, ok := s.Routes[r.Method+" "+tctx.RoutePattern()]; ok {
+		return size
+	}
+	return s.Default
+}
+
+// writeTooLarge writes the response of a body over the limit
+func writeTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	// the rest of the body is not read, the connection can not be reused
+	w.Header().Set("Connection", "close")
+	envelope.WriteError(w, r, http.StatusRequestEntityTooLarge, "El cuerpo de la petición supera el máximo de "+strconv.FormatInt(limit, 10)+" bytes")
+}
@@ -0,0 +1,254 @@
+package limits
+
+import (
+	"app/internal/auth"
+	"app/internal/envelope"
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// ClassRead are the GET, HEAD and OPTIONS requests
+	ClassRead = "read"
+	// ClassWrite are the requests of the other methods
+	ClassWrite = "write"
+)
+
+// Policy is the budget of a client, reads and writes are counted apart
+type Policy struct {
+	// Read is the rate of the read requests
+	Read Rate
+	// Write is the rate of the write requests
+	Write Rate
+}
+
+// Config is a struct that represents the configuration of the rate limits
+type Config struct {
+	// Key is the budget of each authenticated client, by API key or user of the token
+	Key Policy
+	// IP is the budget of each address without credentials
+	IP Policy
+}
+
+// evictInterval is how often the idle buckets are removed
+const evictInterval = time.Minute
+
+// NewLimiter is a function that returns a new instance of Limiter
+func NewLimiter(cfg Config) *Limiter {
+	return &Limiter{cfg: cfg, buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Limiter is a struct that limits the requests of each client with token buckets
+type Limiter struct {
+	// cfg is the configuration of the limits
+	cfg Config
+	// mu protects buckets
+	mu sync.Mutex
+	// buckets are the token buckets by client and class
+	buckets map[string]*bucket
+	// now returns the current time
+	now func() time.Time
+}
+
+// bucket is a token bucket, it holds up to the limit of its rate and refills continuously
+type bucket struct {
+	// tokens are the requests left
+	tokens float64
+	// last is when tokens was computed
+	last time.Time
+	// rate is the rate the bucket refills at
+	rate Rate
+}
+
+// refill updates the tokens of the bucket to now
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(float64(b.rate.Limit), b.tokens+elapsed.Seconds()*b.perSecond())
+	b.last = now
+}
+
+// perSecond returns the tokens the bucket gains each second
+func (b *bucket) perSecond() float64 {
+	return float64(b.rate.Limit) / b.rate.Period.Seconds()
+}
+
+// until returns the time the bucket needs to hold the tokens
+func (b *bucket) until(tokens float64) time.Duration {
+	missing := tokens - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.perSecond() * float64(time.Second))
+}
+
+// Middleware is a method that rejects with 429 the requests of a client over its budget.
+// It must run after auth.Authenticator.Middleware to tell the clients apart by their credentials,
+// the requests without credentials are counted by address.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := classOf(r)
+		client, policy := l.client(r)
+		rate := policy.Write
+		if class == ClassRead {
+			rate = policy.Read
+		}
+		if rate.Unlimited() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, remaining, reset, retry := l.take(client+" "+class, rate)
+		w.Header().Set("RateLimit-Policy", strconv.Itoa(rate.Limit)+";w="+seconds(rate.Period))
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(rate.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", seconds(reset))
+		if !allowed {
+			slog.WarnContext(r.Context(), "rate limited", "client", client, "class", class)
+			w.Header().Set("Retry-After", seconds(retry))
+			envelope.WriteError(w, r, http.StatusTooManyRequests, "Demasiadas peticiones, reintente más tarde")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Failures is a method that counts the requests rejected with 401 against the budget of their address.
+// It must run before auth.Authenticator.Middleware, so the credentials are not checked for an address
+// that used up its budget and guessing them is limited as the requests without credentials.
+func (l *Limiter) Failures(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := classOf(r)
+		client := "ip:" + remoteHost(r)
+		rate := l.cfg.IP.Write
+		if class == ClassRead {
+			rate = l.cfg.IP.Read
+		}
+		if rate.Unlimited() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if allowed, retry := l.peek(client+" "+class, rate); !allowed {
+			slog.WarnContext(r.Context(), "rate limited", "client", client, "class", class)
+			w.Header().Set("Retry-After", seconds(retry))
+			envelope.WriteError(w, r, http.StatusTooManyRequests, "Demasiadas peticiones, reintente más tarde")
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		if ww.Status() == http.StatusUnauthorized {
+			l.take(client+" "+class, rate)
+		}
+	})
+}
+
+// classOf returns the class of the request by its method
+func classOf(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ClassRead
+	}
+	return ClassWrite
+}
+
+// remoteHost returns the address of the client without the port
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// client returns the key of the client of the request and its budget
+func (l *Limiter) client(r *http.Request) (string, Policy) {
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok && p.Method != auth.MethodNone {
+		return p.Method + ":" + p.Subject, l.cfg.Key
+	}
+	return "ip:" + remoteHost(r), l.cfg.IP
+}
+
+// take consumes a token of the bucket of the key. It returns whether the request is allowed,
+// the tokens left, the time until the bucket is full and, if rejected, until the next token.
+func (l *Limiter) take(key string, rate Rate) (allowed bool, remaining int, reset time.Duration, retry time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok || b.rate != rate {
+		b = &bucket{tokens: float64(rate.Limit), last: now, rate: rate}
+		l.buckets[key] = b
+	}
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retry = b.until(1)
+	}
+	return allowed, int(b.tokens), b.until(float64(rate.Limit)), retry
+}
+
+// peek tells whether the bucket of the key holds a token without taking it, and if not, the time until it does
+func (l *Limiter) peek(key string, rate Rate) (allowed bool, retry time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok || b.rate != rate {
+		// a new bucket starts full
+		return true, 0
+	}
+	b.refill(l.now())
+	if b.tokens >= 1 {
+		return true, 0
+	}
+	return false, b.until(1)
+}
+
+// Run is a method that removes the buckets that refilled completely, until the context is done
+func (l *Limiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(evictInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.evict()
+		}
+	}
+}
+
+// evict removes the buckets that are full, a new bucket starts full as well
+func (l *Limiter) evict() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.rate.Limit) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// seconds formats a duration as whole seconds rounded up, as the headers expect
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
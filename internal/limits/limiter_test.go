@@ -0,0 +1,161 @@
+package limits
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// clock is a time that only moves when told
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time { return c.t }
+
+// step is a request sent after the clock moves by wait
+type step struct {
+	wait   time.Duration
+	method string
+	// status is the code expected
+	status int
+	// remaining and retry are the RateLimit-Remaining and Retry-After expected, empty is not checked
+	remaining string
+	retry     string
+}
+
+// TestMiddleware checks the buckets of a client are spent, refill over time and tell when to retry
+func TestMiddleware(t *testing.T) {
+	// 2 each minute is a token every 30 seconds
+	rate := Rate{Limit: 2, Period: time.Minute}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "the burst is the limit",
+			steps: []step{
+				{method: http.MethodGet, status: http.StatusOK, remaining: "1"},
+				{method: http.MethodGet, status: http.StatusOK, remaining: "0"},
+				{method: http.MethodGet, status: http.StatusTooManyRequests, remaining: "0", retry: "30"},
+			},
+		},
+		{
+			name: "a token refills after its share of the period",
+			steps: []step{
+				{method: http.MethodGet, status: http.StatusOK},
+				{method: http.MethodGet, status: http.StatusOK},
+				{wait: 10 * time.Second, method: http.MethodGet, status: http.StatusTooManyRequests, retry: "20"},
+				{wait: 20 * time.Second, method: http.MethodGet, status: http.StatusOK, remaining: "0"},
+				{method: http.MethodGet, status: http.StatusTooManyRequests, retry: "30"},
+			},
+		},
+		{
+			name: "the bucket doesn't refill over the limit",
+			steps: []step{
+				{method: http.MethodGet, status: http.StatusOK, remaining: "1"},
+				{wait: time.Hour, method: http.MethodGet, status: http.StatusOK, remaining: "1"},
+				{method: http.MethodGet, status: http.StatusOK, remaining: "0"},
+				{method: http.MethodGet, status: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "reads and writes are counted apart",
+			steps: []step{
+				{method: http.MethodGet, status: http.StatusOK},
+				{method: http.MethodGet, status: http.StatusOK},
+				{method: http.MethodGet, status: http.StatusTooManyRequests},
+				{method: http.MethodPost, status: http.StatusOK, remaining: "1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &clock{t: time.Unix(0, 0)}
+			l := NewLimiter(Config{IP: Policy{Read: rate, Write: rate}})
+			l.now = c.now
+			h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			for i, s := range tt.steps {
+				c.t = c.t.Add(s.wait)
+				rr := httptest.NewRecorder()
+				h.ServeHTTP(rr, httptest.NewRequest(s.method, "/vehicles", nil))
+
+				if rr.Code != s.status {
+					t.Errorf("step %d: status: got %d, want %d", i, rr.Code, s.status)
+				}
+				if got := rr.Header().Get("RateLimit-Limit"); got != "2" {
+					t.Errorf("step %d: RateLimit-Limit: got %q, want %q", i, got, "2")
+				}
+				if got := rr.Header().Get("RateLimit-Remaining"); s.remaining != "" && got != s.remaining {
+					t.Errorf("step %d: RateLimit-Remaining: got %q, want %q", i, got, s.remaining)
+				}
+				if got := rr.Header().Get("Retry-After"); s.retry != "" && got != s.retry {
+					t.Errorf("step %d: Retry-After: got %q, want %q", i, got, s.retry)
+				}
+			}
+		})
+	}
+}
+
+// TestFailures checks only the requests rejected with 401 are counted and the address is blocked before auth
+func TestFailures(t *testing.T) {
+	rate := Rate{Limit: 2, Period: time.Minute}
+
+	tests := []struct {
+		name string
+		// statuses are the codes returned by the handler behind, one per request
+		statuses []int
+		// want are the codes the client gets
+		want []int
+		// reached is the number of requests that got to the handler
+		reached int
+	}{
+		{
+			name:     "accepted credentials are not counted",
+			statuses: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+			want:     []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+			reached:  4,
+		},
+		{
+			name:     "rejected credentials use up the budget",
+			statuses: []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusOK},
+			want:     []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests},
+			reached:  2,
+		},
+		{
+			name:     "other errors are not counted",
+			statuses: []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnauthorized, http.StatusOK},
+			want:     []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnauthorized, http.StatusOK},
+			reached:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &clock{t: time.Unix(0, 0)}
+			l := NewLimiter(Config{IP: Policy{Read: rate, Write: rate}})
+			l.now = c.now
+			reached := 0
+			h := l.Failures(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[reached])
+				reached++
+			}))
+
+			for i, want := range tt.want {
+				rr := httptest.NewRecorder()
+				h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/vehicles", nil))
+				if rr.Code != want {
+					t.Errorf("request %d: status: got %d, want %d", i, rr.Code, want)
+				}
+				if rr.Code == http.StatusTooManyRequests && rr.Header().Get("Retry-After") != "30" {
+					t.Errorf("request %d: Retry-After: got %q, want %q", i, rr.Header().Get("Retry-After"), "30")
+				}
+			}
+			if reached != tt.reached {
+				t.Errorf("requests reaching auth: got %d, want %d", reached, tt.reached)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package limits
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is the number of requests allowed per period, the zero value means unlimited
+type Rate struct {
+	// Limit is the number of requests, also the largest burst
+	Limit int
+	// Period is the time it takes to refill the whole limit
+	Period time.Duration
+}
+
+// ParseRate is a function that parses a rate written as "<limit>/<period>", e.g. 100/m, 10/s or 500/15m.
+// An empty string or a zero limit is unlimited.
+func ParseRate(value string) (Rate, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Rate{}, nil
+	}
+
+	limit, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("rate %q: must be <limit>/<period>, e.g. 100/m", value)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n < 0 {
+		return Rate{}, fmt.Errorf("rate %q: limit must be a non negative integer", value)
+	}
+	period = strings.TrimSpace(period)
+	// a bare unit is a single period
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return Rate{}, fmt.Errorf("rate %q: %w", value, err)
+	}
+	if d <= 0 {
+		return Rate{}, fmt.Errorf("rate %q: period must be positive", value)
+	}
+	if n == 0 {
+		return Rate{}, nil
+	}
+	return Rate{Limit: n, Period: d}, nil
+}
+
+// Unlimited is a method that reports whether the rate lets every request through
+func (r Rate) Unlimited() bool {
+	return r.Limit <= 0 || r.Period <= 0
+}
+
+// String is a method that returns the rate in the format read by ParseRate
+func (r Rate) String() string {
+	if r.Unlimited() {
+		return ""
+	}
+	return strconv.Itoa(r.Limit) + "/" + r.Period.String()
+}
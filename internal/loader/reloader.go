@@ -0,0 +1,232 @@
+package loader
+
+import (
+	"app/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidRecords is returned in strict mode when some records were skipped or duplicated
+var ErrInvalidRecords = errors.New("records skipped or duplicated")
+
+// ErrNotReady is returned by Reload before the vehicles are loaded for the first time
+var ErrNotReady = errors.New("the vehicles are still loading")
+
+// Replacer is an interface that represents the repository the vehicles reloaded are swapped into
+type Replacer interface {
+	// Replace is a method that swaps all the vehicles at once, with merge the ones changed since the
+	// last load keep their state, kept is how many of them, and dropped are the ones no longer there
+	Replace(v map[int]models.Vehicle, merge bool) (kept int, dropped []int, err error)
+}
+
+// NewReloader is a function that returns a new instance of Reloader. With strict the
+// vehicles are not loaded when a record is skipped or duplicated.
+func NewReloader(path string, opts Options, strict bool) *Reloader {
+	return &Reloader{path: path, opts: opts, strict: strict}
+}
+
+// Reloader is a struct that loads the vehicles of a file or directory and loads them again when they
+// change. The vehicles are read and validated apart, and swapped into the repository only if they are
+// valid, so the requests keep being served with the previous ones meanwhile.
+type Reloader struct {
+	// path is the file or directory of the vehicles
+	path string
+	// opts are how the files are read
+	opts Options
+	// strict rejects the files with records skipped or duplicated
+	strict bool
+	// mu serializes the loads and protects seen and rp
+	mu sync.Mutex
+	// seen is the fingerprint of the files last read, valid or not
+	seen string
+	// rp is where the vehicles reloaded are swapped, nil until the first load is in a repository
+	rp Replacer
+	// deleteHooks are called for each vehicle dropped by a reload to clean up what belongs to it
+	deleteHooks []func(ctx context.Context, id int) error
+}
+
+// Load is a method that loads the vehicles for the first time
+func (r *Reloader) Load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.load()
+}
+
+// Use is a method that sets the repository with the vehicles loaded, the reloads are swapped into it
+func (r *Reloader) Use(rp Replacer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rp = rp
+}
+
+// UseCheck is a method that sets how the vehicles of the files are checked besides their mandatory fields,
+// e.g. as the service checks a new vehicle, the ones rejected are skipped
+func (r *Reloader) UseCheck(check func(v models.Vehicle) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opts.Check = check
+}
+
+// OnDelete is a method that registers a function called for each vehicle a reload drops
+func (r *Reloader) OnDelete(hook func(ctx context.Context, id int) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleteHooks = append(r.deleteHooks, hook)
+}
+
+// Reload is a method that loads the vehicles again and swaps them into the repository, the current
+// ones are kept if the files can't be read or, in strict mode, have records skipped or duplicated
+func (r *Reloader) Reload(ctx context.Context, merge bool) (report models.ReloadReport, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report.Merge = merge
+	if r.rp == nil {
+		return report, ErrNotReady
+	}
+	v, loaded, err := r.load()
+	report.LoadReport = loaded
+	if err != nil {
+		return
+	}
+	kept, dropped, err := r.rp.Replace(v, merge)
+	if err != nil {
+		return
+	}
+	report.Kept, report.Dropped = kept, len(dropped)
+	// the vehicles are already swapped, a clean up that fails is logged and the rest go on
+	for _, id := range dropped {
+		for _, hook := range r.deleteHooks {
+			if errHook := hook(ctx, id); errHook != nil {
+				slog.WarnContext(ctx, "clean up of a vehicle dropped by the reload failed", "id", id, "error", errHook)
+			}
+		}
+	}
+	slog.InfoContext(ctx, "vehicles reloaded", "path", r.path, "count", len(v), "merge", merge, "kept", report.Kept, "dropped", report.Dropped)
+	return
+}
+
+// load reads and validates the vehicles of the files
+func (r *Reloader) load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	// the files are fingerprinted before they are read, a change while reading them is seen by the next check
+	seen, err := fingerprint(r.path)
+	if err != nil {
+		return
+	}
+	r.seen = seen
+
+	ld, err := New(r.path, r.opts)
+	if err != nil {
+		return
+	}
+	if v, report, err = ld.Load(); err != nil {
+		return
+	}
+	logReport(r.path, report)
+	if r.strict && report.Errors() > 0 {
+		return nil, report, fmt.Errorf("%w: %d records of %s", ErrInvalidRecords, report.Errors(), r.path)
+	}
+	return
+}
+
+// Watch is a method that reloads the vehicles when the files change, checking every interval, until
+// the context is done. A change is reloaded once the files stay the same for an interval, so a file
+// still being copied is not read half written.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, merge bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// pending is the fingerprint of a change waiting to settle
+	var pending string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := fingerprint(r.path)
+		if err != nil {
+			// a file being replaced may be missing for a moment, the next check will see it
+			continue
+		}
+		r.mu.Lock()
+		seen := r.seen
+		r.mu.Unlock()
+		if current == seen {
+			pending = ""
+			continue
+		}
+		if current != pending {
+			pending = current
+			continue
+		}
+		pending = ""
+
+		slog.Info("vehicle files changed", "path", r.path)
+		if _, err := r.Reload(ctx, merge); err != nil {
+			slog.Error("vehicles reload failed, the current vehicles are kept", "path", r.path, "error", err)
+		}
+	}
+}
+
+// fingerprint returns the size and modification time of the file, or of each file of the directory
+func fingerprint(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return stamp(info), nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		// the same files the directory loader reads
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(entry.Name() + ":" + stamp(info) + "\n")
+	}
+	return b.String(), nil
+}
+
+// stamp returns the size and modification time of a file
+func stamp(info os.FileInfo) string {
+	return strconv.FormatInt(info.Size(), 10) + "@" + strconv.FormatInt(info.ModTime().UnixNano(), 10)
+}
+
+// logReport logs what the loader did with the records of path: each one skipped or duplicated
+// as a warning, the repaired ones only in debug since the seed files rely on the defaults
+func logReport(path string, report models.LoadReport) {
+	for _, issue := range report.Skipped {
+		slog.Warn("vehicle record skipped", "source", issue.Source, "position", issue.Position, "id", issue.Id, "reasons", issue.Reasons)
+	}
+	for _, issue := range report.Duplicates {
+		slog.Warn("vehicle record duplicated", "source", issue.Source, "position", issue.Position, "id", issue.Id, "reasons", issue.Reasons)
+	}
+	for _, source := range report.Ignored {
+		slog.Warn("file of vehicles ignored, its extension is of no known format", "source", source)
+	}
+	for _, issue := range report.Repaired {
+		slog.Debug("vehicle record repaired", "source", issue.Source, "position", issue.Position, "id", issue.Id, "reasons", issue.Reasons)
+	}
+	slog.Info("vehicles loaded", "path", path, "count", report.Loaded,
+		"skipped", len(report.Skipped), "repaired", len(report.Repaired), "duplicates", len(report.Duplicates), "ignored", len(report.Ignored))
+}
@@ -0,0 +1,54 @@
+package loader
+
+import (
+	"app/internal/codec"
+	"app/pkg/models"
+	"fmt"
+	"io"
+)
+
+// NewVehicleCSVFile is a function that returns a new instance of VehicleCSVFile
+func NewVehicleCSVFile(path string, opts codec.CSVOptions) *VehicleCSVFile {
+	return &VehicleCSVFile{path: path, opts: opts}
+}
+
+// VehicleCSVFile is a struct that implements the VehicleLoader interface for a CSV file with the
+// header the export writes, read a row at a time
+type VehicleCSVFile struct {
+	// path is the path to the file that contains the vehicles
+	path string
+	// opts are the delimiter, decimal separator and names of the columns of the file
+	opts codec.CSVOptions
+}
+
+// Load is a method that loads the vehicles
+func (l *VehicleCSVFile) Load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	c := newCollector(nil)
+	if err = loadFile(l.path, l, c); err != nil {
+		return
+	}
+	v, report = c.result()
+	return
+}
+
+// decode reads the vehicles a row at a time, the rows with values that can't be read are skipped
+func (l *VehicleCSVFile) decode(r io.Reader, source string, c *collector) (err error) {
+	cr, err := codec.NewVehicleCSVReader(r, l.opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if len(row.Errors) > 0 {
+			c.skip(source, row.Line, row.Vehicle.ID, row.Errors...)
+			continue
+		}
+		c.add(source, row.Line, row.Vehicle)
+	}
+}
@@ -0,0 +1,53 @@
+package loader
+
+import (
+	"app/pkg/models"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewVehicleDir is a function that returns a new instance of VehicleDir
+func NewVehicleDir(path string, opts Options) *VehicleDir {
+	return &VehicleDir{path: path, opts: opts}
+}
+
+// VehicleDir is a struct that implements the VehicleLoader interface for a directory, the vehicles
+// of its files are merged and the same id in two files is reported as a duplicate
+type VehicleDir struct {
+	// path is the path to the directory
+	path string
+	// opts are the options of the files
+	opts Options
+}
+
+// Load is a method that loads the vehicles of the files of the directory in order of name.
+// The subdirectories and the hidden files are skipped, and the files with an extension of no known
+// format, e.g. a README, are reported as ignored unless opts give the format of every file.
+func (l *VehicleDir) Load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	entries, err := os.ReadDir(l.path)
+	if err != nil {
+		return
+	}
+
+	c := newCollector(l.opts.Check)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(l.path, entry.Name())
+		if f, _ := FormatOf(path); f == "" && l.opts.Format == "" {
+			c.ignore(entry.Name())
+			continue
+		}
+		var ld fileLoader
+		if ld, err = newFile(path, l.opts); err != nil {
+			return
+		}
+		if err = loadFile(path, ld, c); err != nil {
+			return
+		}
+	}
+	v, report = c.result()
+	return
+}
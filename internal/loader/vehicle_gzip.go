@@ -0,0 +1,43 @@
+package loader
+
+import (
+	"app/pkg/models"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// NewVehicleGzipFile is a function that returns a new instance of VehicleGzipFile
+func NewVehicleGzipFile(path string, dec decoder) *VehicleGzipFile {
+	return &VehicleGzipFile{path: path, dec: dec}
+}
+
+// VehicleGzipFile is a struct that implements the VehicleLoader interface for a file compressed
+// with gzip, decompressed as it is read by the loader of its format
+type VehicleGzipFile struct {
+	// path is the path to the compressed file
+	path string
+	// dec reads the decompressed content
+	dec decoder
+}
+
+// Load is a method that loads the vehicles
+func (l *VehicleGzipFile) Load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	c := newCollector(nil)
+	if err = loadFile(l.path, l, c); err != nil {
+		return
+	}
+	v, report = c.result()
+	return
+}
+
+// decode decompresses r for the loader of the format
+func (l *VehicleGzipFile) decode(r io.Reader, source string, c *collector) (err error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	defer zr.Close()
+
+	return l.dec.decode(zr, source, c)
+}
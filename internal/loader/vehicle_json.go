@@ -3,7 +3,11 @@ package loader
 import (
 	"app/pkg/models"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
 // NewVehicleJSONFile is a function that returns a new instance of VehicleJSONFile
@@ -20,45 +24,102 @@ type VehicleJSONFile struct {
 }
 
 // Load is a method that loads the vehicles
-func (l *VehicleJSONFile) Load() (v map[int]models.Vehicle, err error) {
-	// open file
-	file, err := os.Open(l.path)
-	if err != nil {
+func (l *VehicleJSONFile) Load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	c := newCollector(nil)
+	if err = loadFile(l.path, l, c); err != nil {
 		return
 	}
-	defer file.Close()
+	v, report = c.result()
+	return
+}
 
+// decode reads the array at once, the elements that are not vehicles are skipped
+func (l *VehicleJSONFile) decode(r io.Reader, source string, c *collector) (err error) {
 	// decode file
-	var vehiclesJSON []models.VehicleDoc
-	err = json.NewDecoder(file).Decode(&vehiclesJSON)
+	var records []json.RawMessage
+	err = json.NewDecoder(r).Decode(&records)
 	if err != nil {
-		return
+		return fmt.Errorf("%s: %w", source, err)
 	}
 
 	// serialize vehicles
-	v = make(map[int]models.Vehicle)
-	for _, vh := range vehiclesJSON {
-		v[vh.ID] = models.Vehicle{
-			Id: vh.ID,
-			VehicleAttributes: models.VehicleAttributes{
-				Brand:           vh.Brand,
-				Model:           vh.Model,
-				Registration:    vh.Registration,
-				Color:           vh.Color,
-				FabricationYear: vh.FabricationYear,
-				Capacity:        vh.Capacity,
-				MaxSpeed:        vh.MaxSpeed,
-				FuelType:        vh.FuelType,
-				Transmission:    vh.Transmission,
-				Weight:          vh.Weight,
-				Dimensions: models.Dimensions{
-					Height: vh.Height,
-					Length: vh.Length,
-					Width:  vh.Width,
-				},
-			},
+	for i, record := range records {
+		var vh models.VehicleDoc
+		if err := json.Unmarshal(record, &vh); err != nil {
+			c.skip(source, i+1, vh.ID, err.Error())
+			continue
 		}
+		c.add(source, i+1, vh)
+	}
+	return
+}
+
+// Save is a method that writes the vehicles to the file in the same format Load reads.
+// The content is written to a temporary file first so readers never see a partial file.
+func (l *VehicleJSONFile) Save(v map[int]models.Vehicle) (err error) {
+	// encode file
+	file, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+
+	err = WriteJSON(file, v)
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return
+	}
+
+	// replace file
+	err = os.Rename(file.Name(), l.path)
+	return
+}
+
+// WriteJSON is a function that writes the vehicles ordered by id in the format VehicleJSONFile reads
+func WriteJSON(w io.Writer, v map[int]models.Vehicle) (err error) {
+	// deserialize vehicles ordered by id
+	vehiclesJSON := make([]models.VehicleDoc, 0, len(v))
+	for _, vh := range v {
+		vehiclesJSON = append(vehiclesJSON, models.VehicleDoc{
+			ID:              vh.Id,
+			Brand:           vh.Brand,
+			Model:           vh.Model,
+			Registration:    vh.Registration,
+			Color:           vh.Color,
+			FabricationYear: vh.FabricationYear,
+			Capacity:        vh.Capacity,
+			MaxSpeed:        vh.MaxSpeed,
+			FuelType:        vh.FuelType,
+			Transmission:    vh.Transmission,
+			Weight:          vh.Weight,
+			Height:          vh.Height,
+			Length:          vh.Length,
+			Width:           vh.Width,
+			Price:           vh.Price,
+			Mileage:         vh.Mileage,
+			BranchId:        vh.BranchId,
+			Status:          vh.Status,
+		})
 	}
+	sort.Slice(vehiclesJSON, func(i, j int) bool {
+		return vehiclesJSON[i].ID < vehiclesJSON[j].ID
+	})
 
+	// encode vehicles
+	return json.NewEncoder(w).Encode(vehiclesJSON)
+}
+
+// Ping is a method that checks the directory of the file is writable, which is what Save needs
+func (l *VehicleJSONFile) Ping() (err error) {
+	file, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*.ping")
+	if err != nil {
+		return
+	}
+	err = file.Close()
+	if errRemove := os.Remove(file.Name()); err == nil {
+		err = errRemove
+	}
 	return
 }
@@ -1,9 +1,300 @@
 package loader
 
-import "app/pkg/models"
+import (
+	"app/internal/codec"
+	"app/pkg/models"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
 
 // VehicleLoader is an interface that represents the loader for vehicles
 type VehicleLoader interface {
-	// Load is a method that loads the vehicles
-	Load() (v map[int]models.Vehicle, err error)
+	// Load is a method that loads the vehicles. The records that can't be read or are not valid are
+	// skipped and reported, err is only returned when a source can't be read at all.
+	Load() (v map[int]models.Vehicle, report models.LoadReport, err error)
+}
+
+// Format is the encoding of a file of vehicles
+type Format string
+
+const (
+	// JSON is an array of models.VehicleDoc, extension .json
+	JSON Format = "json"
+	// NDJSON is a models.VehicleDoc by line, extensions .ndjson and .jsonl
+	NDJSON Format = "ndjson"
+	// CSV is a header with the JSON names of models.VehicleDoc and a row by vehicle, extension .csv
+	CSV Format = "csv"
+)
+
+// gzipExtension is the extension added to the files compressed with gzip, e.g. vehicles.csv.gz
+const gzipExtension = ".gz"
+
+// Options is a struct that represents how the files of vehicles are read
+type Options struct {
+	// Format is the format of every file, when empty it is the one of the extension of each file
+	Format Format
+	// CSV are the options of the CSV files
+	CSV codec.CSVOptions
+	// Check returns why a vehicle with its mandatory fields can't be loaded, as the service checks a new
+	// vehicle, the ones it rejects are skipped. Nil loads every vehicle with its mandatory fields.
+	Check func(v models.Vehicle) error
+}
+
+// ParseFormat is a function that returns the format with the name, empty selects it by extension
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case "", JSON, NDJSON, CSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q: must be %s, %s or %s", name, JSON, NDJSON, CSV)
+	}
+}
+
+// FormatOf is a function that returns the format of a file by its extension, and whether it is compressed with gzip
+func FormatOf(path string) (f Format, compressed bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == gzipExtension {
+		compressed = true
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path))))
+	}
+	switch ext {
+	case ".json":
+		f = JSON
+	case ".ndjson", ".jsonl":
+		f = NDJSON
+	case ".csv":
+		f = CSV
+	}
+	return
+}
+
+// New is a function that returns the loader of the path: the files of a directory merged together, or a
+// file in its format, decompressed when its extension ends in .gz
+func New(path string, opts Options) (VehicleLoader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return NewVehicleDir(path, opts), nil
+	}
+	dec, err := newFile(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &checkedFile{path: path, dec: dec, check: opts.Check}, nil
+}
+
+// checkedFile is the loader of a file whose vehicles are checked as the options tell
+type checkedFile struct {
+	// path is the path to the file
+	path string
+	// dec reads the file in its format
+	dec decoder
+	// check rejects the vehicles that can't be loaded, nil accepts them
+	check func(v models.Vehicle) error
+}
+
+// Load is a method that loads the vehicles
+func (l *checkedFile) Load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	c := newCollector(l.check)
+	if err = loadFile(l.path, l.dec, c); err != nil {
+		return
+	}
+	v, report = c.result()
+	return
+}
+
+// Decode is a function that loads the vehicles read from r in opts.Format, e.g. the standard input or
+// the body of a response, source names them in the report
+func Decode(r io.Reader, opts Options, source string) (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	var dec decoder
+	switch opts.Format {
+	case JSON:
+		dec = &VehicleJSONFile{}
+	case NDJSON:
+		dec = &VehicleNDJSONFile{}
+	case CSV:
+		dec = &VehicleCSVFile{opts: opts.CSV}
+	default:
+		return nil, report, fmt.Errorf("%s: unknown format %q, must be %s, %s or %s", source, opts.Format, JSON, NDJSON, CSV)
+	}
+
+	c := newCollector(opts.Check)
+	if err = dec.decode(r, source, c); err != nil {
+		return
+	}
+	v, report = c.result()
+	return
+}
+
+// newFile returns the loader of a file
+func newFile(path string, opts Options) (fileLoader, error) {
+	f, compressed := FormatOf(path)
+	if opts.Format != "" {
+		f = opts.Format
+	}
+
+	var dec fileLoader
+	switch f {
+	case JSON:
+		dec = NewVehicleJSONFile(path)
+	case NDJSON:
+		dec = NewVehicleNDJSONFile(path)
+	case CSV:
+		dec = NewVehicleCSVFile(path, opts.CSV)
+	default:
+		return nil, fmt.Errorf("%s: unknown format, the extension must be .json, .ndjson, .jsonl or .csv, optionally followed by .gz", path)
+	}
+	if compressed {
+		return NewVehicleGzipFile(path, dec), nil
+	}
+	return dec, nil
+}
+
+// decoder is a loader that reads the vehicles of a source from any reader, e.g. a decompressed file
+type decoder interface {
+	// decode adds to c the vehicles read from r, source names where they come from
+	decode(r io.Reader, source string, c *collector) (err error)
+}
+
+// fileLoader is a loader of a file that can read it from any reader
+type fileLoader interface {
+	VehicleLoader
+	decoder
+}
+
+// loadFile adds to c the vehicles of the file at path read by dec
+func loadFile(path string, dec decoder, c *collector) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	return dec.decode(file, filepath.Base(path), c)
+}
+
+// newCollector returns a new instance of collector, check rejects the vehicles that can't be loaded
+func newCollector(check func(v models.Vehicle) error) *collector {
+	return &collector{
+		check:   check,
+		v:       make(map[int]models.Vehicle),
+		origins: make(map[int]string),
+		report:  models.LoadReport{Skipped: []models.LoadIssue{}, Repaired: []models.LoadIssue{}, Duplicates: []models.LoadIssue{}, Ignored: []string{}},
+	}
+}
+
+// collector builds the vehicles read from one or more sources, validated as the service validates a new vehicle
+type collector struct {
+	// check rejects the vehicles that can't be loaded besides their mandatory fields, nil accepts them
+	check func(v models.Vehicle) error
+	// v are the vehicles by id
+	v map[int]models.Vehicle
+	// origins are where each vehicle was read, as source:position
+	origins map[int]string
+	// report is what was done with each record that was not loaded as it was read
+	report models.LoadReport
+}
+
+// result returns the vehicles and the report
+func (c *collector) result() (map[int]models.Vehicle, models.LoadReport) {
+	c.report.Loaded = len(c.v)
+	return c.v, c.report
+}
+
+// ignore reports a file that was not read
+func (c *collector) ignore(source string) {
+	c.report.Ignored = append(c.report.Ignored, source)
+}
+
+// skip reports a record of a source that could not be read
+func (c *collector) skip(source string, position int, id int, reasons ...string) {
+	c.report.Skipped = append(c.report.Skipped, models.LoadIssue{Source: source, Position: position, Id: id, Reasons: reasons})
+}
+
+// add adds the vehicle read at a position of a source. The vehicles without the mandatory fields or
+// rejected by the check are skipped, and the ones with an id already loaded, from this or another
+// source, are duplicates. The prices are kept in cents, as the service keeps them.
+func (c *collector) add(source string, position int, doc models.VehicleDoc) {
+	vehicle := models.Vehicle{
+		Id: doc.ID,
+		VehicleAttributes: models.VehicleAttributes{
+			Brand:           doc.Brand,
+			Model:           doc.Model,
+			Registration:    doc.Registration,
+			Color:           doc.Color,
+			FabricationYear: doc.FabricationYear,
+			Capacity:        doc.Capacity,
+			MaxSpeed:        doc.MaxSpeed,
+			FuelType:        doc.FuelType,
+			Transmission:    doc.Transmission,
+			Weight:          doc.Weight,
+			Price:           doc.Price.Round(2),
+			Mileage:         doc.Mileage,
+			BranchId:        doc.BranchId,
+			Status:          doc.Status,
+			Dimensions: models.Dimensions{
+				Height: doc.Height,
+				Length: doc.Length,
+				Width:  doc.Width,
+			},
+		},
+	}
+
+	// the length was added after the first files were written, without it the vehicle is loaded with
+	// the length unknown (0) instead of skipped
+	missing := slices.DeleteFunc(vehicle.MissingFields(), func(field string) bool { return field == "length" })
+	if len(missing) > 0 {
+		c.skip(source, position, doc.ID, "missing mandatory fields: "+strings.Join(missing, ", "))
+		return
+	}
+	if origin, ok := c.origins[doc.ID]; ok {
+		c.report.Duplicates = append(c.report.Duplicates, models.LoadIssue{
+			Source:   source,
+			Position: position,
+			Id:       doc.ID,
+			Reasons:  []string{"id already loaded from " + origin},
+		})
+		return
+	}
+
+	// vehicles without branch are in stock at the default one
+	var defaults []string
+	if vehicle.BranchId == 0 {
+		vehicle.BranchId = models.DefaultBranchId
+		defaults = append(defaults, "branch_id")
+	}
+	if vehicle.Status == "" {
+		vehicle.Status = models.VehicleAvailable
+		defaults = append(defaults, "status")
+	}
+	if c.check != nil {
+		if err := c.check(vehicle); err != nil {
+			c.skip(source, position, doc.ID, err.Error())
+			return
+		}
+	}
+	var reasons []string
+	if len(defaults) > 0 {
+		reasons = append(reasons, "defaults for "+strings.Join(defaults, ", "))
+	}
+	if vehicle.Length == 0 {
+		reasons = append(reasons, "length unknown, loaded as 0")
+	}
+	if len(reasons) > 0 {
+		c.report.Repaired = append(c.report.Repaired, models.LoadIssue{
+			Source:   source,
+			Position: position,
+			Id:       doc.ID,
+			Reasons:  reasons,
+		})
+	}
+	c.v[doc.ID] = vehicle
+	c.origins[doc.ID] = source + ":" + strconv.Itoa(position)
 }
@@ -0,0 +1,146 @@
+package loader
+
+import (
+	"app/pkg/models"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// vehicleLine returns a NDJSON line of a vehicle with every mandatory field, extra are more fields appended
+func vehicleLine(id int, extra string) string {
+	line := fmt.Sprintf(`{"id":%d,"brand":"Ford","model":"Fiesta","registration":"AB%03d","color":"red","year":2020,"passengers":5,"max_speed":180,"fuel_type":"gas","transmission":"manual","weight":1100,"height":1.5,"width":1.7`, id, id)
+	if extra != "" {
+		line += "," + extra
+	}
+	return line + "}"
+}
+
+// TestDecodeReport checks what the report tells of each record that was not loaded as it was read
+func TestDecodeReport(t *testing.T) {
+	// checkStock rejects the vehicles in transit, as the service does for a new vehicle
+	checkStock := func(v models.Vehicle) error {
+		if v.Status == models.VehicleInTransit {
+			return errors.New("Un vehículo nuevo no puede estar en tránsito")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		lines      []string
+		check      func(v models.Vehicle) error
+		loaded     int
+		skipped    []models.LoadIssue
+		repaired   []models.LoadIssue
+		duplicates []models.LoadIssue
+	}{
+		{
+			name:   "complete vehicle",
+			lines:  []string{vehicleLine(1, `"length":4,"branch_id":2,"status":"available"`)},
+			loaded: 1,
+		},
+		{
+			name:   "defaults for branch and status",
+			lines:  []string{vehicleLine(1, `"length":4`)},
+			loaded: 1,
+			repaired: []models.LoadIssue{
+				{Source: "test", Position: 1, Id: 1, Reasons: []string{"defaults for branch_id, status"}},
+			},
+		},
+		{
+			name:   "length unknown",
+			lines:  []string{vehicleLine(1, `"branch_id":2,"status":"available"`)},
+			loaded: 1,
+			repaired: []models.LoadIssue{
+				{Source: "test", Position: 1, Id: 1, Reasons: []string{"length unknown, loaded as 0"}},
+			},
+		},
+		{
+			name:  "missing mandatory fields",
+			lines: []string{`{"id":1,"brand":"Ford","model":"Fiesta","registration":"AB001","color":"red","year":2020,"passengers":5,"fuel_type":"gas","transmission":"manual","height":1.5,"width":1.7}`},
+			skipped: []models.LoadIssue{
+				{Source: "test", Position: 1, Id: 1, Reasons: []string{"missing mandatory fields: max_speed, weight"}},
+			},
+		},
+		{
+			name:   "duplicated id",
+			lines:  []string{vehicleLine(1, `"length":4,"branch_id":2,"status":"available"`), vehicleLine(1, `"length":4,"branch_id":2,"status":"available"`)},
+			loaded: 1,
+			duplicates: []models.LoadIssue{
+				{Source: "test", Position: 2, Id: 1, Reasons: []string{"id already loaded from test:1"}},
+			},
+		},
+		{
+			name:   "rejected by the check",
+			check:  checkStock,
+			lines:  []string{vehicleLine(1, `"length":4,"branch_id":2,"status":"in_transit"`), vehicleLine(2, `"length":4,"branch_id":2,"status":"available"`)},
+			loaded: 1,
+			skipped: []models.LoadIssue{
+				{Source: "test", Position: 1, Id: 1, Reasons: []string{"Un vehículo nuevo no puede estar en tránsito"}},
+			},
+		},
+		{
+			name:   "a rejected vehicle doesn't take its id",
+			check:  checkStock,
+			lines:  []string{vehicleLine(1, `"length":4,"branch_id":2,"status":"in_transit"`), vehicleLine(1, `"length":4,"branch_id":2,"status":"available"`)},
+			loaded: 1,
+			skipped: []models.LoadIssue{
+				{Source: "test", Position: 1, Id: 1, Reasons: []string{"Un vehículo nuevo no puede estar en tránsito"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, report, err := Decode(strings.NewReader(strings.Join(tt.lines, "\n")), Options{Format: NDJSON, Check: tt.check}, "test")
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if report.Loaded != tt.loaded {
+				t.Errorf("loaded: got %d, want %d", report.Loaded, tt.loaded)
+			}
+			issues := []struct {
+				kind      string
+				got, want []models.LoadIssue
+			}{
+				{"skipped", report.Skipped, tt.skipped},
+				{"repaired", report.Repaired, tt.repaired},
+				{"duplicates", report.Duplicates, tt.duplicates},
+			}
+			for _, i := range issues {
+				if len(i.got) == 0 && len(i.want) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(i.got, i.want) {
+					t.Errorf("%s: got %+v, want %+v", i.kind, i.got, i.want)
+				}
+			}
+		})
+	}
+}
+
+// TestDecodePrice checks the prices are loaded in cents
+func TestDecodePrice(t *testing.T) {
+	tests := []struct {
+		price string
+		want  string
+	}{
+		{"123.456", "123.46"},
+		{"123.454", "123.45"},
+		{"99", "99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.price, func(t *testing.T) {
+			v, _, err := Decode(strings.NewReader(vehicleLine(1, `"length":4,"price":`+tt.price)), Options{Format: NDJSON}, "test")
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got := v[1].Price.String(); got != tt.want {
+				t.Errorf("price: got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package loader
+
+import (
+	"app/pkg/models"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NewVehicleNDJSONFile is a function that returns a new instance of VehicleNDJSONFile
+func NewVehicleNDJSONFile(path string) *VehicleNDJSONFile {
+	return &VehicleNDJSONFile{path: path}
+}
+
+// VehicleNDJSONFile is a struct that implements the VehicleLoader interface for a file with a
+// vehicle in JSON by line, read a line at a time
+type VehicleNDJSONFile struct {
+	// path is the path to the file that contains the vehicles
+	path string
+}
+
+// Load is a method that loads the vehicles
+func (l *VehicleNDJSONFile) Load() (v map[int]models.Vehicle, report models.LoadReport, err error) {
+	c := newCollector(nil)
+	if err = loadFile(l.path, l, c); err != nil {
+		return
+	}
+	v, report = c.result()
+	return
+}
+
+// decode reads the vehicles a line at a time, the blank lines and the ones that are not vehicles are skipped
+func (l *VehicleNDJSONFile) decode(r io.Reader, source string, c *collector) (err error) {
+	sc := bufio.NewScanner(r)
+	// a line is a vehicle, the default limit of 64 KiB is far from it
+	line := 0
+	for sc.Scan() {
+		line++
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var vh models.VehicleDoc
+		if err := json.Unmarshal(text, &vh); err != nil {
+			c.skip(source, line, vh.ID, err.Error())
+			continue
+		}
+		c.add(source, line, vh)
+	}
+	if err = sc.Err(); err != nil {
+		return fmt.Errorf("%s:%d: %w", source, line+1, err)
+	}
+	return
+}
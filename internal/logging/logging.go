@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"app/internal/auth"
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// keys shared by every log line so they can be filtered the same way everywhere
+const (
+	// KeyRequestID is the identifier of the request that produced the line
+	KeyRequestID = "request_id"
+	// KeyTraceID is the identifier of the trace of the request, to find its spans
+	KeyTraceID = "trace_id"
+	// KeyVehicleID is the identifier of the vehicle involved
+	KeyVehicleID = "vehicle_id"
+	// KeyOperation is the name of the mutation applied
+	KeyOperation = "operation"
+	// KeyError is the error returned by the operation
+	KeyError = "error"
+	// KeyActor is who applied the mutation: the username or the name of the API key
+	KeyActor = "actor"
+	// KeyActorID is the identifier of the staff account that applied the mutation
+	KeyActorID = "actor_id"
+)
+
+// NewHandler is a function that returns a JSON slog handler that adds the request and trace IDs found
+// in the context of each record, so the logs of a request can be followed down to the repository
+func NewHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	return &contextHandler{Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})}
+}
+
+// contextHandler is a struct that implements the slog.Handler interface adding the request ID
+type contextHandler struct {
+	// Handler writes the records
+	slog.Handler
+}
+
+// Handle adds the request ID of the context to the record
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := middleware.GetReqID(ctx); id != "" {
+		r.AddAttrs(slog.String(KeyRequestID, id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		r.AddAttrs(slog.String(KeyTraceID, sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the request ID in the handlers derived with With
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the request ID in the handlers derived with WithGroup
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// Mutation is a function that logs the result of a mutation of a vehicle with the shared keys
+// and who applied it, so the log doubles as the audit trail of the inventory
+func Mutation(ctx context.Context, operation string, vehicleId int, err error) {
+	attrs := []any{KeyOperation, operation, KeyVehicleID, vehicleId}
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		attrs = append(attrs, KeyActor, p.Subject)
+		if p.UserId != 0 {
+			attrs = append(attrs, KeyActorID, p.UserId)
+		}
+	}
+
+	if err != nil {
+		slog.WarnContext(ctx, "vehicle mutation failed", append(attrs, KeyError, err.Error())...)
+		return
+	}
+	slog.InfoContext(ctx, "vehicle mutation", attrs...)
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// operations logged on the mutations of the vehicles
+const (
+	OperationCreate         = "create"
+	OperationUpdateMaxSpeed = "update_max_speed"
+	OperationUpdateFuel     = "update_fuel"
+	OperationUpdatePrice    = "update_price"
+	OperationUpdateMileage  = "update_mileage"
+	OperationUpdateBranch   = "update_branch"
+	OperationUpdateStatus   = "update_status"
+	OperationDelete         = "delete"
+)
+
+// LogVehicles is a function that returns a repository that logs every mutation of rp
+// with the operation and the vehicle ID, the queries are passed through
+func LogVehicles(rp repository.VehicleRepository) repository.VehicleRepository {
+	return &vehicleLogged{VehicleRepository: rp}
+}
+
+// vehicleLogged is a struct that implements the VehicleRepository interface logging the mutations
+type vehicleLogged struct {
+	// VehicleRepository keeps the vehicles
+	repository.VehicleRepository
+}
+
+func (r *vehicleLogged) AddVehicle(ctx context.Context, newVehicle models.Vehicle) (v models.Vehicle, err error) {
+	v, err = r.VehicleRepository.AddVehicle(ctx, newVehicle)
+	Mutation(ctx, OperationCreate, newVehicle.Id, err)
+	return
+}
+
+func (r *vehicleLogged) UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error) {
+	err = r.VehicleRepository.UpdateMaxSpeed(ctx, id, newSpeed)
+	Mutation(ctx, OperationUpdateMaxSpeed, id, err)
+	return
+}
+
+func (r *vehicleLogged) UpdateFuel(ctx context.Context, id int, newFuel string) (err error) {
+	err = r.VehicleRepository.UpdateFuel(ctx, id, newFuel)
+	Mutation(ctx, OperationUpdateFuel, id, err)
+	return
+}
+
+func (r *vehicleLogged) UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error) {
+	err = r.VehicleRepository.UpdatePrice(ctx, id, newPrice)
+	Mutation(ctx, OperationUpdatePrice, id, err)
+	return
+}
+
+func (r *vehicleLogged) UpdateMileage(ctx context.Context, id int, newMileage int) (err error) {
+	err = r.VehicleRepository.UpdateMileage(ctx, id, newMileage)
+	Mutation(ctx, OperationUpdateMileage, id, err)
+	return
+}
+
+func (r *vehicleLogged) UpdateBranch(ctx context.Context, id int, branchId int, status string) (err error) {
+	err = r.VehicleRepository.UpdateBranch(ctx, id, branchId, status)
+	Mutation(ctx, OperationUpdateBranch, id, err)
+	return
+}
+
+func (r *vehicleLogged) UpdateStatus(ctx context.Context, id int, status string) (err error) {
+	err = r.VehicleRepository.UpdateStatus(ctx, id, status)
+	Mutation(ctx, OperationUpdateStatus, id, err)
+	return
+}
+
+func (r *vehicleLogged) DeleteVehicle(ctx context.Context, id int) (err error) {
+	err = r.VehicleRepository.DeleteVehicle(ctx, id)
+	Mutation(ctx, OperationDelete, id, err)
+	return
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"app/internal/repository"
+	"context"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterInventory is a method that exposes the vehicles of the repository as gauges,
+// they are counted on every scrape so they never drift from the repository
+func (m *Metrics) RegisterInventory(rp repository.VehicleRepository) error {
+	return m.registry.Register(newInventoryCollector(rp))
+}
+
+// newInventoryCollector is a function that returns a new instance of inventoryCollector
+func newInventoryCollector(rp repository.VehicleRepository) *inventoryCollector {
+	gauge := func(name, help, label string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, []string{label}, nil)
+	}
+	return &inventoryCollector{
+		rp:             rp,
+		byBrand:        gauge("vehicles_by_brand", "Vehicles in the inventory by brand.", "brand"),
+		byFuelType:     gauge("vehicles_by_fuel_type", "Vehicles in the inventory by fuel type.", "fuel_type"),
+		byTransmission: gauge("vehicles_by_transmission", "Vehicles in the inventory by transmission.", "transmission"),
+		byStatus:       gauge("vehicles_by_status", "Vehicles in the inventory by status.", "status"),
+	}
+}
+
+// inventoryCollector is a struct that implements the prometheus.Collector interface
+// counting the vehicles of the repository
+type inventoryCollector struct {
+	// rp is the repository of the vehicles counted
+	rp repository.VehicleRepository
+	// byBrand, byFuelType, byTransmission and byStatus describe the gauges
+	byBrand        *prometheus.Desc
+	byFuelType     *prometheus.Desc
+	byTransmission *prometheus.Desc
+	byStatus       *prometheus.Desc
+}
+
+// Describe sends the descriptions of the gauges
+func (c *inventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.byBrand
+	ch <- c.byFuelType
+	ch <- c.byTransmission
+	ch <- c.byStatus
+}
+
+// Collect counts the vehicles and sends a gauge per value found
+func (c *inventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	vehicles, err := c.rp.FindAll(context.Background())
+	if err != nil {
+		slog.Error("collecting inventory metrics", "error", err)
+		return
+	}
+
+	brands := make(map[string]int)
+	fuelTypes := make(map[string]int)
+	transmissions := make(map[string]int)
+	statuses := make(map[string]int)
+	for _, v := range vehicles {
+		brands[v.Brand]++
+		fuelTypes[v.FuelType]++
+		transmissions[v.Transmission]++
+		statuses[v.Status]++
+	}
+
+	send := func(desc *prometheus.Desc, counts map[string]int) {
+		for value, count := range counts {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count), value)
+		}
+	}
+	send(c.byBrand, brands)
+	send(c.byFuelType, fuelTypes)
+	send(c.byTransmission, transmissions)
+	send(c.byStatus, statuses)
+}
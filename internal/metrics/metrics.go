@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace is the prefix of every metric of the server
+const namespace = "concesionaria"
+
+// routeUnmatched is the route label of the requests that matched no route,
+// the raw path would make a series per path scanned
+const routeUnmatched = "unmatched"
+
+// NewMetrics is a function that returns a new instance of Metrics with the
+// Go runtime and process collectors already registered
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests served by route pattern, method and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the requests by route pattern and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		mutations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "vehicle_mutations_total",
+			Help:      "Vehicles created, updated and deleted.",
+		}, []string{"operation"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.mutations,
+	)
+	return m
+}
+
+// Metrics is a struct that collects the metrics of the server and exposes them to Prometheus
+type Metrics struct {
+	// registry holds every collector of the server
+	registry *prometheus.Registry
+	// requests counts the requests by method, route and status
+	requests *prometheus.CounterVec
+	// duration observes the latency of the requests by method and route
+	duration *prometheus.HistogramVec
+	// mutations counts the vehicles created, updated and deleted
+	mutations *prometheus.CounterVec
+}
+
+// Handler is a method that returns a handler for the route GET /metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// Middleware is a method that observes every request by its chi route pattern,
+// so /vehicles/1 and /vehicles/2 are counted as /vehicles/{id}
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		// the pattern is complete only after the routers matched the request
+		route := routeUnmatched
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" && pattern != "/*" {
+				route = pattern
+			}
+		}
+		status := ww.Status()
+		if status == 0 {
+			// the handler wrote nothing, net/http answers 200
+			status = http.StatusOK
+		}
+
+		m.requests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		m.duration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// OperationCreate is the operation label of the vehicles added
+	OperationCreate = "create"
+	// OperationUpdate is the operation label of the vehicles modified
+	OperationUpdate = "update"
+	// OperationDelete is the operation label of the vehicles removed
+	OperationDelete = "delete"
+)
+
+// CountVehicles is a method that returns a repository that counts the successful
+// creates, updates and deletes of rp, the queries are passed through
+func (m *Metrics) CountVehicles(rp repository.VehicleRepository) repository.VehicleRepository {
+	return &vehicleCounted{VehicleRepository: rp, m: m}
+}
+
+// vehicleCounted is a struct that implements the VehicleRepository interface counting the mutations
+type vehicleCounted struct {
+	// VehicleRepository keeps the vehicles
+	repository.VehicleRepository
+	// m counts the mutations
+	m *Metrics
+}
+
+// count increments the counter of the operation if it succeeded
+func (r *vehicleCounted) count(operation string, err error) error {
+	if err == nil {
+		r.m.mutations.WithLabelValues(operation).Inc()
+	}
+	return err
+}
+
+func (r *vehicleCounted) AddVehicle(ctx context.Context, newVehicle models.Vehicle) (v models.Vehicle, err error) {
+	v, err = r.VehicleRepository.AddVehicle(ctx, newVehicle)
+	return v, r.count(OperationCreate, err)
+}
+
+func (r *vehicleCounted) UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error) {
+	return r.count(OperationUpdate, r.VehicleRepository.UpdateMaxSpeed(ctx, id, newSpeed))
+}
+
+func (r *vehicleCounted) UpdateFuel(ctx context.Context, id int, newFuel string) (err error) {
+	return r.count(OperationUpdate, r.VehicleRepository.UpdateFuel(ctx, id, newFuel))
+}
+
+func (r *vehicleCounted) UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error) {
+	return r.count(OperationUpdate, r.VehicleRepository.UpdatePrice(ctx, id, newPrice))
+}
+
+func (r *vehicleCounted) UpdateMileage(ctx context.Context, id int, newMileage int) (err error) {
+	return r.count(OperationUpdate, r.VehicleRepository.UpdateMileage(ctx, id, newMileage))
+}
+
+func (r *vehicleCounted) UpdateBranch(ctx context.Context, id int, branchId int, status string) (err error) {
+	return r.count(OperationUpdate, r.VehicleRepository.UpdateBranch(ctx, id, branchId, status))
+}
+
+func (r *vehicleCounted) UpdateStatus(ctx context.Context, id int, status string) (err error) {
+	return r.count(OperationUpdate, r.VehicleRepository.UpdateStatus(ctx, id, status))
+}
+
+func (r *vehicleCounted) DeleteVehicle(ctx context.Context, id int) (err error) {
+	return r.count(OperationDelete, r.VehicleRepository.DeleteVehicle(ctx, id))
+}
@@ -0,0 +1,177 @@
+package openapi
+
+import (
+	"app/internal/envelope"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// uiContentSecurityPolicy lets the documentation page load its own scripts and styles and call the API
+const uiContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; connect-src 'self'; img-src 'self' data:; frame-ancestors 'none'"
+
+// methods are the fields of a path item that describe an operation
+var methods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+var (
+	//go:embed openapi.yaml
+	source []byte
+	//go:embed ui
+	ui embed.FS
+)
+
+// New is a function that returns a new instance of Spec with the document embedded in the binary
+func New() (*Spec, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	addDeprecatedAliases(doc)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+
+	// the operations described, by method and path
+	var paths struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &paths); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	operations := make(map[string]bool)
+	for path, item := range paths.Paths {
+		for method := range item {
+			// the path item also holds the parameters and the summary shared by its operations
+			if !methods[method] {
+				continue
+			}
+			operations[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	return &Spec{data: data, operations: operations}, nil
+}
+
+// addDeprecatedAliases describes the routes outside the versioned API that alias the ones inside it
+func addDeprecatedAliases(doc map[string]any) {
+	paths, _ := doc["paths"].(map[string]any)
+	var versioned []string
+	for path := range paths {
+		if strings.HasPrefix(path, envelope.Prefix+"/") {
+			versioned = append(versioned, path)
+		}
+	}
+
+	for _, path := range versioned {
+		item, _ := paths[path].(map[string]any)
+		alias := make(map[string]any)
+		for key, value := range item {
+			op, ok := value.(map[string]any)
+			if !ok || key == "parameters" {
+				alias[key] = value
+				continue
+			}
+			// the responses are the ones the route had before the envelope, only the codes are kept
+			responses := make(map[string]any)
+			for code := range op["responses"].(map[string]any) {
+				responses[code] = map[string]any{"description": "Formato anterior a " + envelope.Prefix}
+			}
+			aliasOp := make(map[string]any)
+			for k, v := range op {
+				aliasOp[k] = v
+			}
+			aliasOp["deprecated"] = true
+			aliasOp["description"] = "Alias obsoleto de `" + path + "`, responde sin el sobre `{data, meta, error}`."
+			aliasOp["responses"] = responses
+			alias[key] = aliasOp
+		}
+		paths[strings.TrimPrefix(path, envelope.Prefix)] = alias
+	}
+}
+
+// Spec is a struct that represents the OpenAPI 3 document of the API
+type Spec struct {
+	// data is the document in JSON
+	data []byte
+	// operations are the operations described, as "METHOD /path"
+	operations map[string]bool
+}
+
+// Handler is a method that returns a handler for the route GET /openapi.json
+func (s *Spec) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(s.data)
+	}
+}
+
+// Undocumented is a method that returns the routes that the document does not describe, as "METHOD /path".
+// The routes of a mounted handler ("/*") are skipped, they are checked with the router of that handler.
+func (s *Spec) Undocumented(routes chi.Routes) (missing []string) {
+	for _, route := range walk(routes) {
+		if !s.operations[route] {
+			missing = append(missing, route)
+		}
+	}
+	sort.Strings(missing)
+	return
+}
+
+// Unrouted is a method that returns the operations of the document that none of the routers serves, as "METHOD /path"
+func (s *Spec) Unrouted(routers ...chi.Routes) (extra []string) {
+	served := make(map[string]bool)
+	for _, routes := range routers {
+		for _, route := range walk(routes) {
+			served[route] = true
+		}
+	}
+	for operation := range s.operations {
+		if !served[operation] {
+			extra = append(extra, operation)
+		}
+	}
+	sort.Strings(extra)
+	return
+}
+
+// walk is a function that returns the routes of a router as "METHOD /path", without the mounted handlers ("/*")
+func walk(routes chi.Routes) (walked []string) {
+	chi.Walk(routes, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.HasSuffix(route, "/*") {
+			return nil
+		}
+		// the index of a sub router is "/vehicles/", it is served as "/vehicles" too
+		if len(route) > 1 {
+			route = strings.TrimSuffix(route, "/")
+		}
+		walked = append(walked, method+" "+route)
+		return nil
+	})
+	return
+}
+
+// UI is a function that returns a handler for the routes GET /docs and GET /docs/{file}.
+// The page renders /openapi.json with no resources from outside the server.
+func UI() http.Handler {
+	files, err := fs.Sub(ui, "ui")
+	if err != nil {
+		// the directory is embedded, it is always there
+		panic(err)
+	}
+	fileServer := http.StripPrefix("/docs", http.FileServer(http.FS(files)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", uiContentSecurityPolicy)
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,235 @@
+"use strict";
+
+// el builds an element, the strings are always set as text
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    if (key === "class") {
+      node.className = value;
+    } else {
+      node.setAttribute(key, value);
+    }
+  }
+  for (const child of children) {
+    if (child === null || child === undefined) {
+      continue;
+    }
+    node.append(typeof child === "string" ? document.createTextNode(child) : child);
+  }
+  return node;
+}
+
+// resolve follows a local $ref of the document
+function resolve(doc, schema) {
+  let seen = 0;
+  while (schema && schema.$ref && seen++ < 16) {
+    schema = schema.$ref
+      .replace(/^#\//, "")
+      .split("/")
+      .reduce((node, key) => (node ? node[key] : undefined), doc);
+  }
+  return schema || {};
+}
+
+// example builds a sample value of a schema
+function example(doc, schema, depth) {
+  schema = resolve(doc, schema);
+  if (depth > 6) {
+    return null;
+  }
+  if (schema.example !== undefined) {
+    return schema.example;
+  }
+  if (schema.allOf) {
+    return Object.assign({}, ...schema.allOf.map((s) => example(doc, s, depth + 1)));
+  }
+  if (schema.enum) {
+    return schema.enum[0];
+  }
+  switch (schema.type) {
+    case "array":
+      return [example(doc, schema.items, depth + 1)];
+    case "integer":
+    case "number":
+      return 0;
+    case "boolean":
+      return false;
+    case "string":
+      return schema.format === "date-time" ? new Date(0).toISOString() : "";
+  }
+  if (schema.properties) {
+    const value = {};
+    for (const [name, property] of Object.entries(schema.properties)) {
+      value[name] = example(doc, property, depth + 1);
+    }
+    return value;
+  }
+  if (schema.additionalProperties) {
+    return { "1": example(doc, schema.additionalProperties, depth + 1) };
+  }
+  return {};
+}
+
+// sample renders the sample of the first content of a body
+function sample(doc, content) {
+  const [type, media] = Object.entries(content || {})[0] || [];
+  if (!type) {
+    return null;
+  }
+  if (!type.endsWith("json")) {
+    return el("p", null, type);
+  }
+  return el("pre", null, JSON.stringify(example(doc, media.schema, 0), null, 2));
+}
+
+// parameters renders the table of the parameters
+function parameters(doc, params) {
+  if (params.length === 0) {
+    return null;
+  }
+  const rows = params.map((p) => {
+    const schema = resolve(doc, p.schema);
+    const type = schema.enum ? schema.enum.join(" | ") : schema.type || "";
+    return el("tr", null,
+      el("td", null, el("code", null, p.name), p.required ? " *" : ""),
+      el("td", null, p.in),
+      el("td", null, type),
+      el("td", null, p.description || ""));
+  });
+  return el("table", null,
+    el("tr", null, el("th", null, "Parámetro"), el("th", null, "En"), el("th", null, "Tipo"), el("th", null, "Descripción")),
+    ...rows);
+}
+
+// responses renders the responses with a sample of each body
+function responses(doc, list) {
+  const items = Object.entries(list || {}).map(([code, response]) => {
+    response = resolve(doc, response);
+    return el("div", null, el("h4", null, code + " " + (response.description || "")), sample(doc, response.content));
+  });
+  return el("div", null, el("h3", null, "Respuestas"), ...items);
+}
+
+// tryIt renders a form that sends the request with the credentials of the header
+function tryIt(doc, method, path, params, body) {
+  const inputs = params.filter((p) => p.in === "path" || p.in === "query").map((p) =>
+    el("label", null, p.name + " ", el("input", { "data-name": p.name, "data-in": p.in })));
+  const text = body ? el("textarea", null, JSON.stringify(example(doc, body.schema, 0), null, 2)) : null;
+  const output = el("pre", { hidden: "" });
+  const form = el("form", { class: "try" }, el("h3", null, "Probar"), ...inputs, text, el("p", null, el("button", null, "Enviar")), output);
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    let url = path;
+    const query = new URLSearchParams();
+    for (const input of form.querySelectorAll("input")) {
+      if (input.dataset.in === "path") {
+        url = url.replace("{" + input.dataset.name + "}", encodeURIComponent(input.value));
+      } else if (input.value !== "") {
+        query.set(input.dataset.name, input.value);
+      }
+    }
+    if (query.toString()) {
+      url += "?" + query;
+    }
+
+    const headers = {};
+    const apiKey = document.getElementById("api-key").value;
+    const bearer = document.getElementById("bearer").value;
+    if (apiKey) {
+      headers["X-API-Key"] = apiKey;
+    }
+    if (bearer) {
+      headers["Authorization"] = "Bearer " + bearer;
+    }
+    const init = { method: method.toUpperCase(), headers };
+    if (text) {
+      headers["Content-Type"] = "application/json";
+      init.body = text.value;
+    }
+
+    output.hidden = false;
+    try {
+      const response = await fetch(url, init);
+      let content = await response.text();
+      try {
+        content = JSON.stringify(JSON.parse(content), null, 2);
+      } catch (_) {
+        // not JSON, shown as is
+      }
+      output.textContent = init.method + " " + url + "\n" + response.status + " " + response.statusText + "\n\n" + content;
+    } catch (error) {
+      output.textContent = String(error);
+    }
+  });
+  return form;
+}
+
+// operation renders an operation of the document
+function operation(doc, method, path, item, op) {
+  const params = (item.parameters || []).concat(op.parameters || []).map((p) => resolve(doc, p));
+  let body = null;
+  let request = null;
+  if (op.requestBody) {
+    const requestBody = resolve(doc, op.requestBody);
+    const [type, media] = Object.entries(requestBody.content || {})[0] || [];
+    if (type === "application/json") {
+      body = media;
+    }
+    request = el("div", null, el("h3", null, "Cuerpo (" + type + ")"), sample(doc, requestBody.content));
+  }
+  const form = method === "get" || body || !op.requestBody ? tryIt(doc, method, path, params, body) : null;
+
+  return el("details", { class: "operation" },
+    el("summary", null,
+      el("span", { class: "method " + method }, method),
+      el("span", { class: "path" }, path),
+      el("span", { class: "summary" }, op.summary || "")),
+    el("div", null,
+      op.description ? el("p", null, op.description) : null,
+      parameters(doc, params),
+      request,
+      responses(doc, op.responses),
+      form));
+}
+
+// render renders the document grouped by tag
+function render(doc) {
+  document.title = doc.info.title;
+  document.getElementById("title").textContent = doc.info.title;
+  document.getElementById("version").textContent = "Versión " + doc.info.version;
+  for (const paragraph of (doc.info.description || "").split("\n\n")) {
+    document.getElementById("description").append(el("p", null, paragraph));
+  }
+
+  const groups = new Map((doc.tags || []).map((tag) => [tag.name, { tag, operations: [] }]));
+  for (const [path, item] of Object.entries(doc.paths)) {
+    for (const method of ["get", "post", "put", "patch", "delete"]) {
+      const op = item[method];
+      if (!op) {
+        continue;
+      }
+      const name = (op.tags || ["default"])[0];
+      if (!groups.has(name)) {
+        groups.set(name, { tag: { name }, operations: [] });
+      }
+      groups.get(name).operations.push(operation(doc, method, path, item, op));
+    }
+  }
+
+  const main = document.getElementById("operations");
+  main.replaceChildren();
+  for (const { tag, operations } of groups.values()) {
+    if (operations.length === 0) {
+      continue;
+    }
+    main.append(el("section", null, el("h2", null, tag.name), tag.description ? el("p", null, tag.description) : null, ...operations));
+  }
+}
+
+fetch("/openapi.json")
+  .then((response) => response.json())
+  .then(render)
+  .catch((error) => {
+    document.getElementById("operations").replaceChildren(el("p", null, "No se pudo cargar el documento: " + error));
+  });
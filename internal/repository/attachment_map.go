@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"sync"
+)
+
+// NewAttachmentMap is a function that returns a new instance of AttachmentMap
+func NewAttachmentMap(db map[int]models.Attachment) *AttachmentMap {
+	// default db
+	defaultDb := make(map[int]models.Attachment)
+	if db != nil {
+		defaultDb = db
+	}
+
+	// next id
+	lastId := 0
+	for id := range defaultDb {
+		if id > lastId {
+			lastId = id
+		}
+	}
+	return &AttachmentMap{db: defaultDb, lastId: lastId}
+}
+
+// AttachmentMap is a struct that represents an attachment repository
+type AttachmentMap struct {
+	// mu protects db and lastId
+	mu sync.RWMutex
+	// db is a map of attachments
+	db map[int]models.Attachment
+	// lastId is the last identifier assigned to an attachment
+	lastId int
+}
+
+func (r *AttachmentMap) AddAttachment(attachment models.Attachment) (models.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	attachment.Id = r.lastId
+	r.db[attachment.Id] = attachment
+	return attachment, nil
+}
+
+func (r *AttachmentMap) GetAttachmentById(id int) (models.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachment, exists := r.db[id]
+	if !exists {
+		return models.Attachment{}, errors.New("Attachment not found")
+	}
+	return attachment, nil
+}
+
+func (r *AttachmentMap) FindAttachmentsByVehicle(vehicleId int) (a map[int]models.Attachment) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a = make(map[int]models.Attachment)
+	for key, value := range r.db {
+		if value.VehicleId == vehicleId {
+			a[key] = value
+		}
+	}
+	return a
+}
+
+func (r *AttachmentMap) DeleteAttachment(id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.db[id]
+	if !exists {
+		return errors.New("Attachment not found")
+	}
+
+	delete(r.db, id)
+	return nil
+}
+
+func (r *AttachmentMap) CountByHash(hash string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, value := range r.db {
+		if value.Hash == hash || value.ThumbnailHash == hash {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,14 @@
+package repository
+
+import "app/pkg/models"
+
+// AttachmentRepository is an interface that represents an attachment repository
+type AttachmentRepository interface {
+	// AddAttachment is a method that saves an attachment assigning it a new identifier
+	AddAttachment(attachment models.Attachment) (models.Attachment, error)
+	GetAttachmentById(id int) (models.Attachment, error)
+	FindAttachmentsByVehicle(vehicleId int) (a map[int]models.Attachment)
+	DeleteAttachment(id int) (err error)
+	// CountByHash is a method that returns how many attachments reference a content or thumbnail hash
+	CountByHash(hash string) int
+}
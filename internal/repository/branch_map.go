@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"sync"
+)
+
+// NewBranchMap is a function that returns a new instance of BranchMap
+func NewBranchMap(db map[int]models.Branch) *BranchMap {
+	// default db
+	defaultDb := make(map[int]models.Branch)
+	if db != nil {
+		defaultDb = db
+	}
+
+	// next id
+	lastId := 0
+	for id := range defaultDb {
+		if id > lastId {
+			lastId = id
+		}
+	}
+	return &BranchMap{db: defaultDb, lastId: lastId}
+}
+
+// BranchMap is a struct that represents a branch repository
+type BranchMap struct {
+	// mu protects db and lastId
+	mu sync.RWMutex
+	// db is a map of branches
+	db map[int]models.Branch
+	// lastId is the last identifier assigned to a branch
+	lastId int
+}
+
+func (r *BranchMap) FindAll() (b map[int]models.Branch, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b = make(map[int]models.Branch)
+	for key, value := range r.db {
+		b[key] = value
+	}
+	return
+}
+
+func (r *BranchMap) AddBranch(branch models.Branch) (models.Branch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	branch.Id = r.lastId
+	r.db[branch.Id] = branch
+	return branch, nil
+}
+
+func (r *BranchMap) GetBranchById(id int) (models.Branch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	branch, exists := r.db[id]
+	if !exists {
+		return models.Branch{}, errors.New("Branch not found")
+	}
+	return branch, nil
+}
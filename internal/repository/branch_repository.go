@@ -0,0 +1,12 @@
+package repository
+
+import "app/pkg/models"
+
+// BranchRepository is an interface that represents a branch repository
+type BranchRepository interface {
+	// FindAll is a method that returns a map of all branches
+	FindAll() (b map[int]models.Branch, err error)
+	// AddBranch is a method that saves a branch assigning it a new identifier
+	AddBranch(branch models.Branch) (models.Branch, error)
+	GetBranchById(id int) (models.Branch, error)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"sync"
+)
+
+// NewImportJobMap is a function that returns a new instance of ImportJobMap
+func NewImportJobMap() *ImportJobMap {
+	return &ImportJobMap{db: make(map[int]models.ImportJob)}
+}
+
+// ImportJobMap is a struct that represents an import job repository, the jobs are lost on restart
+type ImportJobMap struct {
+	// mu protects db and lastId
+	mu sync.RWMutex
+	// db is a map of import jobs
+	db map[int]models.ImportJob
+	// lastId is the last identifier assigned to a job
+	lastId int
+}
+
+func (r *ImportJobMap) AddJob(job models.ImportJob) (models.ImportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	job.Id = r.lastId
+	r.db[job.Id] = job
+	return job, nil
+}
+
+func (r *ImportJobMap) GetJobById(id int) (models.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.db[id]
+	if !exists {
+		return models.ImportJob{}, errors.New("Import job not found")
+	}
+	return job, nil
+}
+
+func (r *ImportJobMap) UpdateJob(job models.ImportJob) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.db[job.Id]
+	if !exists {
+		return errors.New("Import job not found")
+	}
+
+	r.db[job.Id] = job
+	return nil
+}
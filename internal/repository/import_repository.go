@@ -0,0 +1,11 @@
+package repository
+
+import "app/pkg/models"
+
+// ImportJobRepository is an interface that represents an import job repository
+type ImportJobRepository interface {
+	// AddJob is a method that saves a job assigning it a new identifier
+	AddJob(job models.ImportJob) (models.ImportJob, error)
+	GetJobById(id int) (models.ImportJob, error)
+	UpdateJob(job models.ImportJob) (err error)
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// NewMaintenanceMap is a function that returns a new instance of MaintenanceMap
+func NewMaintenanceMap() *MaintenanceMap {
+	return &MaintenanceMap{
+		records:   make(map[int]models.MaintenanceRecord),
+		schedules: make(map[int]models.MaintenanceSchedule),
+	}
+}
+
+// MaintenanceMap is a struct that represents a maintenance repository
+type MaintenanceMap struct {
+	// mu protects the maps and the identifiers
+	mu sync.RWMutex
+	// records is a map of workshop interventions
+	records map[int]models.MaintenanceRecord
+	// lastRecordId is the last identifier assigned to a record
+	lastRecordId int
+	// schedules is a map of recurring maintenance schedules
+	schedules map[int]models.MaintenanceSchedule
+	// lastScheduleId is the last identifier assigned to a schedule
+	lastScheduleId int
+}
+
+func (r *MaintenanceMap) AddRecord(record models.MaintenanceRecord) (models.MaintenanceRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastRecordId++
+	record.Id = r.lastRecordId
+	r.records[record.Id] = record
+	return record, nil
+}
+
+func (r *MaintenanceMap) FindRecordsByVehicle(vehicleId int) (m []models.MaintenanceRecord) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, value := range r.records {
+		if value.VehicleId == vehicleId {
+			m = append(m, value)
+		}
+	}
+
+	// oldest first, ties resolved by insertion order
+	sort.Slice(m, func(i, j int) bool {
+		if m[i].Date.Equal(m[j].Date) {
+			return m[i].Id < m[j].Id
+		}
+		return m[i].Date.Before(m[j].Date)
+	})
+	return m
+}
+
+func (r *MaintenanceMap) DeleteRecord(id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.records[id]
+	if !exists {
+		return errors.New("Maintenance record not found")
+	}
+
+	delete(r.records, id)
+	return nil
+}
+
+func (r *MaintenanceMap) AddSchedule(schedule models.MaintenanceSchedule) (models.MaintenanceSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastScheduleId++
+	schedule.Id = r.lastScheduleId
+	r.schedules[schedule.Id] = schedule
+	return schedule, nil
+}
+
+func (r *MaintenanceMap) FindSchedules() (m map[int]models.MaintenanceSchedule) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m = make(map[int]models.MaintenanceSchedule)
+	for key, value := range r.schedules {
+		m[key] = value
+	}
+	return m
+}
+
+func (r *MaintenanceMap) FindSchedulesByVehicle(vehicleId int) (m map[int]models.MaintenanceSchedule) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m = make(map[int]models.MaintenanceSchedule)
+	for key, value := range r.schedules {
+		if value.VehicleId == vehicleId {
+			m[key] = value
+		}
+	}
+	return m
+}
+
+func (r *MaintenanceMap) DeleteByVehicle(vehicleId int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, value := range r.records {
+		if value.VehicleId == vehicleId {
+			delete(r.records, key)
+		}
+	}
+	for key, value := range r.schedules {
+		if value.VehicleId == vehicleId {
+			delete(r.schedules, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package repository
+
+import "app/pkg/models"
+
+// MaintenanceRepository is an interface that represents a maintenance repository
+type MaintenanceRepository interface {
+	// AddRecord is a method that saves a record assigning it a new identifier
+	AddRecord(record models.MaintenanceRecord) (models.MaintenanceRecord, error)
+	// FindRecordsByVehicle is a method that returns the records of a vehicle ordered by date
+	FindRecordsByVehicle(vehicleId int) (m []models.MaintenanceRecord)
+	// DeleteRecord is a method that removes a record
+	DeleteRecord(id int) (err error)
+	// AddSchedule is a method that saves a schedule assigning it a new identifier
+	AddSchedule(schedule models.MaintenanceSchedule) (models.MaintenanceSchedule, error)
+	// FindSchedules is a method that returns all the schedules
+	FindSchedules() (m map[int]models.MaintenanceSchedule)
+	// FindSchedulesByVehicle is a method that returns the schedules of a vehicle
+	FindSchedulesByVehicle(vehicleId int) (m map[int]models.MaintenanceSchedule)
+	// DeleteByVehicle is a method that removes the records and schedules of a vehicle
+	DeleteByVehicle(vehicleId int) (err error)
+}
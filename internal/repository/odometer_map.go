@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"sort"
+	"sync"
+)
+
+// NewOdometerMap is a function that returns a new instance of OdometerMap
+func NewOdometerMap(db map[int]models.OdometerReading) *OdometerMap {
+	// default db
+	defaultDb := make(map[int]models.OdometerReading)
+	if db != nil {
+		defaultDb = db
+	}
+
+	// next id
+	lastId := 0
+	for id := range defaultDb {
+		if id > lastId {
+			lastId = id
+		}
+	}
+	return &OdometerMap{db: defaultDb, lastId: lastId}
+}
+
+// OdometerMap is a struct that represents an odometer reading repository
+type OdometerMap struct {
+	// mu protects db and lastId
+	mu sync.RWMutex
+	// db is a map of readings
+	db map[int]models.OdometerReading
+	// lastId is the last identifier assigned to a reading
+	lastId int
+}
+
+func (r *OdometerMap) AddReading(reading models.OdometerReading) (models.OdometerReading, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	reading.Id = r.lastId
+	r.db[reading.Id] = reading
+	return reading, nil
+}
+
+func (r *OdometerMap) FindReadingsByVehicle(vehicleId int) (readings []models.OdometerReading) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, value := range r.db {
+		if value.VehicleId == vehicleId {
+			readings = append(readings, value)
+		}
+	}
+
+	// oldest first, ties resolved by insertion order
+	sort.Slice(readings, func(i, j int) bool {
+		if readings[i].Date.Equal(readings[j].Date) {
+			return readings[i].Id < readings[j].Id
+		}
+		return readings[i].Date.Before(readings[j].Date)
+	})
+	return readings
+}
+
+func (r *OdometerMap) DeleteReadingsByVehicle(vehicleId int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, value := range r.db {
+		if value.VehicleId == vehicleId {
+			delete(r.db, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "app/pkg/models"
+
+// OdometerRepository is an interface that represents an odometer reading repository
+type OdometerRepository interface {
+	// AddReading is a method that saves a reading assigning it a new identifier
+	AddReading(reading models.OdometerReading) (models.OdometerReading, error)
+	// FindReadingsByVehicle is a method that returns the readings of a vehicle ordered by date
+	FindReadingsByVehicle(vehicleId int) (r []models.OdometerReading)
+	// DeleteReadingsByVehicle is a method that removes the readings of a vehicle
+	DeleteReadingsByVehicle(vehicleId int) (err error)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// NewQuoteMap is a function that returns a new instance of QuoteMap
+func NewQuoteMap(db map[int]models.Quote) *QuoteMap {
+	// default db
+	defaultDb := make(map[int]models.Quote)
+	if db != nil {
+		defaultDb = db
+	}
+
+	// next id
+	lastId := 0
+	for id := range defaultDb {
+		if id > lastId {
+			lastId = id
+		}
+	}
+	return &QuoteMap{db: defaultDb, lastId: lastId}
+}
+
+// QuoteMap is a struct that represents a quote repository
+type QuoteMap struct {
+	// mu protects db and lastId
+	mu sync.RWMutex
+	// db is a map of quotes
+	db map[int]models.Quote
+	// lastId is the last identifier assigned to a quote
+	lastId int
+}
+
+func (r *QuoteMap) AddQuote(quote models.Quote) (models.Quote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	quote.Id = r.lastId
+	r.db[quote.Id] = quote
+	return quote, nil
+}
+
+func (r *QuoteMap) GetQuoteById(id int) (models.Quote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quote, exists := r.db[id]
+	if !exists {
+		return models.Quote{}, errors.New("Quote not found")
+	}
+	return quote, nil
+}
+
+func (r *QuoteMap) FindQuotesByCustomer(document string) (q map[int]models.Quote) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q = make(map[int]models.Quote)
+	for key, value := range r.db {
+		if strings.EqualFold(value.CustomerDocument, document) {
+			q[key] = value
+		}
+	}
+	return q
+}
+
+func (r *QuoteMap) FindQuotesByVehicle(vehicleId int) (q map[int]models.Quote) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q = make(map[int]models.Quote)
+	for key, value := range r.db {
+		if value.Plan.VehicleId == vehicleId {
+			q[key] = value
+		}
+	}
+	return q
+}
+
+func (r *QuoteMap) DeleteQuotesByVehicle(vehicleId int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, value := range r.db {
+		if value.Plan.VehicleId == vehicleId {
+			delete(r.db, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import "app/pkg/models"
+
+// QuoteRepository is an interface that represents a financing quote repository
+type QuoteRepository interface {
+	// AddQuote is a method that saves a quote assigning it a new identifier
+	AddQuote(quote models.Quote) (models.Quote, error)
+	// GetQuoteById is a method that returns a quote by its identifier
+	GetQuoteById(id int) (models.Quote, error)
+	// FindQuotesByCustomer is a method that returns the quotes of a customer document
+	FindQuotesByCustomer(document string) (q map[int]models.Quote)
+	// FindQuotesByVehicle is a method that returns the quotes of a vehicle
+	FindQuotesByVehicle(vehicleId int) (q map[int]models.Quote)
+	// DeleteQuotesByVehicle is a method that removes the quotes of a vehicle
+	DeleteQuotesByVehicle(vehicleId int) (err error)
+}
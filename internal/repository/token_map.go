@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"sync"
+	"time"
+)
+
+// NewTokenMap is a function that returns a new instance of TokenMap
+func NewTokenMap() *TokenMap {
+	return &TokenMap{
+		refresh: make(map[string]models.RefreshToken),
+		revoked: make(map[string]time.Time),
+		resets:  make(map[string]models.PasswordReset),
+	}
+}
+
+// TokenMap is a struct that represents a token repository
+type TokenMap struct {
+	// mu protects the maps
+	mu sync.RWMutex
+	// refresh are the refresh tokens by hash
+	refresh map[string]models.RefreshToken
+	// revoked are the expiration of the revoked access tokens by id
+	revoked map[string]time.Time
+	// resets are the password reset tokens by hash
+	resets map[string]models.PasswordReset
+}
+
+func (r *TokenMap) AddRefreshToken(token models.RefreshToken) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refresh[token.Hash] = token
+	return nil
+}
+
+func (r *TokenMap) GetRefreshToken(hash string) (models.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, exists := r.refresh[hash]
+	if !exists {
+		return models.RefreshToken{}, errors.New("Token not found")
+	}
+	return token, nil
+}
+
+func (r *TokenMap) RevokeRefreshToken(hash string, at time.Time) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, exists := r.refresh[hash]
+	if !exists {
+		return errors.New("Token not found")
+	}
+	if token.RevokedAt != nil {
+		return errors.New("Token already revoked")
+	}
+	token.RevokedAt = &at
+	r.refresh[hash] = token
+	return nil
+}
+
+func (r *TokenMap) RevokeRefreshTokensByUser(userId int, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.refresh {
+		if token.UserId == userId && token.RevokedAt == nil {
+			token.RevokedAt = &at
+			r.refresh[hash] = token
+		}
+	}
+}
+
+func (r *TokenMap) RevokeAccessToken(id string, expiresAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// forget the tokens that expired, they are rejected anyway
+	now := time.Now()
+	for key, exp := range r.revoked {
+		if exp.Before(now) {
+			delete(r.revoked, key)
+		}
+	}
+	r.revoked[id] = expiresAt
+}
+
+func (r *TokenMap) IsAccessTokenRevoked(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, revoked := r.revoked[id]
+	return revoked
+}
+
+func (r *TokenMap) AddPasswordReset(reset models.PasswordReset) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resets[reset.Hash] = reset
+	return nil
+}
+
+func (r *TokenMap) GetPasswordReset(hash string) (models.PasswordReset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reset, exists := r.resets[hash]
+	if !exists {
+		return models.PasswordReset{}, errors.New("Token not found")
+	}
+	return reset, nil
+}
+
+func (r *TokenMap) UsePasswordReset(hash string, at time.Time) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reset, exists := r.resets[hash]
+	if !exists {
+		return errors.New("Token not found")
+	}
+	if reset.UsedAt != nil {
+		return errors.New("Token already used")
+	}
+	reset.UsedAt = &at
+	r.resets[hash] = reset
+	return nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"time"
+)
+
+// TokenRepository is an interface that represents where the refresh tokens, the revoked
+// access tokens and the password reset tokens are kept
+type TokenRepository interface {
+	AddRefreshToken(token models.RefreshToken) (err error)
+	GetRefreshToken(hash string) (models.RefreshToken, error)
+	// RevokeRefreshToken is a method that marks the token as used, it fails if it already was
+	RevokeRefreshToken(hash string, at time.Time) (err error)
+	// RevokeRefreshTokensByUser is a method that revokes every token of the user still valid
+	RevokeRefreshTokensByUser(userId int, at time.Time)
+	// RevokeAccessToken is a method that rejects the access token until it expires
+	RevokeAccessToken(id string, expiresAt time.Time)
+	IsAccessTokenRevoked(id string) bool
+	AddPasswordReset(reset models.PasswordReset) (err error)
+	GetPasswordReset(hash string) (models.PasswordReset, error)
+	// UsePasswordReset is a method that marks the token as used, it fails if it already was
+	UsePasswordReset(hash string, at time.Time) (err error)
+}
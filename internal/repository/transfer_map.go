@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NewTransferMap is a function that returns a new instance of TransferMap
+func NewTransferMap(db map[int]models.Transfer) *TransferMap {
+	// default db
+	defaultDb := make(map[int]models.Transfer)
+	if db != nil {
+		defaultDb = db
+	}
+
+	// next id
+	lastId := 0
+	for id := range defaultDb {
+		if id > lastId {
+			lastId = id
+		}
+	}
+	return &TransferMap{db: defaultDb, lastId: lastId}
+}
+
+// TransferMap is a struct that represents a transfer repository
+type TransferMap struct {
+	// mu protects db and lastId
+	mu sync.RWMutex
+	// db is a map of transfers
+	db map[int]models.Transfer
+	// lastId is the last identifier assigned to a transfer
+	lastId int
+}
+
+func (r *TransferMap) AddTransfer(transfer models.Transfer) (models.Transfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	transfer.Id = r.lastId
+	r.db[transfer.Id] = transfer
+	return transfer, nil
+}
+
+func (r *TransferMap) GetTransferById(id int) (models.Transfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transfer, exists := r.db[id]
+	if !exists {
+		return models.Transfer{}, errors.New("Transfer not found")
+	}
+	return transfer, nil
+}
+
+func (r *TransferMap) UpdateTransfer(transfer models.Transfer) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.db[transfer.Id]
+	if !exists {
+		return errors.New("Transfer not found")
+	}
+
+	r.db[transfer.Id] = transfer
+	return nil
+}
+
+func (r *TransferMap) FindTransfers(status string) (t map[int]models.Transfer) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t = make(map[int]models.Transfer)
+	for key, value := range r.db {
+		if status == "" || strings.EqualFold(value.Status, status) {
+			t[key] = value
+		}
+	}
+	return t
+}
+
+func (r *TransferMap) FindTransfersByVehicle(vehicleId int) (t []models.Transfer) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, value := range r.db {
+		if value.VehicleId == vehicleId {
+			t = append(t, value)
+		}
+	}
+
+	sort.Slice(t, func(i, j int) bool {
+		return t[i].Id < t[j].Id
+	})
+	return t
+}
+
+func (r *TransferMap) DeleteTransfersByVehicle(vehicleId int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, value := range r.db {
+		if value.VehicleId == vehicleId {
+			delete(r.db, key)
+		}
+	}
+	return nil
+}
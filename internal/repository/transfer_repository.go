@@ -0,0 +1,17 @@
+package repository
+
+import "app/pkg/models"
+
+// TransferRepository is an interface that represents a transfer repository
+type TransferRepository interface {
+	// AddTransfer is a method that saves a transfer assigning it a new identifier
+	AddTransfer(transfer models.Transfer) (models.Transfer, error)
+	GetTransferById(id int) (models.Transfer, error)
+	UpdateTransfer(transfer models.Transfer) (err error)
+	// FindTransfers is a method that returns the transfers with a status, all of them if it is empty
+	FindTransfers(status string) (t map[int]models.Transfer)
+	// FindTransfersByVehicle is a method that returns the transfer history of a vehicle ordered by request date
+	FindTransfersByVehicle(vehicleId int) (t []models.Transfer)
+	// DeleteTransfersByVehicle is a method that removes the transfers of a vehicle
+	DeleteTransfersByVehicle(vehicleId int) (err error)
+}
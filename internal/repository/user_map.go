@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// NewUserMap is a function that returns a new instance of UserMap
+func NewUserMap(db map[int]models.User) *UserMap {
+	// default db
+	defaultDb := make(map[int]models.User)
+	if db != nil {
+		defaultDb = db
+	}
+
+	// next id
+	lastId := 0
+	for id := range defaultDb {
+		if id > lastId {
+			lastId = id
+		}
+	}
+	return &UserMap{db: defaultDb, lastId: lastId}
+}
+
+// UserMap is a struct that represents a user repository
+type UserMap struct {
+	// mu protects db and lastId
+	mu sync.RWMutex
+	// db is a map of users
+	db map[int]models.User
+	// lastId is the last identifier assigned to a user
+	lastId int
+}
+
+func (r *UserMap) FindAll() (u map[int]models.User, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u = make(map[int]models.User)
+	for key, value := range r.db {
+		u[key] = value
+	}
+	return
+}
+
+func (r *UserMap) AddUser(user models.User) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// usernames are unique regardless of case
+	for _, u := range r.db {
+		if strings.EqualFold(u.Username, user.Username) {
+			return models.User{}, errors.New("Username already exists")
+		}
+	}
+
+	r.lastId++
+	user.Id = r.lastId
+	r.db[user.Id] = user
+	return user, nil
+}
+
+func (r *UserMap) GetUserById(id int) (models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.db[id]
+	if !exists {
+		return models.User{}, errors.New("User not found")
+	}
+	return user, nil
+}
+
+func (r *UserMap) GetUserByUsername(username string) (models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.db {
+		if strings.EqualFold(user.Username, username) {
+			return user, nil
+		}
+	}
+	return models.User{}, errors.New("User not found")
+}
+
+func (r *UserMap) UpdateUser(user models.User) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.db[user.Id]; !exists {
+		return errors.New("User not found")
+	}
+	r.db[user.Id] = user
+	return nil
+}
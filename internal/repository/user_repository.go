@@ -0,0 +1,14 @@
+package repository
+
+import "app/pkg/models"
+
+// UserRepository is an interface that represents a user repository
+type UserRepository interface {
+	// FindAll is a method that returns a map of all users
+	FindAll() (u map[int]models.User, err error)
+	// AddUser is a method that saves a user assigning it a new identifier
+	AddUser(user models.User) (models.User, error)
+	GetUserById(id int) (models.User, error)
+	GetUserByUsername(username string) (models.User, error)
+	UpdateUser(user models.User) (err error)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"app/pkg/models"
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// VehicleSaver is an interface that represents where the vehicles are persisted
+type VehicleSaver interface {
+	// Save is a method that writes all the vehicles
+	Save(v map[int]models.Vehicle) (err error)
+	// Ping is a method that checks the vehicles can be written
+	Ping() (err error)
+}
+
+// NewVehicleFile is a function that returns a new instance of VehicleFile.
+// With a zero flushInterval every change is written through, otherwise the
+// changes are written in batches by Run and on Flush.
+func NewVehicleFile(db map[int]models.Vehicle, sv VehicleSaver, flushInterval time.Duration) *VehicleFile {
+	return &VehicleFile{VehicleMap: NewVehicleMap(db), sv: sv, flushInterval: flushInterval}
+}
+
+// VehicleFile is a struct that represents a vehicle repository kept in memory
+// that persists its changes to a file
+type VehicleFile struct {
+	// VehicleMap answers the queries and keeps the changes in memory
+	*VehicleMap
+	// mu serializes the writes to the file
+	mu sync.Mutex
+	// sv is where the vehicles are persisted
+	sv VehicleSaver
+	// flushInterval is the time between batched writes, zero to write every change
+	flushInterval time.Duration
+	// dirty tells if there are changes not written yet
+	dirty atomic.Bool
+}
+
+// Flush is a method that writes the pending changes to the file
+func (r *VehicleFile) Flush() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.dirty.Swap(false) {
+		return nil
+	}
+
+	v, err := r.VehicleMap.FindAll(context.Background())
+	if err == nil {
+		err = r.sv.Save(v)
+	}
+	if err != nil {
+		// keep the changes pending so the next flush retries them
+		r.dirty.Store(true)
+	}
+	return
+}
+
+// Run is a method that writes the pending changes every flushInterval until the context is done
+func (r *VehicleFile) Run(ctx context.Context) {
+	if r.flushInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				slog.Error("flushing vehicles", "error", err)
+			}
+		}
+	}
+}
+
+// changed marks the changes as pending and writes them unless they are batched
+func (r *VehicleFile) changed() error {
+	r.dirty.Store(true)
+	if r.flushInterval > 0 {
+		return nil
+	}
+	return r.Flush()
+}
+
+func (r *VehicleFile) AddVehicle(ctx context.Context, newVehicle models.Vehicle) (models.Vehicle, error) {
+	vehicle, err := r.VehicleMap.AddVehicle(ctx, newVehicle)
+	if err != nil {
+		return models.Vehicle{}, err
+	}
+	return vehicle, r.changed()
+}
+
+func (r *VehicleFile) UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error) {
+	if err = r.VehicleMap.UpdateMaxSpeed(ctx, id, newSpeed); err != nil {
+		return
+	}
+	return r.changed()
+}
+
+func (r *VehicleFile) DeleteVehicle(ctx context.Context, id int) (err error) {
+	if err = r.VehicleMap.DeleteVehicle(ctx, id); err != nil {
+		return
+	}
+	return r.changed()
+}
+
+func (r *VehicleFile) UpdateFuel(ctx context.Context, id int, newFuel string) (err error) {
+	if err = r.VehicleMap.UpdateFuel(ctx, id, newFuel); err != nil {
+		return
+	}
+	return r.changed()
+}
+
+func (r *VehicleFile) UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error) {
+	if err = r.VehicleMap.UpdatePrice(ctx, id, newPrice); err != nil {
+		return
+	}
+	return r.changed()
+}
+
+func (r *VehicleFile) UpdateMileage(ctx context.Context, id int, newMileage int) (err error) {
+	if err = r.VehicleMap.UpdateMileage(ctx, id, newMileage); err != nil {
+		return
+	}
+	return r.changed()
+}
+
+func (r *VehicleFile) UpdateBranch(ctx context.Context, id int, branchId int, status string) (err error) {
+	if err = r.VehicleMap.UpdateBranch(ctx, id, branchId, status); err != nil {
+		return
+	}
+	return r.changed()
+}
+
+func (r *VehicleFile) UpdateStatus(ctx context.Context, id int, status string) (err error) {
+	if err = r.VehicleMap.UpdateStatus(ctx, id, status); err != nil {
+		return
+	}
+	return r.changed()
+}
+
+func (r *VehicleFile) Replace(db map[int]models.Vehicle, merge bool) (kept int, dropped []int, err error) {
+	if kept, dropped, err = r.VehicleMap.Replace(db, merge); err != nil {
+		return
+	}
+	return kept, dropped, r.changed()
+}
+
+// Ping is a method that checks the file can still be written
+func (r *VehicleFile) Ping(ctx context.Context) (err error) {
+	return r.sv.Ping()
+}
@@ -2,8 +2,13 @@ package repository
 
 import (
 	"app/pkg/models"
+	"context"
 	"errors"
+	"slices"
 	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
 )
 
 // NewVehicleMap is a function that returns a new instance of VehicleMap
@@ -13,17 +18,24 @@ func NewVehicleMap(db map[int]models.Vehicle) *VehicleMap {
 	if db != nil {
 		defaultDb = db
 	}
-	return &VehicleMap{db: defaultDb}
+	return &VehicleMap{db: defaultDb, touched: make(map[int]bool)}
 }
 
 // VehicleMap is a struct that represents a vehicle repository
 type VehicleMap struct {
+	// mu protects db from concurrent requests
+	mu sync.RWMutex
 	// db is a map of vehicles
 	db map[int]models.Vehicle
+	// touched are the ids of the vehicles added, updated or deleted since they were loaded
+	touched map[int]bool
 }
 
 // FindAll is a method that returns a map of all vehicles
-func (r *VehicleMap) FindAll() (v map[int]models.Vehicle, err error) {
+func (r *VehicleMap) FindAll(ctx context.Context) (v map[int]models.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]models.Vehicle)
 
 	// copy db
@@ -34,12 +46,19 @@ func (r *VehicleMap) FindAll() (v map[int]models.Vehicle, err error) {
 	return
 }
 
-func (r *VehicleMap) AddVehicle(newVehicle models.Vehicle) (models.Vehicle, error) {
+func (r *VehicleMap) AddVehicle(ctx context.Context, newVehicle models.Vehicle) (models.Vehicle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.db[newVehicle.Id] = newVehicle
+	r.touched[newVehicle.Id] = true
 	return newVehicle, nil
 }
 
-func (r *VehicleMap) GetVehicleById(id int) (models.Vehicle, error) {
+func (r *VehicleMap) GetVehicleById(ctx context.Context, id int) (models.Vehicle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	// try to get the vehicle form the db by its key
 	vehicle, exists := r.db[id]
 
@@ -53,7 +72,10 @@ func (r *VehicleMap) GetVehicleById(id int) (models.Vehicle, error) {
 }
 
 // FindVehiclesByColorAndYear implements VehicleRepository.
-func (r *VehicleMap) FindVehiclesByColorAndYear(color string, year int) (v map[int]models.Vehicle) {
+func (r *VehicleMap) FindVehiclesByColorAndYear(ctx context.Context, color string, year int) (v map[int]models.Vehicle) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]models.Vehicle)
 
 	// copy db
@@ -68,7 +90,10 @@ func (r *VehicleMap) FindVehiclesByColorAndYear(color string, year int) (v map[i
 	return v
 }
 
-func (r *VehicleMap) FindVehiclesByBrandAndRangeYears(brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error) {
+func (r *VehicleMap) FindVehiclesByBrandAndRangeYears(ctx context.Context, brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]models.Vehicle)
 	for key, value := range r.db {
 		vehicle := r.db[key]
@@ -79,7 +104,10 @@ func (r *VehicleMap) FindVehiclesByBrandAndRangeYears(brand string, starYear int
 	return
 }
 
-func (r *VehicleMap) FindVehiclesByBrand(brand string) (v map[int]models.Vehicle, err error) {
+func (r *VehicleMap) FindVehiclesByBrand(ctx context.Context, brand string) (v map[int]models.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]models.Vehicle)
 	for key, value := range r.db {
 		vehicle := r.db[key]
@@ -90,17 +118,24 @@ func (r *VehicleMap) FindVehiclesByBrand(brand string) (v map[int]models.Vehicle
 	return
 }
 
-func (r *VehicleMap) UpdateMaxSpeed(id int, newSpeed float64) (err error) {
+func (r *VehicleMap) UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	vehicle, exists := r.db[id]
 	if !exists {
 		return errors.New("Vehicle not found") // Si el vehículo no existe, devuelve un error
 	}
 	vehicle.MaxSpeed = newSpeed
 	r.db[id] = vehicle
+	r.touched[id] = true
 	return
 }
 
-func (r *VehicleMap) FindVehiclesByFuel(fuel string) (v map[int]models.Vehicle) {
+func (r *VehicleMap) FindVehiclesByFuel(ctx context.Context, fuel string) (v map[int]models.Vehicle) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]models.Vehicle)
 	for key, value := range r.db {
 		vehicle := r.db[key]
@@ -111,17 +146,24 @@ func (r *VehicleMap) FindVehiclesByFuel(fuel string) (v map[int]models.Vehicle)
 	return v
 }
 
-func (r *VehicleMap) DeleteVehicle(id int) (err error) {
+func (r *VehicleMap) DeleteVehicle(ctx context.Context, id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	_, exists := r.db[id]
 	if !exists {
 		return errors.New("Vehicle not found")
 	}
 
 	delete(r.db, id)
+	r.touched[id] = true
 	return nil
 }
 
-func (r *VehicleMap) FindVehiclesByTransmission(transmisiion string) (v map[int]models.Vehicle) {
+func (r *VehicleMap) FindVehiclesByTransmission(ctx context.Context, transmisiion string) (v map[int]models.Vehicle) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]models.Vehicle)
 	for key, value := range r.db {
 		vehicle := r.db[key]
@@ -132,7 +174,10 @@ func (r *VehicleMap) FindVehiclesByTransmission(transmisiion string) (v map[int]
 	return v
 }
 
-func (r *VehicleMap) UpdateFuel(id int, newFuel string) (err error) {
+func (r *VehicleMap) UpdateFuel(ctx context.Context, id int, newFuel string) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	vehicle, exists := r.db[id]
 	if !exists {
 		return errors.New("Vehicle not found")
@@ -140,10 +185,14 @@ func (r *VehicleMap) UpdateFuel(id int, newFuel string) (err error) {
 
 	vehicle.FuelType = newFuel
 	r.db[id] = vehicle
+	r.touched[id] = true
 	return nil
 }
 
-func (r *VehicleMap) GetVehiclesByBrand(brand string) (v map[int]models.Vehicle) {
+func (r *VehicleMap) GetVehiclesByBrand(ctx context.Context, brand string) (v map[int]models.Vehicle) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]models.Vehicle)
 	for key, value := range r.db {
 		vehicle := r.db[key]
@@ -154,7 +203,10 @@ func (r *VehicleMap) GetVehiclesByBrand(brand string) (v map[int]models.Vehicle)
 	return v
 }
 
-func (r *VehicleMap) FindVehiclesByDimensions(minLength float64, maxLength float64, minWidth float64, maxWidth float64) map[int]models.Vehicle {
+func (r *VehicleMap) FindVehiclesByDimensions(ctx context.Context, minLength float64, maxLength float64, minWidth float64, maxWidth float64) map[int]models.Vehicle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	vehicles := make(map[int]models.Vehicle)
 	for key, value := range r.db {
 		vehicle := r.db[key]
@@ -165,7 +217,10 @@ func (r *VehicleMap) FindVehiclesByDimensions(minLength float64, maxLength float
 	return vehicles
 }
 
-func (r *VehicleMap) FindVehiclesByWeigth(minWeigth float64, maxWeigth float64) map[int]models.Vehicle {
+func (r *VehicleMap) FindVehiclesByWeigth(ctx context.Context, minWeigth float64, maxWeigth float64) map[int]models.Vehicle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	vehicles := make(map[int]models.Vehicle)
 	for key, value := range r.db {
 		vehicle := r.db[key]
@@ -175,3 +230,132 @@ func (r *VehicleMap) FindVehiclesByWeigth(minWeigth float64, maxWeigth float64)
 	}
 	return vehicles
 }
+
+func (r *VehicleMap) UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vehicle, exists := r.db[id]
+	if !exists {
+		return errors.New("Vehicle not found")
+	}
+
+	vehicle.Price = newPrice
+	r.db[id] = vehicle
+	r.touched[id] = true
+	return nil
+}
+
+func (r *VehicleMap) UpdateMileage(ctx context.Context, id int, newMileage int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vehicle, exists := r.db[id]
+	if !exists {
+		return errors.New("Vehicle not found")
+	}
+
+	vehicle.Mileage = newMileage
+	r.db[id] = vehicle
+	r.touched[id] = true
+	return nil
+}
+
+func (r *VehicleMap) FindVehiclesByMileage(ctx context.Context, minMileage int, maxMileage int) map[int]models.Vehicle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vehicles := make(map[int]models.Vehicle)
+	for key, value := range r.db {
+		if value.Mileage >= minMileage && value.Mileage <= maxMileage {
+			vehicles[key] = value
+		}
+	}
+	return vehicles
+}
+
+func (r *VehicleMap) FindVehiclesByBranch(ctx context.Context, branchId int) map[int]models.Vehicle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	vehicles := make(map[int]models.Vehicle)
+	for key, value := range r.db {
+		if value.BranchId == branchId {
+			vehicles[key] = value
+		}
+	}
+	return vehicles
+}
+
+func (r *VehicleMap) UpdateBranch(ctx context.Context, id int, branchId int, status string) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vehicle, exists := r.db[id]
+	if !exists {
+		return errors.New("Vehicle not found")
+	}
+
+	vehicle.BranchId, vehicle.Status = branchId, status
+	r.db[id] = vehicle
+	r.touched[id] = true
+	return nil
+}
+
+func (r *VehicleMap) UpdateStatus(ctx context.Context, id int, status string) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vehicle, exists := r.db[id]
+	if !exists {
+		return errors.New("Vehicle not found")
+	}
+
+	vehicle.Status = status
+	r.db[id] = vehicle
+	r.touched[id] = true
+	return nil
+}
+
+// Replace is a method that swaps all the vehicles for the ones given, at once for the concurrent requests.
+// With merge the vehicles added, updated or deleted since the last load keep their current state, kept
+// is how many of them, and the other vehicles in both keep their mileage, branch and status. Without it
+// the vehicles are the ones given as they are, e.g. a snapshot restored. dropped are the ones that are no
+// longer there.
+func (r *VehicleMap) Replace(db map[int]models.Vehicle, merge bool) (kept int, dropped []int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if merge {
+		for id := range r.touched {
+			if vehicle, exists := r.db[id]; exists {
+				db[id] = vehicle
+			} else {
+				delete(db, id)
+			}
+		}
+		kept = len(r.touched)
+		// the odometer readings and the transfers keep them up to date, the files only describe the vehicles
+		for id, vehicle := range db {
+			if current, exists := r.db[id]; exists && !r.touched[id] {
+				vehicle.Mileage, vehicle.BranchId, vehicle.Status = current.Mileage, current.BranchId, current.Status
+				db[id] = vehicle
+			}
+		}
+	} else {
+		r.touched = make(map[int]bool)
+	}
+	for id := range r.db {
+		if _, exists := db[id]; !exists {
+			dropped = append(dropped, id)
+		}
+	}
+	slices.Sort(dropped)
+	r.db = db
+	return
+}
+
+// Ping is a method that checks the repository, the memory is always reachable
+func (r *VehicleMap) Ping(ctx context.Context) (err error) {
+	return nil
+}
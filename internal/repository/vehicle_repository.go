@@ -1,22 +1,36 @@
 package repository
 
-import "app/pkg/models"
+import (
+	"app/pkg/models"
+	"context"
+
+	"github.com/shopspring/decimal"
+)
 
 // VehicleRepository is an interface that represents a vehicle repository
 type VehicleRepository interface {
 	// FindAll is a method that returns a map of all vehicles
-	FindAll() (v map[int]models.Vehicle, err error)
-	AddVehicle(newVehicle models.Vehicle) (models.Vehicle, error)
-	GetVehicleById(id int) (models.Vehicle, error)
-	FindVehiclesByColorAndYear(color string, year int) (v map[int]models.Vehicle)
-	FindVehiclesByBrandAndRangeYears(brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error)
-	FindVehiclesByBrand(brand string) (v map[int]models.Vehicle, err error)
-	UpdateMaxSpeed(id int, newSpeed float64) (err error)
-	FindVehiclesByFuel(fuel string) (v map[int]models.Vehicle)
-	DeleteVehicle(id int) (err error)
-	FindVehiclesByTransmission(transmisiion string) (v map[int]models.Vehicle)
-	UpdateFuel(id int, newFuel string) (err error)
-	GetVehiclesByBrand(brand string) (v map[int]models.Vehicle)
-	FindVehiclesByDimensions(minLength float64, maxLength float64, minWidth float64, maxWidth float64) map[int]models.Vehicle
-	FindVehiclesByWeigth(minWeigth float64, maxWeigth float64) map[int]models.Vehicle
+	FindAll(ctx context.Context) (v map[int]models.Vehicle, err error)
+	AddVehicle(ctx context.Context, newVehicle models.Vehicle) (models.Vehicle, error)
+	GetVehicleById(ctx context.Context, id int) (models.Vehicle, error)
+	FindVehiclesByColorAndYear(ctx context.Context, color string, year int) (v map[int]models.Vehicle)
+	FindVehiclesByBrandAndRangeYears(ctx context.Context, brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error)
+	FindVehiclesByBrand(ctx context.Context, brand string) (v map[int]models.Vehicle, err error)
+	UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error)
+	FindVehiclesByFuel(ctx context.Context, fuel string) (v map[int]models.Vehicle)
+	DeleteVehicle(ctx context.Context, id int) (err error)
+	FindVehiclesByTransmission(ctx context.Context, transmisiion string) (v map[int]models.Vehicle)
+	UpdateFuel(ctx context.Context, id int, newFuel string) (err error)
+	GetVehiclesByBrand(ctx context.Context, brand string) (v map[int]models.Vehicle)
+	FindVehiclesByDimensions(ctx context.Context, minLength float64, maxLength float64, minWidth float64, maxWidth float64) map[int]models.Vehicle
+	FindVehiclesByWeigth(ctx context.Context, minWeigth float64, maxWeigth float64) map[int]models.Vehicle
+	UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error)
+	UpdateMileage(ctx context.Context, id int, newMileage int) (err error)
+	FindVehiclesByMileage(ctx context.Context, minMileage int, maxMileage int) map[int]models.Vehicle
+	FindVehiclesByBranch(ctx context.Context, branchId int) map[int]models.Vehicle
+	// UpdateBranch is a method that moves a vehicle to a branch with the status it has there, both at once
+	UpdateBranch(ctx context.Context, id int, branchId int, status string) (err error)
+	UpdateStatus(ctx context.Context, id int, status string) (err error)
+	// Ping is a method that checks the vehicles can be read and written
+	Ping(ctx context.Context) (err error)
 }
@@ -0,0 +1,171 @@
+package security
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig is a struct that represents the cross origin requests allowed by the API
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed, "*" allows any and "https://*.example.com" any subdomain.
+	// Empty turns CORS off.
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in the preflight requests
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed in the preflight requests, "*" allows any
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers readable by the scripts of the origin
+	ExposedHeaders []string
+	// AllowCredentials lets the browser send cookies and the Authorization header
+	AllowCredentials bool
+	// MaxAge is how long the browser caches a preflight response, zero leaves it to the browser
+	MaxAge time.Duration
+}
+
+// NewCORS is a function that returns a new instance of CORS
+func NewCORS(cfg CORSConfig) *CORS {
+	c := &CORS{
+		cfg:     cfg,
+		methods: make(map[string]bool),
+		headers: make(map[string]bool),
+	}
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		if origin == "*" {
+			c.anyOrigin = true
+			continue
+		}
+		c.origins = append(c.origins, origin)
+	}
+	for _, method := range cfg.AllowedMethods {
+		c.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range cfg.AllowedHeaders {
+		if header == "*" {
+			c.anyHeader = true
+			continue
+		}
+		c.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	return c
+}
+
+// CORS is a struct that answers the preflight requests and adds the CORS headers to the responses
+type CORS struct {
+	// cfg is the configuration of the cross origin requests
+	cfg CORSConfig
+	// anyOrigin is true when every origin is allowed
+	anyOrigin bool
+	// origins are the allowed origins in lower case, they may have a wildcard subdomain
+	origins []string
+	// methods are the allowed methods
+	methods map[string]bool
+	// anyHeader is true when every request header is allowed
+	anyHeader bool
+	// headers are the allowed request headers in canonical form
+	headers map[string]bool
+}
+
+// Middleware is a method that adds the CORS headers to the responses to an allowed origin,
+// and answers its preflight requests without reaching the routes
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	if !c.anyOrigin && len(c.origins) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+		if origin == "" || !c.allowedOrigin(origin) {
+			if preflight {
+				// without the allow headers the browser blocks the request
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !preflight {
+			c.allowOrigin(h, origin)
+			if len(c.cfg.ExposedHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(c.cfg.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+		headers, ok := c.allowedHeaders(r.Header.Values("Access-Control-Request-Headers"))
+		if !c.methods[method] || !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		c.allowOrigin(h, origin)
+		h.Set("Access-Control-Allow-Methods", strings.Join(c.cfg.AllowedMethods, ", "))
+		if len(headers) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		}
+		if c.cfg.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.cfg.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowOrigin sets the headers that let the origin read the response
+func (c *CORS) allowOrigin(h http.Header, origin string) {
+	if c.anyOrigin && !c.cfg.AllowCredentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.cfg.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowedOrigin reports whether the origin may call the API
+func (c *CORS) allowedOrigin(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.origins {
+		if allowed == origin {
+			return true
+		}
+		// https://*.example.com matches https://app.example.com but not https://example.com
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok &&
+			len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedHeaders returns the requested headers if all of them are allowed
+func (c *CORS) allowedHeaders(values []string) (headers []string, ok bool) {
+	for _, value := range values {
+		for _, header := range strings.Split(value, ",") {
+			header = strings.TrimSpace(header)
+			if header == "" {
+				continue
+			}
+			if !c.anyHeader && !c.headers[http.CanonicalHeaderKey(header)] {
+				return nil, false
+			}
+			headers = append(headers, header)
+		}
+	}
+	return headers, true
+}
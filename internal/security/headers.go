@@ -0,0 +1,43 @@
+package security
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultContentSecurityPolicy is the policy of an API that only serves data: nothing is loaded or framed
+const DefaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// HeadersConfig is a struct that represents the configuration of the security headers
+type HeadersConfig struct {
+	// ContentSecurityPolicy is the value of the Content-Security-Policy header
+	ContentSecurityPolicy string
+	// HSTSMaxAge is how long the browser must only use HTTPS, zero omits Strict-Transport-Security.
+	// It is only sent on the connections over TLS.
+	HSTSMaxAge time.Duration
+}
+
+// Headers is a function that returns a middleware that adds the standard security headers to every response
+func Headers(cfg HeadersConfig) func(http.Handler) http.Handler {
+	csp := cfg.ContentSecurityPolicy
+	if csp == "" {
+		csp = DefaultContentSecurityPolicy
+	}
+	hsts := "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "no-referrer")
+			h.Set("Content-Security-Policy", csp)
+			h.Set("Cross-Origin-Opener-Policy", "same-origin")
+			if cfg.HSTSMaxAge > 0 && r.TLS != nil {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
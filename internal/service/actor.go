@@ -0,0 +1,15 @@
+package service
+
+import (
+	"app/internal/auth"
+	"context"
+)
+
+// actor is a function that returns who makes the request: the username or the name of the API key,
+// empty when the request carries no principal, e.g. an import job
+func actor(ctx context.Context) string {
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		return p.Subject
+	}
+	return ""
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/internal/storage"
+	"app/pkg/models"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMaxAttachmentSize is the maximum size of an attachment when none is configured
+	defaultMaxAttachmentSize = 10 << 20
+	// thumbnailSize is the maximum width and height of a thumbnail
+	thumbnailSize = 320
+	// maxImagePixels is the maximum width×height of an image, a few compressed bytes can
+	// describe an image that takes gigabytes once decoded
+	maxImagePixels = 50_000_000
+)
+
+var (
+	// errInvalidImage is returned when the thumbnail of an image can't be made
+	errInvalidImage = errors.New("Imagen mal formada")
+	// errImageTooLarge is returned when an image has more than maxImagePixels
+	errImageTooLarge = errors.New("La imagen supera las dimensiones máximas permitidas")
+)
+
+// allowedContentTypes are the sniffed content types accepted for each kind of attachment
+var allowedContentTypes = map[string][]string{
+	models.AttachmentPhoto:      {"image/jpeg", "image/png", "image/gif"},
+	models.AttachmentTitle:      {"application/pdf", "image/jpeg", "image/png"},
+	models.AttachmentInspection: {"application/pdf", "image/jpeg", "image/png"},
+}
+
+// NewAttachmentDefault is a function that returns a new instance of AttachmentDefault
+func NewAttachmentDefault(rpVehicle repository.VehicleRepository, rpAttachment repository.AttachmentRepository, st storage.BlobStorage, maxSize int64) *AttachmentDefault {
+	// default values
+	if maxSize <= 0 {
+		maxSize = defaultMaxAttachmentSize
+	}
+	return &AttachmentDefault{rpVehicle: rpVehicle, rpAttachment: rpAttachment, st: st, maxSize: maxSize}
+}
+
+// AttachmentDefault is a struct that represents the default service for attachments
+type AttachmentDefault struct {
+	// mu guards the blobs shared by attachments with the same content: the uploads hold it for reading
+	// from saving the content to adding the attachment that references it, and the deletes hold it for
+	// writing from counting the references to removing the content
+	mu sync.RWMutex
+	// rpVehicle is the repository of the vehicles the files are attached to
+	rpVehicle repository.VehicleRepository
+	// rpAttachment is the repository of the attachments metadata
+	rpAttachment repository.AttachmentRepository
+	// st is the storage of the attachments content
+	st storage.BlobStorage
+	// maxSize is the maximum size of an attachment in bytes
+	maxSize int64
+}
+
+func (s *AttachmentDefault) Upload(ctx context.Context, vehicleId int, kind string, fileName string, content io.Reader) (attachment models.Attachment, err error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	allowed, ok := allowedContentTypes[kind]
+	if !ok {
+		return models.Attachment{}, errors.New("Tipo de adjunto no admitido")
+	}
+
+	_, err = s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+
+	// sniff the content type from the first bytes instead of trusting the client
+	reader := bufio.NewReaderSize(content, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return models.Attachment{}, err
+	}
+	if len(head) == 0 {
+		return models.Attachment{}, errors.New("El archivo está vacío")
+	}
+	contentType := strings.SplitN(http.DetectContentType(head), ";", 2)[0]
+	if !contains(allowed, contentType) {
+		return models.Attachment{}, errors.New("Formato de archivo no admitido")
+	}
+
+	attachment = models.Attachment{
+		VehicleId:   vehicleId,
+		Kind:        kind,
+		FileName:    filepath.Base(fileName),
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+	attachment, err = s.save(attachment, reader)
+	if err != nil {
+		if attachment.Hash != "" {
+			// the content is only removed if no other attachment shares it
+			s.mu.Lock()
+			s.removeUnreferenced(attachment.Hash)
+			s.mu.Unlock()
+		}
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// save stores the content and its thumbnail and adds the attachment. When the thumbnail can't be made
+// the attachment returned has the hash of the content stored, to remove it.
+func (s *AttachmentDefault) save(attachment models.Attachment, content io.Reader) (models.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hash, size, err := s.st.Save(&maxSizeReader{r: content, remaining: s.maxSize})
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	attachment.Hash = hash
+	attachment.Size = size
+
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		attachment.ThumbnailHash, err = s.saveThumbnail(hash)
+		if err != nil {
+			return attachment, err
+		}
+	}
+
+	return s.rpAttachment.AddAttachment(attachment)
+}
+
+func (s *AttachmentDefault) FindAttachmentsByVehicle(ctx context.Context, vehicleId int) (a map[int]models.Attachment, err error) {
+	_, err = s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	a = s.rpAttachment.FindAttachmentsByVehicle(vehicleId)
+	if len(a) == 0 {
+		return a, errors.New("El vehículo no tiene adjuntos")
+	}
+	return a, nil
+}
+
+func (s *AttachmentDefault) Open(ctx context.Context, vehicleId int, attachmentId int, thumbnail bool) (attachment models.Attachment, content io.ReadCloser, err error) {
+	attachment, err = s.getAttachment(vehicleId, attachmentId)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+
+	hash := attachment.Hash
+	if thumbnail {
+		if attachment.ThumbnailHash == "" {
+			return models.Attachment{}, nil, errors.New("El adjunto no tiene miniatura")
+		}
+		hash = attachment.ThumbnailHash
+	}
+
+	content, err = s.st.Open(hash)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (s *AttachmentDefault) DeleteAttachment(ctx context.Context, vehicleId int, attachmentId int) (err error) {
+	attachment, err := s.getAttachment(vehicleId, attachmentId)
+	if err != nil {
+		return err
+	}
+	return s.delete(attachment)
+}
+
+func (s *AttachmentDefault) DeleteAttachmentsByVehicle(ctx context.Context, vehicleId int) (err error) {
+	for _, attachment := range s.rpAttachment.FindAttachmentsByVehicle(vehicleId) {
+		if err = s.delete(attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getAttachment returns an attachment checking it belongs to the vehicle
+func (s *AttachmentDefault) getAttachment(vehicleId int, attachmentId int) (models.Attachment, error) {
+	attachment, err := s.rpAttachment.GetAttachmentById(attachmentId)
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	if attachment.VehicleId != vehicleId {
+		return models.Attachment{}, errors.New("Attachment not found")
+	}
+	return attachment, nil
+}
+
+// delete removes the metadata and the content no other attachment references
+func (s *AttachmentDefault) delete(attachment models.Attachment) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.rpAttachment.DeleteAttachment(attachment.Id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.removeUnreferenced(attachment.Hash); err != nil {
+		return err
+	}
+	if attachment.ThumbnailHash != "" {
+		return s.removeUnreferenced(attachment.ThumbnailHash)
+	}
+	return nil
+}
+
+// removeUnreferenced removes the content when no attachment references it, mu must be held for writing
+func (s *AttachmentDefault) removeUnreferenced(hash string) error {
+	if s.rpAttachment.CountByHash(hash) > 0 {
+		return nil
+	}
+	return s.st.Remove(hash)
+}
+
+// saveThumbnail decodes a stored image and stores a JPEG that fits in thumbnailSize
+func (s *AttachmentDefault) saveThumbnail(hash string) (string, error) {
+	// the size is read from the header before allocating the image
+	header, err := s.st.Open(hash)
+	if err != nil {
+		return "", err
+	}
+	config, _, err := image.DecodeConfig(header)
+	header.Close()
+	if err != nil {
+		return "", errInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return "", errImageTooLarge
+	}
+
+	content, err := s.st.Open(hash)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	img, _, err := image.Decode(content)
+	if err != nil {
+		return "", errInvalidImage
+	}
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, scaleDown(img, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return "", err
+	}
+
+	thumbnailHash, _, err := s.st.Save(&buf)
+	return thumbnailHash, err
+}
+
+// scaleDown returns the image resized with nearest neighbour sampling so it fits in a
+// square of the given size, keeping the aspect ratio. Smaller images are returned as is.
+func scaleDown(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return src
+	}
+
+	newWidth, newHeight := size, height*size/width
+	if height > width {
+		newWidth, newHeight = width*size/height, size
+	}
+	if newWidth == 0 {
+		newWidth = 1
+	}
+	if newHeight == 0 {
+		newHeight = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		for x := 0; x < newWidth; x++ {
+			dst.Set(x, y, src.At(bounds.Min.X+x*width/newWidth, bounds.Min.Y+y*height/newHeight))
+		}
+	}
+	return dst
+}
+
+// maxSizeReader is a reader that fails once more than remaining bytes are read
+type maxSizeReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (m *maxSizeReader) Read(p []byte) (n int, err error) {
+	n, err = m.r.Read(p)
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n, errors.New("El archivo supera el tamaño máximo permitido")
+	}
+	return n, err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
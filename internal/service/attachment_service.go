@@ -0,0 +1,19 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+	"io"
+)
+
+// AttachmentService is an interface that represents an attachment service
+type AttachmentService interface {
+	// Upload is a method that stores a file attached to a vehicle
+	Upload(ctx context.Context, vehicleId int, kind string, fileName string, content io.Reader) (attachment models.Attachment, err error)
+	FindAttachmentsByVehicle(ctx context.Context, vehicleId int) (a map[int]models.Attachment, err error)
+	// Open is a method that returns the content of an attachment or of its thumbnail
+	Open(ctx context.Context, vehicleId int, attachmentId int, thumbnail bool) (attachment models.Attachment, content io.ReadCloser, err error)
+	DeleteAttachment(ctx context.Context, vehicleId int, attachmentId int) (err error)
+	// DeleteAttachmentsByVehicle is a method that removes every attachment of a vehicle
+	DeleteAttachmentsByVehicle(ctx context.Context, vehicleId int) (err error)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"strings"
+)
+
+// NewBranchDefault is a function that returns a new instance of BranchDefault
+func NewBranchDefault(rpBranch repository.BranchRepository, rpVehicle repository.VehicleRepository) *BranchDefault {
+	return &BranchDefault{rpBranch: rpBranch, rpVehicle: rpVehicle}
+}
+
+// BranchDefault is a struct that represents the default service for branches
+type BranchDefault struct {
+	// rpBranch is the repository of the branches
+	rpBranch repository.BranchRepository
+	// rpVehicle is the repository of the vehicles in stock at the branches
+	rpVehicle repository.VehicleRepository
+}
+
+func (s *BranchDefault) FindAll(ctx context.Context) (b map[int]models.Branch, err error) {
+	b, err = s.rpBranch.FindAll()
+	return
+}
+
+func (s *BranchDefault) AddBranch(ctx context.Context, branchDoc models.BranchDoc) (models.Branch, error) {
+	name := strings.TrimSpace(branchDoc.Name)
+	if name == "" {
+		return models.Branch{}, errors.New("Datos de la sucursal incompletos")
+	}
+
+	branch, err := s.rpBranch.AddBranch(models.Branch{
+		Name:    name,
+		Address: strings.TrimSpace(branchDoc.Address),
+		City:    strings.TrimSpace(branchDoc.City),
+	})
+	if err != nil {
+		return models.Branch{}, err
+	}
+	return branch, nil
+}
+
+func (s *BranchDefault) GetBranchById(ctx context.Context, id int) (models.Branch, error) {
+	branch, err := s.rpBranch.GetBranchById(id)
+	if err != nil {
+		return models.Branch{}, err
+	}
+	return branch, nil
+}
+
+func (s *BranchDefault) FindVehiclesByBranch(ctx context.Context, branchId int) (v map[int]models.Vehicle, err error) {
+	_, err = s.rpBranch.GetBranchById(branchId)
+	if err != nil {
+		return nil, err
+	}
+
+	v = s.rpVehicle.FindVehiclesByBranch(ctx, branchId)
+	if len(v) == 0 {
+		return v, errors.New("No se encontraron vehículos en esa sucursal")
+	}
+	return v, nil
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+)
+
+// BranchService is an interface that represents a branch service
+type BranchService interface {
+	// FindAll is a method that returns a map of all branches
+	FindAll(ctx context.Context) (b map[int]models.Branch, err error)
+	AddBranch(ctx context.Context, branchDoc models.BranchDoc) (models.Branch, error)
+	GetBranchById(ctx context.Context, id int) (models.Branch, error)
+	// FindVehiclesByBranch is a method that returns the vehicles in stock at a branch
+	FindVehiclesByBranch(ctx context.Context, branchId int) (v map[int]models.Vehicle, err error)
+}
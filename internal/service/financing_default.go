@@ -0,0 +1,278 @@
+package service
+
+import (
+	"app/internal/auth"
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxTermMonths is the longest financing term accepted
+const maxTermMonths = 120
+
+// NewFinancingDefault is a function that returns a new instance of FinancingDefault
+func NewFinancingDefault(rpVehicle repository.VehicleRepository, rpQuote repository.QuoteRepository, maxRate decimal.Decimal) *FinancingDefault {
+	return &FinancingDefault{rpVehicle: rpVehicle, rpQuote: rpQuote, maxRate: maxRate}
+}
+
+// FinancingDefault is a struct that represents the default service for financing plans
+type FinancingDefault struct {
+	// rpVehicle is the repository used to get the list price of the vehicles
+	rpVehicle repository.VehicleRepository
+	// rpQuote is the repository where the quotes are saved
+	rpQuote repository.QuoteRepository
+	// maxRate is the highest nominal annual rate accepted, in percent
+	maxRate decimal.Decimal
+}
+
+// Simulate is a method that returns the amortization schedule of a vehicle financing
+func (s *FinancingDefault) Simulate(ctx context.Context, req models.FinancingRequest) (plan models.FinancingPlan, err error) {
+	if req.System == "" {
+		req.System = models.AmortizationFrench
+	}
+	req.System = strings.ToLower(req.System)
+	if req.System != models.AmortizationFrench && req.System != models.AmortizationGerman {
+		return models.FinancingPlan{}, errors.New("Sistema de amortización no admitido")
+	}
+
+	if req.TermMonths <= 0 || req.TermMonths > maxTermMonths ||
+		req.AnnualRate.IsNegative() ||
+		req.DownPayment.IsNegative() ||
+		req.OpeningFee.IsNegative() {
+		return models.FinancingPlan{}, errors.New("Parámetros de financiación mal formados o fuera de rango")
+	}
+	// a typo such as 6000 instead of 60 would give a schedule no one offers
+	if req.AnnualRate.GreaterThan(s.maxRate) {
+		return models.FinancingPlan{}, errors.New("La tasa anual supera la máxima admitida")
+	}
+
+	vehicle, err := s.rpVehicle.GetVehicleById(ctx, req.VehicleId)
+	if err != nil {
+		return models.FinancingPlan{}, err
+	}
+	if !vehicle.Price.IsPositive() {
+		return models.FinancingPlan{}, errors.New("El vehículo no tiene precio de lista")
+	}
+
+	listPrice := vehicle.Price.Round(2)
+	downPayment := req.DownPayment.Round(2)
+	if downPayment.GreaterThanOrEqual(listPrice) {
+		return models.FinancingPlan{}, errors.New("El anticipo debe ser menor al precio del vehículo")
+	}
+	principal := listPrice.Sub(downPayment)
+	openingFee := req.OpeningFee.Round(2)
+	if openingFee.GreaterThanOrEqual(principal) {
+		return models.FinancingPlan{}, errors.New("Parámetros de financiación mal formados o fuera de rango")
+	}
+
+	// monthly rate from the nominal annual rate
+	monthlyRate := req.AnnualRate.Div(decimal.NewFromInt(1200))
+
+	var installments []models.Installment
+	if req.System == models.AmortizationFrench {
+		installments = frenchSchedule(principal, monthlyRate, req.TermMonths)
+	} else {
+		installments = germanSchedule(principal, monthlyRate, req.TermMonths)
+	}
+
+	totalInterest := decimal.Zero
+	totalPaid := decimal.Zero
+	for _, installment := range installments {
+		totalInterest = totalInterest.Add(installment.Interest)
+		totalPaid = totalPaid.Add(installment.Payment)
+	}
+
+	plan = models.FinancingPlan{
+		VehicleId:     vehicle.Id,
+		System:        req.System,
+		ListPrice:     listPrice,
+		DownPayment:   downPayment,
+		Principal:     principal,
+		TermMonths:    req.TermMonths,
+		AnnualRate:    req.AnnualRate,
+		OpeningFee:    openingFee,
+		TotalInterest: totalInterest,
+		TotalPaid:     totalPaid,
+		CFT:           totalFinancialCost(principal.Sub(openingFee), installments),
+		Installments:  installments,
+	}
+	return plan, nil
+}
+
+func (s *FinancingDefault) SaveQuote(ctx context.Context, quoteDoc models.QuoteDoc) (quote models.Quote, err error) {
+	if strings.TrimSpace(quoteDoc.CustomerName) == "" || strings.TrimSpace(quoteDoc.CustomerDocument) == "" {
+		return models.Quote{}, errors.New("Datos del cliente incompletos")
+	}
+
+	plan, err := s.Simulate(ctx, models.FinancingRequest{
+		VehicleId:   quoteDoc.VehicleId,
+		DownPayment: quoteDoc.DownPayment,
+		TermMonths:  quoteDoc.TermMonths,
+		AnnualRate:  quoteDoc.AnnualRate,
+		OpeningFee:  quoteDoc.OpeningFee,
+		System:      quoteDoc.System,
+	})
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	// a vehicle moving between branches can't be offered
+	vehicle, err := s.rpVehicle.GetVehicleById(ctx, plan.VehicleId)
+	if err != nil {
+		return models.Quote{}, err
+	}
+	if vehicle.Status == models.VehicleInTransit {
+		return models.Quote{}, errors.New("El vehículo está en tránsito entre sucursales")
+	}
+
+	quote = models.Quote{
+		CustomerName:     strings.TrimSpace(quoteDoc.CustomerName),
+		CustomerDocument: strings.TrimSpace(quoteDoc.CustomerDocument),
+		CreatedAt:        time.Now(),
+		Plan:             plan,
+	}
+	// the caller is the acting salesperson
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		quote.SalespersonId = p.UserId
+		quote.Salesperson = p.Subject
+	}
+
+	quote, err = s.rpQuote.AddQuote(quote)
+	if err != nil {
+		return models.Quote{}, err
+	}
+	return quote, nil
+}
+
+func (s *FinancingDefault) GetQuoteById(ctx context.Context, id int) (quote models.Quote, err error) {
+	quote, err = s.rpQuote.GetQuoteById(id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+	return quote, nil
+}
+
+func (s *FinancingDefault) FindQuotesByCustomer(ctx context.Context, document string) (q map[int]models.Quote, err error) {
+	q = s.rpQuote.FindQuotesByCustomer(document)
+	if len(q) == 0 {
+		return q, errors.New("No se encontraron cotizaciones con esos criterios")
+	}
+	return q, nil
+}
+
+func (s *FinancingDefault) FindQuotesByVehicle(ctx context.Context, vehicleId int) (q map[int]models.Quote, err error) {
+	q = s.rpQuote.FindQuotesByVehicle(vehicleId)
+	if len(q) == 0 {
+		return q, errors.New("No se encontraron cotizaciones con esos criterios")
+	}
+	return q, nil
+}
+
+// frenchSchedule returns a schedule with constant installments.
+// The last installment absorbs the rounding so the principal reconciles to the cent.
+func frenchSchedule(principal decimal.Decimal, rate decimal.Decimal, term int) []models.Installment {
+	n := decimal.NewFromInt(int64(term))
+
+	var payment decimal.Decimal
+	if rate.IsZero() {
+		payment = principal.Div(n).Round(2)
+	} else {
+		factor := rate.Add(decimal.NewFromInt(1)).Pow(n)
+		payment = principal.Mul(rate).Mul(factor).Div(factor.Sub(decimal.NewFromInt(1))).Round(2)
+	}
+
+	installments := make([]models.Installment, 0, term)
+	balance := principal
+	for number := 1; number <= term; number++ {
+		interest := balance.Mul(rate).Round(2)
+		amortization := payment.Sub(interest)
+		if number == term || amortization.GreaterThan(balance) {
+			amortization = balance
+		}
+		balance = balance.Sub(amortization)
+
+		installments = append(installments, models.Installment{
+			Number:    number,
+			Payment:   amortization.Add(interest),
+			Interest:  interest,
+			Principal: amortization,
+			Balance:   balance,
+		})
+	}
+	return installments
+}
+
+// germanSchedule returns a schedule with constant principal payments.
+// The last installment absorbs the rounding so the principal reconciles to the cent.
+func germanSchedule(principal decimal.Decimal, rate decimal.Decimal, term int) []models.Installment {
+	amortization := principal.Div(decimal.NewFromInt(int64(term))).Round(2)
+
+	installments := make([]models.Installment, 0, term)
+	balance := principal
+	for number := 1; number <= term; number++ {
+		interest := balance.Mul(rate).Round(2)
+		current := amortization
+		if number == term || current.GreaterThan(balance) {
+			current = balance
+		}
+		balance = balance.Sub(current)
+
+		installments = append(installments, models.Installment{
+			Number:    number,
+			Payment:   current.Add(interest),
+			Interest:  interest,
+			Principal: current,
+			Balance:   balance,
+		})
+	}
+	return installments
+}
+
+// totalFinancialCost returns the CFT as an effective annual rate percentage.
+// It finds the monthly rate that discounts the installments to the amount
+// actually received by the customer (net of fees) and compounds it yearly.
+func totalFinancialCost(received decimal.Decimal, installments []models.Installment) decimal.Decimal {
+	net := received.InexactFloat64()
+	payments := make([]float64, len(installments))
+	for i, installment := range installments {
+		payments[i] = installment.Payment.InexactFloat64()
+	}
+
+	presentValue := func(rate float64) float64 {
+		pv := 0.0
+		for i, payment := range payments {
+			pv += payment / math.Pow(1+rate, float64(i+1))
+		}
+		return pv
+	}
+
+	// the present value decreases with the rate, so bisect until it matches
+	low, high := 0.0, 1.0
+	if presentValue(low) <= net {
+		return decimal.Zero
+	}
+	for presentValue(high) > net {
+		high *= 2
+	}
+	for i := 0; i < 200; i++ {
+		middle := (low + high) / 2
+		if presentValue(middle) > net {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	monthly := (low + high) / 2
+	return decimal.NewFromFloat((math.Pow(1+monthly, 12) - 1) * 100).Round(2)
+}
+
+func (s *FinancingDefault) DeleteQuotesByVehicle(ctx context.Context, vehicleId int) (err error) {
+	return s.rpQuote.DeleteQuotesByVehicle(vehicleId)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// newFinancingTest returns a service with a vehicle of each price by id, up to a rate of 200%
+func newFinancingTest(prices map[int]string) *FinancingDefault {
+	db := make(map[int]models.Vehicle)
+	for id, price := range prices {
+		db[id] = models.Vehicle{Id: id, VehicleAttributes: models.VehicleAttributes{Price: decimal.RequireFromString(price), Status: models.VehicleAvailable}}
+	}
+	return NewFinancingDefault(repository.NewVehicleMap(db), repository.NewQuoteMap(nil), decimal.NewFromInt(200))
+}
+
+// TestSimulateSchedule checks the installments of each system, the totals and the CFT
+func TestSimulateSchedule(t *testing.T) {
+	sv := newFinancingTest(map[int]string{1: "10000", 2: "12000", 3: "1000"})
+
+	tests := []struct {
+		name string
+		req  models.FinancingRequest
+		// first and last are the payments of the first and last installments
+		first, last   string
+		totalInterest string
+		totalPaid     string
+		cft           string
+	}{
+		{
+			name:  "french, constant installments and the last one absorbs the rounding",
+			req:   models.FinancingRequest{VehicleId: 1, TermMonths: 12, AnnualRate: decimal.NewFromInt(12), System: models.AmortizationFrench},
+			first: "888.49", last: "888.47", totalInterest: "661.86", totalPaid: "10661.86", cft: "12.68",
+		},
+		{
+			name:  "french, the opening fee raises the CFT",
+			req:   models.FinancingRequest{VehicleId: 1, TermMonths: 12, AnnualRate: decimal.NewFromInt(12), OpeningFee: decimal.NewFromInt(200)},
+			first: "888.49", last: "888.47", totalInterest: "661.86", totalPaid: "10661.86", cft: "17.06",
+		},
+		{
+			name:  "french without interest",
+			req:   models.FinancingRequest{VehicleId: 3, TermMonths: 3, System: models.AmortizationFrench},
+			first: "333.33", last: "333.34", totalInterest: "0", totalPaid: "1000", cft: "0",
+		},
+		{
+			name:  "german, constant principal",
+			req:   models.FinancingRequest{VehicleId: 2, TermMonths: 12, AnnualRate: decimal.NewFromInt(12), System: models.AmortizationGerman},
+			first: "1120", last: "1010", totalInterest: "780", totalPaid: "12780", cft: "12.68",
+		},
+		{
+			name:  "the down payment is not financed",
+			req:   models.FinancingRequest{VehicleId: 2, DownPayment: decimal.NewFromInt(2000), TermMonths: 10, System: models.AmortizationGerman},
+			first: "1000", last: "1000", totalInterest: "0", totalPaid: "10000", cft: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := sv.Simulate(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("simulate: %v", err)
+			}
+			if len(plan.Installments) != tt.req.TermMonths {
+				t.Fatalf("installments: got %d, want %d", len(plan.Installments), tt.req.TermMonths)
+			}
+			first, last := plan.Installments[0], plan.Installments[len(plan.Installments)-1]
+			checks := []struct {
+				field     string
+				got, want decimal.Decimal
+			}{
+				{"first payment", first.Payment, decimal.RequireFromString(tt.first)},
+				{"last payment", last.Payment, decimal.RequireFromString(tt.last)},
+				{"last balance", last.Balance, decimal.Zero},
+				{"total interest", plan.TotalInterest, decimal.RequireFromString(tt.totalInterest)},
+				{"total paid", plan.TotalPaid, decimal.RequireFromString(tt.totalPaid)},
+				{"cft", plan.CFT, decimal.RequireFromString(tt.cft)},
+			}
+			for _, c := range checks {
+				if !c.got.Equal(c.want) {
+					t.Errorf("%s: got %s, want %s", c.field, c.got, c.want)
+				}
+			}
+
+			// the principal of the installments reconciles to the cent
+			principal := decimal.Zero
+			for _, installment := range plan.Installments {
+				principal = principal.Add(installment.Principal)
+			}
+			if !principal.Equal(plan.Principal) {
+				t.Errorf("principal of the installments: got %s, want %s", principal, plan.Principal)
+			}
+		})
+	}
+}
+
+// TestSimulateRejected checks the requests that can't be simulated
+func TestSimulateRejected(t *testing.T) {
+	sv := newFinancingTest(map[int]string{1: "10000", 2: "0"})
+
+	tests := []struct {
+		name string
+		req  models.FinancingRequest
+		want string
+	}{
+		{"unknown system", models.FinancingRequest{VehicleId: 1, TermMonths: 12, System: "american"}, "Sistema de amortización no admitido"},
+		{"no term", models.FinancingRequest{VehicleId: 1}, "Parámetros de financiación mal formados o fuera de rango"},
+		{"term too long", models.FinancingRequest{VehicleId: 1, TermMonths: maxTermMonths + 1}, "Parámetros de financiación mal formados o fuera de rango"},
+		{"negative rate", models.FinancingRequest{VehicleId: 1, TermMonths: 12, AnnualRate: decimal.NewFromInt(-1)}, "Parámetros de financiación mal formados o fuera de rango"},
+		{"rate over the maximum", models.FinancingRequest{VehicleId: 1, TermMonths: 12, AnnualRate: decimal.NewFromInt(6000)}, "La tasa anual supera la máxima admitida"},
+		{"unknown vehicle", models.FinancingRequest{VehicleId: 9, TermMonths: 12}, "Vehicle not found"},
+		{"vehicle without price", models.FinancingRequest{VehicleId: 2, TermMonths: 12}, "El vehículo no tiene precio de lista"},
+		{"down payment of the whole price", models.FinancingRequest{VehicleId: 1, TermMonths: 12, DownPayment: decimal.NewFromInt(10000)}, "El anticipo debe ser menor al precio del vehículo"},
+		{"opening fee of the whole principal", models.FinancingRequest{VehicleId: 1, TermMonths: 12, OpeningFee: decimal.NewFromInt(10000)}, "Parámetros de financiación mal formados o fuera de rango"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sv.Simulate(context.Background(), tt.req)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+)
+
+// FinancingService is an interface that represents a financing service
+type FinancingService interface {
+	// Simulate is a method that returns the financing plan of a vehicle
+	Simulate(ctx context.Context, req models.FinancingRequest) (plan models.FinancingPlan, err error)
+	// SaveQuote is a method that simulates a plan and saves it for a customer
+	SaveQuote(ctx context.Context, quoteDoc models.QuoteDoc) (quote models.Quote, err error)
+	GetQuoteById(ctx context.Context, id int) (quote models.Quote, err error)
+	FindQuotesByCustomer(ctx context.Context, document string) (q map[int]models.Quote, err error)
+	FindQuotesByVehicle(ctx context.Context, vehicleId int) (q map[int]models.Quote, err error)
+	// DeleteQuotesByVehicle is a method that removes the quotes of a vehicle
+	DeleteQuotesByVehicle(ctx context.Context, vehicleId int) (err error)
+}
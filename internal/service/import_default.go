@@ -0,0 +1,170 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewImportDefault is a function that returns a new instance of ImportDefault
+func NewImportDefault(sv VehicleService, rp repository.ImportJobRepository) *ImportDefault {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ImportDefault{sv: sv, rp: rp, ctx: ctx, cancel: cancel}
+}
+
+// ImportDefault is a struct that represents the default service for imports, the vehicles are
+// added through the same path as a batch
+type ImportDefault struct {
+	// sv is the service of the vehicles imported
+	sv VehicleService
+	// rp is the repository of the jobs
+	rp repository.ImportJobRepository
+	// ctx is done when the jobs in the background must stop
+	ctx context.Context
+	// cancel stops the jobs in the background
+	cancel context.CancelFunc
+	// wg waits for the jobs in the background to return
+	wg sync.WaitGroup
+}
+
+// Run is a method that blocks until ctx is done, then stops the jobs in the background and waits for them
+func (s *ImportDefault) Run(ctx context.Context) {
+	<-ctx.Done()
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *ImportDefault) Validate(ctx context.Context, file models.ImportFile) models.ImportReport {
+	report := models.ImportReport{
+		Rows:           len(file.Rows),
+		IgnoredColumns: file.IgnoredColumns,
+		Errors:         []models.ImportRowError{},
+	}
+
+	// line where each identifier appears first
+	lines := make(map[int]int)
+	for _, row := range file.Rows {
+		errs := append([]string(nil), row.Errors...)
+
+		// the fields that could not be read are already reported
+		var missing []string
+		for _, field := range mapDocToVehicle(row.Vehicle).MissingFields() {
+			if !hasFieldError(row.Errors, field) {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			errs = append(errs, "Campos obligatorios faltantes: "+strings.Join(missing, ", "))
+		}
+		if err := s.sv.CheckNewVehicle(ctx, row.Vehicle); err != nil {
+			errs = append(errs, err.Error())
+		}
+
+		if id := row.Vehicle.ID; id != 0 {
+			if line, ok := lines[id]; ok {
+				errs = append(errs, "Identificador repetido en la línea "+strconv.Itoa(line))
+			} else {
+				lines[id] = row.Line
+				if _, err := s.sv.GetVehicleById(ctx, id); err == nil {
+					errs = append(errs, "Identificador del vehículo ya existente")
+				}
+			}
+		}
+
+		if len(errs) > 0 {
+			report.Invalid++
+			report.Errors = append(report.Errors, models.ImportRowError{Line: row.Line, Id: row.Vehicle.ID, Errors: errs})
+			continue
+		}
+		report.Valid++
+	}
+	return report
+}
+
+// hasFieldError tells if one of the errors of a row belongs to the field
+func hasFieldError(errs []string, field string) bool {
+	for _, err := range errs {
+		if strings.HasPrefix(err, field+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ImportDefault) Import(ctx context.Context, file models.ImportFile, async bool) (models.ImportJob, error) {
+	report := s.Validate(ctx, file)
+	if report.Rows == 0 {
+		return models.ImportJob{Report: report}, errors.New("El archivo no tiene vehículos")
+	}
+	if report.Invalid > 0 {
+		return models.ImportJob{Report: report}, errors.New("Algunas filas del archivo no son válidas")
+	}
+
+	job, err := s.rp.AddJob(models.ImportJob{
+		Status:    models.ImportPending,
+		Report:    report,
+		Total:     len(file.Rows),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return models.ImportJob{}, err
+	}
+
+	if !async {
+		return s.run(ctx, job, file.Rows), nil
+	}
+	// the job outlives the request but keeps its values, e.g. the actor of the mutation logs
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(s.ctx, cancel)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer stop()
+		defer cancel()
+		s.run(jobCtx, job, file.Rows)
+	}()
+	return job, nil
+}
+
+// run adds the vehicles of the rows and returns the job finished. They are added a vehicle at a time
+// so the progress and the row that fails are exact: a job that fails or is interrupted keeps the
+// vehicles of the rows up to its last line, and its error names the first row not added.
+func (s *ImportDefault) run(ctx context.Context, job models.ImportJob, rows []models.ImportRow) models.ImportJob {
+	started := time.Now()
+	job.Status, job.StartedAt = models.ImportRunning, &started
+	s.rp.UpdateJob(job)
+
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return s.finish(job, fmt.Errorf("Importación interrumpida en la línea %d", row.Line))
+		}
+		if err := s.sv.AddMultipleVehicles(ctx, []models.VehicleDoc{row.Vehicle}); err != nil {
+			return s.finish(job, fmt.Errorf("línea %d: %w", row.Line, err))
+		}
+		job.Created++
+		job.LastLine = row.Line
+		s.rp.UpdateJob(job)
+	}
+	return s.finish(job, nil)
+}
+
+// finish marks the job as failed with err, or succeeded when it is nil
+func (s *ImportDefault) finish(job models.ImportJob, err error) models.ImportJob {
+	finished := time.Now()
+	job.Status, job.FinishedAt = models.ImportSucceeded, &finished
+	if err != nil {
+		job.Status, job.Error = models.ImportFailed, err.Error()
+	}
+	s.rp.UpdateJob(job)
+	return job
+}
+
+func (s *ImportDefault) GetJobById(ctx context.Context, id int) (models.ImportJob, error) {
+	return s.rp.GetJobById(id)
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+)
+
+// ImportService is an interface that represents the bulk import of vehicles from files
+type ImportService interface {
+	// Validate is a method that reports the rows AddMultipleVehicles would reject: values that could not be
+	// read, mandatory fields missing and identifiers repeated in the file or already in use
+	Validate(ctx context.Context, file models.ImportFile) models.ImportReport
+	// Import is a method that validates the file and, when every row is valid, adds its vehicles in a job.
+	// With async the job is returned pending and runs in the background.
+	Import(ctx context.Context, file models.ImportFile, async bool) (models.ImportJob, error)
+	GetJobById(ctx context.Context, id int) (models.ImportJob, error)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// NewMaintenanceDefault is a function that returns a new instance of MaintenanceDefault
+func NewMaintenanceDefault(rpVehicle repository.VehicleRepository, rpMaintenance repository.MaintenanceRepository, svOdometer OdometerService) *MaintenanceDefault {
+	return &MaintenanceDefault{rpVehicle: rpVehicle, rpMaintenance: rpMaintenance, svOdometer: svOdometer}
+}
+
+// MaintenanceDefault is a struct that represents the default service for maintenance records
+type MaintenanceDefault struct {
+	// rpVehicle is the repository of the serviced vehicles
+	rpVehicle repository.VehicleRepository
+	// rpMaintenance is the repository where records and schedules are kept
+	rpMaintenance repository.MaintenanceRepository
+	// svOdometer is the service used to record the odometer of each intervention
+	svOdometer OdometerService
+}
+
+func (s *MaintenanceDefault) AddRecord(ctx context.Context, vehicleId int, recordDoc models.MaintenanceRecordDoc) (record models.MaintenanceRecord, err error) {
+	recordType := strings.ToLower(strings.TrimSpace(recordDoc.Type))
+	if recordType == "" || recordDoc.LaborCost.IsNegative() || recordDoc.Odometer < 0 {
+		return models.MaintenanceRecord{}, errors.New("Registro de mantenimiento mal formado o incompleto")
+	}
+	parts := make([]models.MaintenancePart, 0, len(recordDoc.Parts))
+	for _, part := range recordDoc.Parts {
+		if strings.TrimSpace(part.Name) == "" || part.Quantity <= 0 || part.UnitCost.IsNegative() {
+			return models.MaintenanceRecord{}, errors.New("Registro de mantenimiento mal formado o incompleto")
+		}
+		// costs are kept in cents so the totals reconcile
+		part.Name = strings.TrimSpace(part.Name)
+		part.UnitCost = part.UnitCost.Round(2)
+		parts = append(parts, part)
+	}
+
+	date := recordDoc.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	_, err = s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return models.MaintenanceRecord{}, err
+	}
+
+	save := func() (err error) {
+		record, err = s.rpMaintenance.AddRecord(models.MaintenanceRecord{
+			VehicleId:  vehicleId,
+			Date:       date,
+			Type:       recordType,
+			Parts:      parts,
+			LaborCost:  recordDoc.LaborCost.Round(2),
+			Odometer:   recordDoc.Odometer,
+			Notes:      recordDoc.Notes,
+			RecordedBy: actor(ctx),
+		})
+		return err
+	}
+
+	if recordDoc.Odometer == 0 {
+		if err = save(); err != nil {
+			return models.MaintenanceRecord{}, err
+		}
+		return record, nil
+	}
+
+	// the odometer seen at the workshop goes through the odometer validation, the record is only
+	// saved once the reading is accepted
+	_, err = s.svOdometer.AddReadingWith(ctx, vehicleId, models.OdometerReadingDoc{
+		Value:  recordDoc.Odometer,
+		Date:   date,
+		Source: "maintenance",
+	}, save)
+	if err != nil {
+		// the reading can only fail to be recorded after the record was saved if the vehicle is gone
+		if record.Id != 0 {
+			_ = s.rpMaintenance.DeleteRecord(record.Id)
+		}
+		return models.MaintenanceRecord{}, err
+	}
+	return record, nil
+}
+
+func (s *MaintenanceDefault) FindRecordsByVehicle(ctx context.Context, vehicleId int) (m []models.MaintenanceRecord, err error) {
+	_, err = s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	m = s.rpMaintenance.FindRecordsByVehicle(vehicleId)
+	if len(m) == 0 {
+		return m, errors.New("El vehículo no tiene registros de mantenimiento")
+	}
+	return m, nil
+}
+
+func (s *MaintenanceDefault) GetReconditioningCost(ctx context.Context, vehicleId int) (cost models.ReconditioningCost, err error) {
+	vehicle, err := s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return models.ReconditioningCost{}, err
+	}
+
+	cost = models.ReconditioningCost{
+		VehicleId: vehicleId,
+		PartsCost: decimal.Zero,
+		LaborCost: decimal.Zero,
+		ListPrice: vehicle.Price.Round(2),
+	}
+	for _, record := range s.rpMaintenance.FindRecordsByVehicle(vehicleId) {
+		cost.Records++
+		cost.PartsCost = cost.PartsCost.Add(record.PartsCost())
+		cost.LaborCost = cost.LaborCost.Add(record.LaborCost)
+	}
+	cost.TotalCost = cost.PartsCost.Add(cost.LaborCost)
+	cost.NetOfCost = cost.ListPrice.Sub(cost.TotalCost)
+	return cost, nil
+}
+
+func (s *MaintenanceDefault) AddSchedule(ctx context.Context, vehicleId int, scheduleDoc models.MaintenanceScheduleDoc) (schedule models.MaintenanceSchedule, err error) {
+	scheduleType := strings.ToLower(strings.TrimSpace(scheduleDoc.Type))
+	if scheduleType == "" ||
+		scheduleDoc.IntervalDays < 0 ||
+		scheduleDoc.IntervalKm < 0 ||
+		(scheduleDoc.IntervalDays == 0 && scheduleDoc.IntervalKm == 0) {
+		return models.MaintenanceSchedule{}, errors.New("Programación de mantenimiento mal formada o sin intervalo")
+	}
+
+	vehicle, err := s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return models.MaintenanceSchedule{}, err
+	}
+
+	schedule, err = s.rpMaintenance.AddSchedule(models.MaintenanceSchedule{
+		VehicleId:     vehicleId,
+		Type:          scheduleType,
+		IntervalDays:  scheduleDoc.IntervalDays,
+		IntervalKm:    scheduleDoc.IntervalKm,
+		StartDate:     time.Now(),
+		StartOdometer: vehicle.Mileage,
+	})
+	if err != nil {
+		return models.MaintenanceSchedule{}, err
+	}
+	return schedule, nil
+}
+
+func (s *MaintenanceDefault) FindSchedulesByVehicle(ctx context.Context, vehicleId int) (m map[int]models.MaintenanceSchedule, err error) {
+	_, err = s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	m = s.rpMaintenance.FindSchedulesByVehicle(vehicleId)
+	if len(m) == 0 {
+		return m, errors.New("El vehículo no tiene mantenimientos programados")
+	}
+	return m, nil
+}
+
+// FindOverdue checks every schedule against the last record of the same type
+// (or the schedule start when there is none) and returns the ones due by days or km
+func (s *MaintenanceDefault) FindOverdue(ctx context.Context) (o []models.OverdueMaintenance, err error) {
+	now := time.Now()
+
+	for _, schedule := range s.rpMaintenance.FindSchedules() {
+		vehicle, err := s.rpVehicle.GetVehicleById(ctx, schedule.VehicleId)
+		if err != nil {
+			// the vehicle is no longer in stock
+			continue
+		}
+
+		lastDate := schedule.StartDate
+		lastOdometer := schedule.StartOdometer
+		for _, record := range s.rpMaintenance.FindRecordsByVehicle(schedule.VehicleId) {
+			if record.Type != schedule.Type || record.Date.Before(lastDate) {
+				continue
+			}
+			lastDate = record.Date
+			if record.Odometer > 0 {
+				lastOdometer = record.Odometer
+			}
+		}
+
+		overdue := models.OverdueMaintenance{
+			ScheduleId:     schedule.Id,
+			VehicleId:      schedule.VehicleId,
+			Type:           schedule.Type,
+			LastDate:       lastDate,
+			LastOdometer:   lastOdometer,
+			CurrentMileage: vehicle.Mileage,
+		}
+		isOverdue := false
+		if schedule.IntervalDays > 0 {
+			dueDate := lastDate.AddDate(0, 0, schedule.IntervalDays)
+			overdue.DueDate = &dueDate
+			if !now.Before(dueDate) {
+				isOverdue = true
+				overdue.OverdueDays = int(now.Sub(dueDate).Hours() / 24)
+			}
+		}
+		if schedule.IntervalKm > 0 {
+			overdue.DueOdometer = lastOdometer + schedule.IntervalKm
+			if vehicle.Mileage >= overdue.DueOdometer {
+				isOverdue = true
+				overdue.OverdueKm = vehicle.Mileage - overdue.DueOdometer
+			}
+		}
+
+		if isOverdue {
+			o = append(o, overdue)
+		}
+	}
+
+	if len(o) == 0 {
+		return o, errors.New("No hay vehículos con mantenimiento vencido")
+	}
+
+	sort.Slice(o, func(i, j int) bool {
+		return o[i].ScheduleId < o[j].ScheduleId
+	})
+	return o, nil
+}
+
+func (s *MaintenanceDefault) DeleteMaintenanceByVehicle(ctx context.Context, vehicleId int) (err error) {
+	return s.rpMaintenance.DeleteByVehicle(vehicleId)
+}
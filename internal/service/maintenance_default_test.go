@@ -0,0 +1,122 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// odometerLost is an odometer service that accepts a reading and loses the vehicle before recording it
+type odometerLost struct {
+	OdometerService
+}
+
+func (s odometerLost) AddReadingWith(ctx context.Context, vehicleId int, readingDoc models.OdometerReadingDoc, save func() error) (models.OdometerReading, error) {
+	if err := save(); err != nil {
+		return models.OdometerReading{}, err
+	}
+	return models.OdometerReading{}, errors.New("Vehicle not found")
+}
+
+// TestAddRecord checks a record is only kept when the odometer seen at the workshop is accepted
+func TestAddRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		// lost makes the vehicle disappear once the reading is accepted
+		lost bool
+		// previous is the odometer of a record added before
+		previous int
+		doc      models.MaintenanceRecordDoc
+		want     string
+		// wantRecords are the records of the vehicle afterwards
+		wantRecords int
+		wantMileage int
+	}{
+		{
+			name:        "without odometer",
+			doc:         models.MaintenanceRecordDoc{Type: "service"},
+			wantRecords: 1,
+		},
+		{
+			name:        "odometer accepted",
+			doc:         models.MaintenanceRecordDoc{Type: "service", Odometer: 15000},
+			wantRecords: 1, wantMileage: 15000,
+		},
+		{
+			name:     "odometer lower than the last reading",
+			previous: 15000,
+			doc:      models.MaintenanceRecordDoc{Type: "service", Odometer: 12000},
+			want:     "La lectura del odómetro no puede ser menor a la anterior", wantRecords: 1, wantMileage: 15000,
+		},
+		{
+			name: "vehicle lost after the record is saved",
+			lost: true,
+			doc:  models.MaintenanceRecordDoc{Type: "service", Odometer: 15000},
+			want: "Vehicle not found",
+		},
+		{
+			name: "without type",
+			doc:  models.MaintenanceRecordDoc{Odometer: 15000},
+			want: "Registro de mantenimiento mal formado o incompleto",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			rpVehicle := repository.NewVehicleMap(map[int]models.Vehicle{1: {Id: 1}})
+			rpMaintenance := repository.NewMaintenanceMap()
+			var svOdometer OdometerService = NewOdometerDefault(rpVehicle, repository.NewOdometerMap(nil))
+			if tt.lost {
+				svOdometer = odometerLost{svOdometer}
+			}
+			sv := NewMaintenanceDefault(rpVehicle, rpMaintenance, svOdometer)
+			if tt.previous != 0 {
+				if _, err := sv.AddRecord(ctx, 1, models.MaintenanceRecordDoc{Type: "service", Odometer: tt.previous, Date: time.Now().Add(-time.Hour)}); err != nil {
+					t.Fatalf("previous record: %v", err)
+				}
+			}
+
+			_, err := sv.AddRecord(ctx, 1, tt.doc)
+			if tt.want != "" {
+				if err == nil || err.Error() != tt.want {
+					t.Errorf("got %v, want %q", err, tt.want)
+				}
+			} else if err != nil {
+				t.Fatalf("add record: %v", err)
+			}
+
+			if records := rpMaintenance.FindRecordsByVehicle(1); len(records) != tt.wantRecords {
+				t.Errorf("records: got %d, want %d", len(records), tt.wantRecords)
+			}
+			vehicle, _ := rpVehicle.GetVehicleById(ctx, 1)
+			if vehicle.Mileage != tt.wantMileage {
+				t.Errorf("mileage: got %d, want %d", vehicle.Mileage, tt.wantMileage)
+			}
+		})
+	}
+}
+
+// TestAddRecordRejectedId checks a rejected record doesn't take an id
+func TestAddRecordRejectedId(t *testing.T) {
+	ctx := context.Background()
+	rpVehicle := repository.NewVehicleMap(map[int]models.Vehicle{1: {Id: 1}})
+	sv := NewMaintenanceDefault(rpVehicle, repository.NewMaintenanceMap(), NewOdometerDefault(rpVehicle, repository.NewOdometerMap(nil)))
+
+	docs := []models.MaintenanceRecordDoc{
+		{Type: "service", Odometer: 15000},
+		{Type: "service", Odometer: 100},
+		{Type: "service", Odometer: 16000},
+	}
+	ids := make([]int, 0, len(docs))
+	for _, doc := range docs {
+		if record, err := sv.AddRecord(ctx, 1, doc); err == nil {
+			ids = append(ids, record.Id)
+		}
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("ids: got %v, want [1 2]", ids)
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+)
+
+// MaintenanceService is an interface that represents a maintenance service
+type MaintenanceService interface {
+	// AddRecord is a method that records a workshop intervention on a vehicle
+	AddRecord(ctx context.Context, vehicleId int, recordDoc models.MaintenanceRecordDoc) (record models.MaintenanceRecord, err error)
+	FindRecordsByVehicle(ctx context.Context, vehicleId int) (m []models.MaintenanceRecord, err error)
+	// GetReconditioningCost is a method that returns the total spent on a vehicle
+	GetReconditioningCost(ctx context.Context, vehicleId int) (cost models.ReconditioningCost, err error)
+	// AddSchedule is a method that schedules a recurring maintenance by days and/or kilometers
+	AddSchedule(ctx context.Context, vehicleId int, scheduleDoc models.MaintenanceScheduleDoc) (schedule models.MaintenanceSchedule, err error)
+	FindSchedulesByVehicle(ctx context.Context, vehicleId int) (m map[int]models.MaintenanceSchedule, err error)
+	// FindOverdue is a method that returns the schedules whose interval has elapsed
+	FindOverdue(ctx context.Context) (o []models.OverdueMaintenance, err error)
+	// DeleteMaintenanceByVehicle is a method that removes the records and schedules of a vehicle
+	DeleteMaintenanceByVehicle(ctx context.Context, vehicleId int) (err error)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewOdometerDefault is a function that returns a new instance of OdometerDefault
+func NewOdometerDefault(rpVehicle repository.VehicleRepository, rpOdometer repository.OdometerRepository) *OdometerDefault {
+	return &OdometerDefault{rpVehicle: rpVehicle, rpOdometer: rpOdometer}
+}
+
+// OdometerDefault is a struct that represents the default service for odometer readings
+type OdometerDefault struct {
+	// mu serializes the readings so each one is checked against the last one saved
+	// and the mileage is updated in the order they were accepted
+	mu sync.Mutex
+	// rpVehicle is the repository where the current mileage is kept
+	rpVehicle repository.VehicleRepository
+	// rpOdometer is the repository where the reading history is kept
+	rpOdometer repository.OdometerRepository
+}
+
+// AddReading records a reading. Readings are appended in chronological order and
+// can never go backwards unless it is an explicit override with a reason
+// (e.g. the odometer was replaced), in which case the previous value is kept for audit.
+func (s *OdometerDefault) AddReading(ctx context.Context, vehicleId int, readingDoc models.OdometerReadingDoc) (reading models.OdometerReading, err error) {
+	return s.AddReadingWith(ctx, vehicleId, readingDoc, nil)
+}
+
+// AddReadingWith records a reading as AddReading, running save while the reading is checked against the
+// last one, so whatever save writes is only kept along with an accepted reading
+func (s *OdometerDefault) AddReadingWith(ctx context.Context, vehicleId int, readingDoc models.OdometerReadingDoc, save func() error) (reading models.OdometerReading, err error) {
+	source := strings.TrimSpace(readingDoc.Source)
+	if readingDoc.Value < 0 || source == "" {
+		return models.OdometerReading{}, errors.New("Lectura de odómetro mal formada o incompleta")
+	}
+
+	date := readingDoc.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	if date.After(time.Now()) {
+		return models.OdometerReading{}, errors.New("La fecha de la lectura no puede ser futura")
+	}
+
+	overrideReason := strings.TrimSpace(readingDoc.OverrideReason)
+	if readingDoc.Override && overrideReason == "" {
+		return models.OdometerReading{}, errors.New("El reemplazo de odómetro requiere un motivo")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vehicle, err := s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return models.OdometerReading{}, err
+	}
+
+	// the last known value is the last reading, or the mileage the vehicle was registered with
+	lastValue := vehicle.Mileage
+	readings := s.rpOdometer.FindReadingsByVehicle(vehicleId)
+	if len(readings) > 0 {
+		last := readings[len(readings)-1]
+		if date.Before(last.Date) {
+			return models.OdometerReading{}, errors.New("La fecha de la lectura es anterior a la última registrada")
+		}
+		lastValue = last.Value
+	}
+
+	if readingDoc.Value < lastValue && !readingDoc.Override {
+		return models.OdometerReading{}, errors.New("La lectura del odómetro no puede ser menor a la anterior")
+	}
+
+	reading = models.OdometerReading{
+		VehicleId:  vehicleId,
+		Value:      readingDoc.Value,
+		Date:       date,
+		Source:     source,
+		RecordedBy: actor(ctx),
+	}
+	if readingDoc.Override {
+		reading.Override = true
+		reading.OverrideReason = overrideReason
+		reading.PreviousValue = lastValue
+	}
+
+	if save != nil {
+		if err = save(); err != nil {
+			return models.OdometerReading{}, err
+		}
+	}
+
+	reading, err = s.rpOdometer.AddReading(reading)
+	if err != nil {
+		return models.OdometerReading{}, err
+	}
+
+	err = s.rpVehicle.UpdateMileage(ctx, vehicleId, reading.Value)
+	if err != nil {
+		return models.OdometerReading{}, err
+	}
+
+	return reading, nil
+}
+
+func (s *OdometerDefault) FindReadingsByVehicle(ctx context.Context, vehicleId int) (r []models.OdometerReading, err error) {
+	_, err = s.rpVehicle.GetVehicleById(ctx, vehicleId)
+	if err != nil {
+		return nil, err
+	}
+
+	r = s.rpOdometer.FindReadingsByVehicle(vehicleId)
+	if len(r) == 0 {
+		return r, errors.New("El vehículo no tiene lecturas de odómetro")
+	}
+	return r, nil
+}
+
+// DeleteReadingsByVehicle removes the history of a vehicle, a vehicle added again with its id starts
+// from the mileage it is registered with
+func (s *OdometerDefault) DeleteReadingsByVehicle(ctx context.Context, vehicleId int) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rpOdometer.DeleteReadingsByVehicle(vehicleId)
+}
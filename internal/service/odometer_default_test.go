@@ -0,0 +1,128 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestAddReading checks a reading against the last one of the vehicle, one at a time in order
+func TestAddReading(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		// mileage is the one the vehicle is registered with
+		mileage int
+		// previous are the readings accepted before
+		previous []models.OdometerReadingDoc
+		doc      models.OdometerReadingDoc
+		want     string
+		// wantMileage is the mileage of the vehicle afterwards
+		wantMileage int
+	}{
+		{
+			name:    "first reading over the mileage registered",
+			mileage: 1000, doc: models.OdometerReadingDoc{Value: 1500, Source: "sales"},
+			wantMileage: 1500,
+		},
+		{
+			name:    "first reading under the mileage registered",
+			mileage: 1000, doc: models.OdometerReadingDoc{Value: 500, Source: "sales"},
+			want: "La lectura del odómetro no puede ser menor a la anterior", wantMileage: 1000,
+		},
+		{
+			name:        "same value as the last reading",
+			previous:    []models.OdometerReadingDoc{{Value: 2000, Source: "sales"}},
+			doc:         models.OdometerReadingDoc{Value: 2000, Source: "sales"},
+			wantMileage: 2000,
+		},
+		{
+			name:     "lower than the last reading",
+			previous: []models.OdometerReadingDoc{{Value: 2000, Source: "sales"}},
+			doc:      models.OdometerReadingDoc{Value: 1999, Source: "sales"},
+			want:     "La lectura del odómetro no puede ser menor a la anterior", wantMileage: 2000,
+		},
+		{
+			name:        "override of a replaced odometer",
+			previous:    []models.OdometerReadingDoc{{Value: 2000, Source: "sales"}},
+			doc:         models.OdometerReadingDoc{Value: 10, Source: "workshop", Override: true, OverrideReason: "odometer replaced"},
+			wantMileage: 10,
+		},
+		{
+			name:     "override without a reason",
+			previous: []models.OdometerReadingDoc{{Value: 2000, Source: "sales"}},
+			doc:      models.OdometerReadingDoc{Value: 10, Source: "workshop", Override: true},
+			want:     "El reemplazo de odómetro requiere un motivo", wantMileage: 2000,
+		},
+		{
+			name:     "dated before the last reading",
+			previous: []models.OdometerReadingDoc{{Value: 2000, Source: "sales", Date: now}},
+			doc:      models.OdometerReadingDoc{Value: 3000, Source: "sales", Date: earlier},
+			want:     "La fecha de la lectura es anterior a la última registrada", wantMileage: 2000,
+		},
+		{
+			name: "dated in the future",
+			doc:  models.OdometerReadingDoc{Value: 3000, Source: "sales", Date: now.Add(time.Hour)},
+			want: "La fecha de la lectura no puede ser futura",
+		},
+		{
+			name: "without source",
+			doc:  models.OdometerReadingDoc{Value: 3000},
+			want: "Lectura de odómetro mal formada o incompleta",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpVehicle := repository.NewVehicleMap(map[int]models.Vehicle{
+				1: {Id: 1, VehicleAttributes: models.VehicleAttributes{Mileage: tt.mileage}},
+			})
+			sv := NewOdometerDefault(rpVehicle, repository.NewOdometerMap(nil))
+			for _, doc := range tt.previous {
+				if _, err := sv.AddReading(context.Background(), 1, doc); err != nil {
+					t.Fatalf("previous reading: %v", err)
+				}
+			}
+
+			reading, err := sv.AddReading(context.Background(), 1, tt.doc)
+			if tt.want != "" {
+				if err == nil || err.Error() != tt.want {
+					t.Errorf("got %v, want %q", err, tt.want)
+				}
+			} else if err != nil {
+				t.Fatalf("add reading: %v", err)
+			} else if tt.doc.Override && reading.PreviousValue != tt.previous[len(tt.previous)-1].Value {
+				// the value replaced is kept for audit
+				t.Errorf("previous value: got %d, want %d", reading.PreviousValue, tt.previous[len(tt.previous)-1].Value)
+			}
+
+			vehicle, _ := rpVehicle.GetVehicleById(context.Background(), 1)
+			if vehicle.Mileage != tt.wantMileage {
+				t.Errorf("mileage: got %d, want %d", vehicle.Mileage, tt.wantMileage)
+			}
+		})
+	}
+}
+
+// TestDeleteReadingsByVehicle checks a vehicle added again with its id starts from the mileage it is registered with
+func TestDeleteReadingsByVehicle(t *testing.T) {
+	ctx := context.Background()
+	rpVehicle := repository.NewVehicleMap(map[int]models.Vehicle{1: {Id: 1}})
+	sv := NewOdometerDefault(rpVehicle, repository.NewOdometerMap(nil))
+	if _, err := sv.AddReading(ctx, 1, models.OdometerReadingDoc{Value: 5000, Source: "sales"}); err != nil {
+		t.Fatalf("add reading: %v", err)
+	}
+
+	if err := sv.DeleteReadingsByVehicle(ctx, 1); err != nil {
+		t.Fatalf("delete readings: %v", err)
+	}
+	_ = rpVehicle.DeleteVehicle(ctx, 1)
+	_, _ = rpVehicle.AddVehicle(ctx, models.Vehicle{Id: 1})
+
+	if _, err := sv.AddReading(ctx, 1, models.OdometerReadingDoc{Value: 100, Source: "sales"}); err != nil {
+		t.Errorf("first reading of the vehicle added again: %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+)
+
+// OdometerService is an interface that represents an odometer service
+type OdometerService interface {
+	// AddReading is a method that records a reading and updates the current mileage of the vehicle
+	AddReading(ctx context.Context, vehicleId int, readingDoc models.OdometerReadingDoc) (reading models.OdometerReading, err error)
+	// AddReadingWith is a method that records a reading as AddReading, running save once the reading is accepted and
+	// before it is recorded, the reading is not recorded if save fails
+	AddReadingWith(ctx context.Context, vehicleId int, readingDoc models.OdometerReadingDoc, save func() error) (reading models.OdometerReading, err error)
+	// FindReadingsByVehicle is a method that returns the reading history of a vehicle
+	FindReadingsByVehicle(ctx context.Context, vehicleId int) (r []models.OdometerReading, err error)
+	// DeleteReadingsByVehicle is a method that removes the reading history of a vehicle
+	DeleteReadingsByVehicle(ctx context.Context, vehicleId int) (err error)
+}
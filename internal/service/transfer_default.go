@@ -0,0 +1,189 @@
+package service
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewTransferDefault is a function that returns a new instance of TransferDefault
+func NewTransferDefault(rpTransfer repository.TransferRepository, rpVehicle repository.VehicleRepository, rpBranch repository.BranchRepository) *TransferDefault {
+	return &TransferDefault{rpTransfer: rpTransfer, rpVehicle: rpVehicle, rpBranch: rpBranch}
+}
+
+// TransferDefault is a struct that represents the default service for transfers
+type TransferDefault struct {
+	// mu serializes the workflow steps so a vehicle can't have two open transfers
+	mu sync.Mutex
+	// rpTransfer is the repository of the transfers
+	rpTransfer repository.TransferRepository
+	// rpVehicle is the repository of the vehicles moved
+	rpVehicle repository.VehicleRepository
+	// rpBranch is the repository of the branches
+	rpBranch repository.BranchRepository
+}
+
+func (s *TransferDefault) RequestTransfer(ctx context.Context, transferDoc models.TransferDoc) (models.Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vehicle, err := s.rpVehicle.GetVehicleById(ctx, transferDoc.VehicleId)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	_, err = s.rpBranch.GetBranchById(transferDoc.ToBranchId)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	if vehicle.BranchId == transferDoc.ToBranchId {
+		return models.Transfer{}, errors.New("El vehículo ya se encuentra en esa sucursal")
+	}
+
+	for _, transfer := range s.rpTransfer.FindTransfersByVehicle(vehicle.Id) {
+		if transfer.Status == models.TransferRequested || transfer.Status == models.TransferInTransit {
+			return models.Transfer{}, errors.New("El vehículo ya tiene un traslado en curso")
+		}
+	}
+
+	transfer, err := s.rpTransfer.AddTransfer(models.Transfer{
+		VehicleId:    vehicle.Id,
+		FromBranchId: vehicle.BranchId,
+		ToBranchId:   transferDoc.ToBranchId,
+		Status:       models.TransferRequested,
+		Notes:        strings.TrimSpace(transferDoc.Notes),
+		RequestedAt:  time.Now(),
+		RequestedBy:  actor(ctx),
+	})
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+func (s *TransferDefault) Dispatch(ctx context.Context, id int) (models.Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.rpTransfer.GetTransferById(id)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	if transfer.Status != models.TransferRequested {
+		return models.Transfer{}, errors.New("El traslado no admite esa transición")
+	}
+
+	err = s.rpVehicle.UpdateStatus(ctx, transfer.VehicleId, models.VehicleInTransit)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+
+	now := time.Now()
+	transfer.Status = models.TransferInTransit
+	transfer.DispatchedAt = &now
+	transfer.DispatchedBy = actor(ctx)
+	err = s.rpTransfer.UpdateTransfer(transfer)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+func (s *TransferDefault) Receive(ctx context.Context, id int) (models.Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.rpTransfer.GetTransferById(id)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	if transfer.Status != models.TransferInTransit {
+		return models.Transfer{}, errors.New("El traslado no admite esa transición")
+	}
+
+	// the vehicle is never at the destination still in transit
+	err = s.rpVehicle.UpdateBranch(ctx, transfer.VehicleId, transfer.ToBranchId, models.VehicleAvailable)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+
+	now := time.Now()
+	transfer.Status = models.TransferReceived
+	transfer.ReceivedAt = &now
+	transfer.ReceivedBy = actor(ctx)
+	err = s.rpTransfer.UpdateTransfer(transfer)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+func (s *TransferDefault) Cancel(ctx context.Context, id int) (models.Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, err := s.rpTransfer.GetTransferById(id)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	if transfer.Status != models.TransferRequested {
+		return models.Transfer{}, errors.New("El traslado no admite esa transición")
+	}
+
+	now := time.Now()
+	transfer.Status = models.TransferCancelled
+	transfer.CancelledAt = &now
+	transfer.CancelledBy = actor(ctx)
+	err = s.rpTransfer.UpdateTransfer(transfer)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+func (s *TransferDefault) GetTransferById(ctx context.Context, id int) (models.Transfer, error) {
+	transfer, err := s.rpTransfer.GetTransferById(id)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return transfer, nil
+}
+
+func (s *TransferDefault) FindTransfers(ctx context.Context, status string) (t map[int]models.Transfer, err error) {
+	t = s.rpTransfer.FindTransfers(status)
+	if len(t) == 0 {
+		return t, errors.New("No se encontraron traslados con esos criterios")
+	}
+	return t, nil
+}
+
+func (s *TransferDefault) FindTransfersByVehicle(ctx context.Context, vehicleId int) (t []models.Transfer, err error) {
+	t = s.rpTransfer.FindTransfersByVehicle(vehicleId)
+	if len(t) == 0 {
+		return t, errors.New("No se encontraron traslados con esos criterios")
+	}
+	return t, nil
+}
+
+// CheckVehicleDeletable returns why a vehicle can't be deleted, the transfers in course need it
+func (s *TransferDefault) CheckVehicleDeletable(ctx context.Context, vehicleId int) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, transfer := range s.rpTransfer.FindTransfersByVehicle(vehicleId) {
+		if transfer.Status == models.TransferRequested || transfer.Status == models.TransferInTransit {
+			return errors.New("El vehículo tiene un traslado en curso")
+		}
+	}
+	return nil
+}
+
+func (s *TransferDefault) DeleteTransfersByVehicle(ctx context.Context, vehicleId int) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rpTransfer.DeleteTransfersByVehicle(vehicleId)
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+)
+
+// TransferService is an interface that represents the workflow of transfers between branches
+type TransferService interface {
+	// RequestTransfer is a method that requests moving a vehicle to another branch
+	RequestTransfer(ctx context.Context, transferDoc models.TransferDoc) (models.Transfer, error)
+	// Dispatch is a method that marks the vehicle as in transit
+	Dispatch(ctx context.Context, id int) (models.Transfer, error)
+	// Receive is a method that moves the vehicle to the destination branch
+	Receive(ctx context.Context, id int) (models.Transfer, error)
+	// Cancel is a method that cancels a transfer not dispatched yet
+	Cancel(ctx context.Context, id int) (models.Transfer, error)
+	GetTransferById(ctx context.Context, id int) (models.Transfer, error)
+	FindTransfers(ctx context.Context, status string) (t map[int]models.Transfer, err error)
+	FindTransfersByVehicle(ctx context.Context, vehicleId int) (t []models.Transfer, err error)
+	// CheckVehicleDeletable is a method that returns why a vehicle can't be deleted, a transfer in course
+	CheckVehicleDeletable(ctx context.Context, vehicleId int) (err error)
+	// DeleteTransfersByVehicle is a method that removes the transfers of a vehicle, open or not
+	DeleteTransfersByVehicle(ctx context.Context, vehicleId int) (err error)
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"app/internal/auth"
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// usernamePattern are the usernames accepted
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{3,32}$`)
+
+// dummyHash is compared when the user does not exist, so a login takes the same time either way
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("concesionaria"), bcrypt.DefaultCost)
+
+// NewUserDefault is a function that returns a new instance of UserDefault
+func NewUserDefault(rp repository.UserRepository, rpToken repository.TokenRepository, signer *auth.Signer, refreshTTL time.Duration, resetTTL time.Duration) *UserDefault {
+	return &UserDefault{rp: rp, rpToken: rpToken, signer: signer, refreshTTL: refreshTTL, resetTTL: resetTTL}
+}
+
+// UserDefault is a struct that represents the default service for users
+type UserDefault struct {
+	// rp is the repository of the users
+	rp repository.UserRepository
+	// rpToken is the repository of the refresh, revoked and reset tokens
+	rpToken repository.TokenRepository
+	// signer issues the access tokens
+	signer *auth.Signer
+	// refreshTTL is the lifetime of the refresh tokens
+	refreshTTL time.Duration
+	// resetTTL is the lifetime of the password reset tokens
+	resetTTL time.Duration
+}
+
+func (s *UserDefault) FindAll(ctx context.Context) (u map[int]models.User, err error) {
+	return s.rp.FindAll()
+}
+
+func (s *UserDefault) CreateUser(ctx context.Context, userDoc models.UserDoc) (models.User, error) {
+	if !usernamePattern.MatchString(userDoc.Username) || strings.TrimSpace(userDoc.FullName) == "" {
+		return models.User{}, errors.New("Datos del usuario incompletos o mal formados")
+	}
+	if !auth.ValidRole(userDoc.Role) {
+		return models.User{}, errors.New("Rol inválido, debe ser viewer, salesperson o admin")
+	}
+
+	hash, err := hashPassword(userDoc.Password)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user, err := s.rp.AddUser(models.User{
+		Username:     userDoc.Username,
+		FullName:     strings.TrimSpace(userDoc.FullName),
+		Role:         userDoc.Role,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+func (s *UserDefault) GetUserById(ctx context.Context, id int) (models.User, error) {
+	return s.rp.GetUserById(id)
+}
+
+func (s *UserDefault) Login(ctx context.Context, username string, password string) (models.TokenPair, error) {
+	user, err := s.rp.GetUserByUsername(username)
+	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return models.TokenPair{}, errors.New("Usuario o contraseña incorrectos")
+	}
+	if bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)) != nil {
+		return models.TokenPair{}, errors.New("Usuario o contraseña incorrectos")
+	}
+
+	return s.issue(user)
+}
+
+func (s *UserDefault) Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	hash := hashToken(refreshToken)
+	token, err := s.rpToken.GetRefreshToken(hash)
+	if err != nil || token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return models.TokenPair{}, errors.New("Token de refresco inválido o vencido")
+	}
+
+	// rotate: a refresh token is good for a single use
+	if err = s.rpToken.RevokeRefreshToken(hash, time.Now()); err != nil {
+		return models.TokenPair{}, errors.New("Token de refresco inválido o vencido")
+	}
+
+	user, err := s.rp.GetUserById(token.UserId)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	return s.issue(user)
+}
+
+func (s *UserDefault) Logout(ctx context.Context, refreshToken string) (err error) {
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok || p.Method != auth.MethodJWT {
+		return errors.New("Solo se puede cerrar la sesión de un token de acceso")
+	}
+	s.rpToken.RevokeAccessToken(p.TokenId, p.ExpiresAt)
+
+	if refreshToken != "" {
+		hash := hashToken(refreshToken)
+		// only the owner can revoke it
+		if token, err := s.rpToken.GetRefreshToken(hash); err == nil && token.UserId == p.UserId {
+			s.rpToken.RevokeRefreshToken(hash, time.Now())
+		}
+	}
+	return nil
+}
+
+func (s *UserDefault) RevokeTokens(ctx context.Context, userId int) (err error) {
+	user, err := s.rp.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s.rpToken.RevokeRefreshTokensByUser(userId, now)
+	user.TokensRevokedAt = now
+	return s.rp.UpdateUser(user)
+}
+
+func (s *UserDefault) RequestPasswordReset(ctx context.Context, userId int) (models.PasswordResetDoc, error) {
+	if _, err := s.rp.GetUserById(userId); err != nil {
+		return models.PasswordResetDoc{}, err
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return models.PasswordResetDoc{}, err
+	}
+	reset := models.PasswordReset{
+		Hash:      hashToken(token),
+		UserId:    userId,
+		ExpiresAt: time.Now().Add(s.resetTTL),
+	}
+	if err = s.rpToken.AddPasswordReset(reset); err != nil {
+		return models.PasswordResetDoc{}, err
+	}
+	return models.PasswordResetDoc{Token: token, UserId: userId, ExpiresAt: reset.ExpiresAt}, nil
+}
+
+func (s *UserDefault) ResetPassword(ctx context.Context, token string, password string) (err error) {
+	hash := hashToken(token)
+	reset, err := s.rpToken.GetPasswordReset(hash)
+	if err != nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return errors.New("Token de restablecimiento inválido o vencido")
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err = s.rpToken.UsePasswordReset(hash, time.Now()); err != nil {
+		return errors.New("Token de restablecimiento inválido o vencido")
+	}
+
+	user, err := s.rp.GetUserById(reset.UserId)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = passwordHash
+	if err = s.rp.UpdateUser(user); err != nil {
+		return err
+	}
+
+	// whoever knew the old password must log in again
+	return s.RevokeTokens(ctx, user.Id)
+}
+
+// Revoked is a method that implements auth.Revocation: a token is rejected if it was
+// revoked on logout, or issued before the tokens of its user were revoked
+func (s *UserDefault) Revoked(ctx context.Context, claims auth.Claims) bool {
+	if claims.ID != "" && s.rpToken.IsAccessTokenRevoked(claims.ID) {
+		return true
+	}
+	if claims.UserId == 0 {
+		return false
+	}
+
+	user, err := s.rp.GetUserById(claims.UserId)
+	if err != nil {
+		// the account no longer exists
+		return true
+	}
+	if user.TokensRevokedAt.IsZero() {
+		return claims.IssuedAt == nil
+	}
+	// iat has a precision of seconds, a token issued in the same second as the revocation is rejected
+	return claims.IssuedAt == nil || !claims.IssuedAt.Time.After(user.TokensRevokedAt.Truncate(time.Second))
+}
+
+// issue returns a new access and refresh token pair for the user
+func (s *UserDefault) issue(user models.User) (models.TokenPair, error) {
+	access, err := s.signer.Sign(user.Id, user.Username, user.Role)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	refresh, err := newToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	err = s.rpToken.AddRefreshToken(models.RefreshToken{
+		Hash:      hashToken(refresh),
+		UserId:    user.Id,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	})
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.signer.TTL().Seconds()),
+	}, nil
+}
+
+// hashPassword checks the length of the password and returns its bcrypt hash
+func hashPassword(password string) ([]byte, error) {
+	// bcrypt ignores everything past 72 bytes
+	if len(password) < 8 || len(password) > 72 {
+		return nil, errors.New("La contraseña debe tener entre 8 y 72 caracteres")
+	}
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// newToken returns a random opaque token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the sha256 of a token, which is what is stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"app/pkg/models"
+	"context"
+)
+
+// UserService is an interface that represents the staff accounts and their sessions
+type UserService interface {
+	// FindAll is a method that returns a map of all users
+	FindAll(ctx context.Context) (u map[int]models.User, err error)
+	CreateUser(ctx context.Context, userDoc models.UserDoc) (models.User, error)
+	GetUserById(ctx context.Context, id int) (models.User, error)
+	// Login is a method that checks the password and issues an access and a refresh token
+	Login(ctx context.Context, username string, password string) (models.TokenPair, error)
+	// Refresh is a method that exchanges a refresh token for a new pair, the old one can't be used again
+	Refresh(ctx context.Context, refreshToken string) (models.TokenPair, error)
+	// Logout is a method that revokes the access token of the caller and the refresh token given
+	Logout(ctx context.Context, refreshToken string) (err error)
+	// RevokeTokens is a method that invalidates every token issued to the user
+	RevokeTokens(ctx context.Context, userId int) (err error)
+	// RequestPasswordReset is a method that issues a single use token to set a new password
+	RequestPasswordReset(ctx context.Context, userId int) (models.PasswordResetDoc, error)
+	ResetPassword(ctx context.Context, token string, password string) (err error)
+}
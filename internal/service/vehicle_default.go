@@ -3,9 +3,12 @@ package service
 import (
 	"app/internal/repository"
 	"app/pkg/models"
+	"context"
 	"errors"
 
 	"strconv"
+
+	"github.com/shopspring/decimal"
 )
 
 // NewVehicleDefault is a function that returns a new instance of VehicleDefault
@@ -17,15 +20,36 @@ func NewVehicleDefault(rp repository.VehicleRepository) *VehicleDefault {
 type VehicleDefault struct {
 	// rp is the repository that will be used by the service
 	rp repository.VehicleRepository
+	// rpBranch is the repository of the branches of the new vehicles, nil when they are not checked
+	rpBranch repository.BranchRepository
+	// deleteChecks are called before a vehicle is deleted, an error keeps it
+	deleteChecks []func(ctx context.Context, id int) error
+	// deleteHooks are called after a vehicle is deleted to clean up what belongs to it
+	deleteHooks []func(ctx context.Context, id int) error
+}
+
+// CheckDelete is a method that registers a function that tells why a vehicle can't be deleted
+func (s *VehicleDefault) CheckDelete(check func(ctx context.Context, id int) error) {
+	s.deleteChecks = append(s.deleteChecks, check)
+}
+
+// OnDelete is a method that registers a function called after a vehicle is deleted
+func (s *VehicleDefault) OnDelete(hook func(ctx context.Context, id int) error) {
+	s.deleteHooks = append(s.deleteHooks, hook)
+}
+
+// UseBranches is a method that checks the branch of each new vehicle exists in the repository
+func (s *VehicleDefault) UseBranches(rpBranch repository.BranchRepository) {
+	s.rpBranch = rpBranch
 }
 
 // FindAll is a method that returns a map of all vehicles
-func (s *VehicleDefault) FindAll() (v map[int]models.Vehicle, err error) {
-	v, err = s.rp.FindAll()
+func (s *VehicleDefault) FindAll(ctx context.Context) (v map[int]models.Vehicle, err error) {
+	v, err = s.rp.FindAll(ctx)
 	return
 }
 
-func (s *VehicleDefault) AddVehicle(vehicleDoc models.VehicleDoc) (models.Vehicle, error) {
+func (s *VehicleDefault) AddVehicle(ctx context.Context, vehicleDoc models.VehicleDoc) (models.Vehicle, error) {
 	// convert vehicleDoc to vehicle
 	newVehicle := mapDocToVehicle(vehicleDoc)
 
@@ -34,14 +58,17 @@ func (s *VehicleDefault) AddVehicle(vehicleDoc models.VehicleDoc) (models.Vehicl
 	if !fieldsAreOk {
 		return models.Vehicle{}, errors.New("Campos incompletos o mal formados")
 	}
+	if err := s.CheckNewVehicle(ctx, vehicleDoc); err != nil {
+		return models.Vehicle{}, err
+	}
 
 	// check if the vehicle (id) already exists
-	_, err := s.rp.GetVehicleById(newVehicle.Id)
+	_, err := s.rp.GetVehicleById(ctx, newVehicle.Id)
 
 	// if vehicle does not exists in the db
 	if err != nil {
 		// add new vehicle to db and return it
-		_, err = s.rp.AddVehicle(newVehicle)
+		_, err = s.rp.AddVehicle(ctx, newVehicle)
 		if err != nil {
 			return models.Vehicle{}, err
 		}
@@ -53,21 +80,21 @@ func (s *VehicleDefault) AddVehicle(vehicleDoc models.VehicleDoc) (models.Vehicl
 }
 
 // FindVehiclesByColorAndYear implements VehicleService.
-func (s *VehicleDefault) FindVehiclesByColorAndYear(color string, year string) (vehicles map[int]models.Vehicle, err error) {
+func (s *VehicleDefault) FindVehiclesByColorAndYear(ctx context.Context, color string, year string) (vehicles map[int]models.Vehicle, err error) {
 	yearParsed, err := strconv.Atoi(year)
 	if err != nil {
 		return make(map[int]models.Vehicle), err
 	}
 
-	vehicles = s.rp.FindVehiclesByColorAndYear(color, yearParsed)
+	vehicles = s.rp.FindVehiclesByColorAndYear(ctx, color, yearParsed)
 	if len(vehicles) == 0 {
 		return make(map[int]models.Vehicle), errors.New("No se encontraron vehículos con esos criterios")
 	}
 	return vehicles, nil
 }
 
-func (s *VehicleDefault) FindVehiclesByBrandAndRangeYears(brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error) {
-	v, err = s.rp.FindVehiclesByBrandAndRangeYears(brand, starYear, endYear)
+func (s *VehicleDefault) FindVehiclesByBrandAndRangeYears(ctx context.Context, brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error) {
+	v, err = s.rp.FindVehiclesByBrandAndRangeYears(ctx, brand, starYear, endYear)
 
 	if err != nil {
 		return make(map[int]models.Vehicle), err
@@ -80,8 +107,8 @@ func (s *VehicleDefault) FindVehiclesByBrandAndRangeYears(brand string, starYear
 	return v, nil
 }
 
-func (s *VehicleDefault) FindAverageOfSpeedByBrand(brand string) (average float64, err error) {
-	vehicles, err := s.rp.FindVehiclesByBrand(brand)
+func (s *VehicleDefault) FindAverageOfSpeedByBrand(ctx context.Context, brand string) (average float64, err error) {
+	vehicles, err := s.rp.FindVehiclesByBrand(ctx, brand)
 	if err != nil {
 		return 0, err
 	}
@@ -97,7 +124,7 @@ func (s *VehicleDefault) FindAverageOfSpeedByBrand(brand string) (average float6
 	return 0, errors.New("No se encontraron vehículos de esa marca")
 }
 
-func (s *VehicleDefault) AddMultipleVehicles(v []models.VehicleDoc) (err error) {
+func (s *VehicleDefault) AddMultipleVehicles(ctx context.Context, v []models.VehicleDoc) (err error) {
 	for _, vehicle := range v {
 		newVehicle := mapDocToVehicle(vehicle)
 		// check mandatory fields
@@ -105,16 +132,19 @@ func (s *VehicleDefault) AddMultipleVehicles(v []models.VehicleDoc) (err error)
 		if !fieldsAreOk {
 			return errors.New("Datos de algún vehículo mal formados o incompletos")
 		}
+		if err := s.CheckNewVehicle(ctx, vehicle); err != nil {
+			return err
+		}
 
 		// check if the vehicle (id) already exists
-		_, err := s.rp.GetVehicleById(newVehicle.Id)
+		_, err := s.rp.GetVehicleById(ctx, newVehicle.Id)
 
 		// if vehicle exists in the db
 		if err == nil {
 			return errors.New("Algún vehículo tiene un identificador ya existente")
 		} else {
 			// add new vehicle to db and return it
-			_, err = s.rp.AddVehicle(newVehicle)
+			_, err = s.rp.AddVehicle(ctx, newVehicle)
 			if err != nil {
 				return err
 			}
@@ -123,17 +153,39 @@ func (s *VehicleDefault) AddMultipleVehicles(v []models.VehicleDoc) (err error)
 	return nil
 }
 
-func (s *VehicleDefault) UpdateMaxSpeed(id int, newSpeed float64) (err error) {
+// CheckNewVehicle is a method that returns why a vehicle can't be added besides its mandatory fields.
+// New stock is available, the other statuses are reached through the transfers, and it goes to a branch
+// that exists, the default one when none is given.
+func (s *VehicleDefault) CheckNewVehicle(ctx context.Context, vehicleDoc models.VehicleDoc) (err error) {
+	return CheckStock(s.rpBranch, vehicleDoc.Status, vehicleDoc.BranchId)
+}
+
+// CheckStock is a function that returns why a vehicle with the status and branch can't be new stock, the rules
+// of CheckNewVehicle also applied to the vehicles read from the files. An empty status or no branch are the
+// defaults, and the branch is not checked when rpBranch is nil.
+func CheckStock(rpBranch repository.BranchRepository, status string, branchId int) (err error) {
+	if status != "" && status != models.VehicleAvailable {
+		return errors.New("Un vehículo nuevo solo puede estar disponible")
+	}
+	if rpBranch != nil && branchId != 0 {
+		if _, err = rpBranch.GetBranchById(branchId); err != nil {
+			return errors.New("La sucursal del vehículo no existe")
+		}
+	}
+	return nil
+}
+
+func (s *VehicleDefault) UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error) {
 	if newSpeed <= 0 {
 		return errors.New("Velocidad mal formada o fuera de rango.")
 	}
 
-	_, err = s.rp.GetVehicleById(id)
+	_, err = s.rp.GetVehicleById(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	err = s.rp.UpdateMaxSpeed(id, newSpeed)
+	err = s.rp.UpdateMaxSpeed(ctx, id, newSpeed)
 	if err != nil {
 		return err
 	}
@@ -141,16 +193,16 @@ func (s *VehicleDefault) UpdateMaxSpeed(id int, newSpeed float64) (err error) {
 	return nil
 }
 
-func (s *VehicleDefault) GetVehicleById(id int) (models.Vehicle, error) {
-	vehicle, err := s.rp.GetVehicleById(id)
+func (s *VehicleDefault) GetVehicleById(ctx context.Context, id int) (models.Vehicle, error) {
+	vehicle, err := s.rp.GetVehicleById(ctx, id)
 	if err != nil {
 		return models.Vehicle{}, err
 	}
 	return vehicle, nil
 }
 
-func (s *VehicleDefault) FindVehiclesByFuel(fuel string) (map[int]models.Vehicle, error) {
-	v := s.rp.FindVehiclesByFuel(fuel)
+func (s *VehicleDefault) FindVehiclesByFuel(ctx context.Context, fuel string) (map[int]models.Vehicle, error) {
+	v := s.rp.FindVehiclesByFuel(ctx, fuel)
 	if len(v) == 0 {
 		return make(map[int]models.Vehicle), errors.New("No se encontraron vehículos con ese tipo de combustible")
 	}
@@ -158,21 +210,38 @@ func (s *VehicleDefault) FindVehiclesByFuel(fuel string) (map[int]models.Vehicle
 	return v, nil
 }
 
-func (s *VehicleDefault) DeleteVehicle(id int) (err error) {
-	_, err = s.rp.GetVehicleById(id)
+func (s *VehicleDefault) DeleteVehicle(ctx context.Context, id int) (err error) {
+	_, err = s.rp.GetVehicleById(ctx, id)
 	if err != nil {
 		return err
 	}
+	for _, check := range s.deleteChecks {
+		if err = check(ctx, id); err != nil {
+			return err
+		}
+	}
 
-	err = s.rp.DeleteVehicle(id)
+	err = s.rp.DeleteVehicle(ctx, id)
 	if err != nil {
 		return err
 	}
+
+	return s.Deleted(ctx, id)
+}
+
+// Deleted is a method that calls the functions registered with OnDelete for a vehicle that was removed,
+// by the service or without it, e.g. dropped by a reload of the files
+func (s *VehicleDefault) Deleted(ctx context.Context, id int) (err error) {
+	for _, hook := range s.deleteHooks {
+		if err = hook(ctx, id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *VehicleDefault) FindVehiclesByTransmission(transmisiion string) (v map[int]models.Vehicle, err error) {
-	v = s.rp.FindVehiclesByTransmission(transmisiion)
+func (s *VehicleDefault) FindVehiclesByTransmission(ctx context.Context, transmisiion string) (v map[int]models.Vehicle, err error) {
+	v = s.rp.FindVehiclesByTransmission(ctx, transmisiion)
 	if len(v) == 0 {
 		return v, errors.New("No se encontraron vehículos con ese tipo de transmisión")
 	}
@@ -180,18 +249,18 @@ func (s *VehicleDefault) FindVehiclesByTransmission(transmisiion string) (v map[
 	return v, nil
 }
 
-func (s *VehicleDefault) UpdateFuel(id int, vehicleDoc models.VehicleDoc) (err error) {
+func (s *VehicleDefault) UpdateFuel(ctx context.Context, id int, vehicleDoc models.VehicleDoc) (err error) {
 	if vehicleDoc.FuelType == "" {
 		return errors.New("Tipo de combustible mal formado o no admitido")
 	}
-	_, err = s.rp.GetVehicleById(id)
+	_, err = s.rp.GetVehicleById(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	vehicle := mapDocToVehicle(vehicleDoc)
 
-	err = s.rp.UpdateFuel(id, vehicle.FuelType)
+	err = s.rp.UpdateFuel(ctx, id, vehicle.FuelType)
 	if err != nil {
 		return err
 	}
@@ -199,8 +268,8 @@ func (s *VehicleDefault) UpdateFuel(id int, vehicleDoc models.VehicleDoc) (err e
 	return nil
 }
 
-func (s *VehicleDefault) GetAveragePeopleCapacityByBrand(brand string) (capacity int, err error) {
-	vehicles := s.rp.GetVehiclesByBrand(brand)
+func (s *VehicleDefault) GetAveragePeopleCapacityByBrand(ctx context.Context, brand string) (capacity int, err error) {
+	vehicles := s.rp.GetVehiclesByBrand(ctx, brand)
 	if len(vehicles) == 0 {
 		return 0, errors.New("No se encontraron vehículos de esa marca")
 	}
@@ -212,22 +281,53 @@ func (s *VehicleDefault) GetAveragePeopleCapacityByBrand(brand string) (capacity
 	return capacity / len(vehicles), nil
 }
 
-func (s *VehicleDefault) FindVehiclesByDimensions(minLength float64, maxLength float64, minWidth float64, maxWidth float64) (map[int]models.Vehicle, error) {
-	vehicles := s.rp.FindVehiclesByDimensions(minLength, maxLength, minWidth, maxWidth)
+func (s *VehicleDefault) FindVehiclesByDimensions(ctx context.Context, minLength float64, maxLength float64, minWidth float64, maxWidth float64) (map[int]models.Vehicle, error) {
+	vehicles := s.rp.FindVehiclesByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
 	if len(vehicles) == 0 {
 		return vehicles, errors.New("No se encontraron vehículos con esas dimensiones")
 	}
 	return vehicles, nil
 }
 
-func (s *VehicleDefault) FindVehiclesByWeigth(minWeigth float64, maxWeigth float64) (v map[int]models.Vehicle, err error) {
-	vehicles := s.rp.FindVehiclesByWeigth(minWeigth, maxWeigth)
+func (s *VehicleDefault) FindVehiclesByWeigth(ctx context.Context, minWeigth float64, maxWeigth float64) (v map[int]models.Vehicle, err error) {
+	vehicles := s.rp.FindVehiclesByWeigth(ctx, minWeigth, maxWeigth)
 	if len(vehicles) == 0 {
 		return v, errors.New("No se encontraron vehículos en ese rango de peso")
 	}
 	return vehicles, nil
 }
 
+func (s *VehicleDefault) UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error) {
+	if !newPrice.IsPositive() {
+		return errors.New("Precio mal formado o fuera de rango")
+	}
+
+	_, err = s.rp.GetVehicleById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// prices are kept in cents, as the maintenance costs
+	err = s.rp.UpdatePrice(ctx, id, newPrice.Round(2))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *VehicleDefault) FindVehiclesByMileage(ctx context.Context, minMileage int, maxMileage int) (map[int]models.Vehicle, error) {
+	if minMileage < 0 || maxMileage < minMileage {
+		return make(map[int]models.Vehicle), errors.New("Rango de kilometraje mal formado")
+	}
+
+	vehicles := s.rp.FindVehiclesByMileage(ctx, minMileage, maxMileage)
+	if len(vehicles) == 0 {
+		return vehicles, errors.New("No se encontraron vehículos en ese rango de kilometraje")
+	}
+	return vehicles, nil
+}
+
 func mapDocToVehicle(doc models.VehicleDoc) models.Vehicle {
 	vehicle := models.Vehicle{
 		Id: doc.ID,
@@ -242,6 +342,10 @@ func mapDocToVehicle(doc models.VehicleDoc) models.Vehicle {
 			FuelType:        doc.FuelType,
 			Transmission:    doc.Transmission,
 			Weight:          doc.Weight,
+			Price:           doc.Price.Round(2),
+			Mileage:         doc.Mileage,
+			BranchId:        doc.BranchId,
+			Status:          doc.Status,
 			Dimensions: models.Dimensions{
 				Height: doc.Height,
 				Length: doc.Length,
@@ -249,25 +353,17 @@ func mapDocToVehicle(doc models.VehicleDoc) models.Vehicle {
 			},
 		},
 	}
+
+	// new stock goes to the default branch unless told otherwise
+	if vehicle.BranchId == 0 {
+		vehicle.BranchId = models.DefaultBranchId
+	}
+	if vehicle.Status == "" {
+		vehicle.Status = models.VehicleAvailable
+	}
 	return vehicle
 }
 
 func areMandatoryFieldsOK(vehicle models.Vehicle) bool {
-	if vehicle.Id == 0 ||
-		vehicle.Brand == "" ||
-		vehicle.Model == "" ||
-		vehicle.Registration == "" ||
-		vehicle.Color == "" ||
-		vehicle.FabricationYear == 0 ||
-		vehicle.Capacity == 0 ||
-		vehicle.MaxSpeed == 0 ||
-		vehicle.FuelType == "" ||
-		vehicle.Transmission == "" ||
-		vehicle.Weight == 0 ||
-		vehicle.Height == 0 ||
-		vehicle.Length == 0 ||
-		vehicle.Width == 0 {
-		return false
-	}
-	return true
+	return len(vehicle.MissingFields()) == 0
 }
@@ -1,23 +1,32 @@
 package service
 
-import "app/pkg/models"
+import (
+	"app/pkg/models"
+	"context"
+
+	"github.com/shopspring/decimal"
+)
 
 // VehicleService is an interface that represents a vehicle service
 type VehicleService interface {
 	// FindAll is a method that returns a map of all vehicles
-	FindAll() (v map[int]models.Vehicle, err error)
-	AddVehicle(vehicleDoc models.VehicleDoc) (models.Vehicle, error)
-	FindVehiclesByColorAndYear(color string, year string) (vehicles map[int]models.Vehicle, err error)
-	FindVehiclesByBrandAndRangeYears(brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error)
-	FindAverageOfSpeedByBrand(brand string) (average float64, err error)
-	AddMultipleVehicles(v []models.VehicleDoc) (err error)
-	UpdateMaxSpeed(id int, newSpeed float64) (err error)
-	GetVehicleById(id int) (models.Vehicle, error)
-	FindVehiclesByFuel(fuel string) (v map[int]models.Vehicle, err error)
-	DeleteVehicle(id int) (err error)
-	FindVehiclesByTransmission(transmisiion string) (v map[int]models.Vehicle, err error)
-	UpdateFuel(id int, vehicleDoc models.VehicleDoc) (err error)
-	GetAveragePeopleCapacityByBrand(brand string) (capacity int, err error)
-	FindVehiclesByDimensions(minLength float64, maxLength float64, minWidth float64, maxWidth float64) (map[int]models.Vehicle, error)
-	FindVehiclesByWeigth(minWeigth float64, maxWeigth float64) (map[int]models.Vehicle, error)
+	FindAll(ctx context.Context) (v map[int]models.Vehicle, err error)
+	AddVehicle(ctx context.Context, vehicleDoc models.VehicleDoc) (models.Vehicle, error)
+	FindVehiclesByColorAndYear(ctx context.Context, color string, year string) (vehicles map[int]models.Vehicle, err error)
+	FindVehiclesByBrandAndRangeYears(ctx context.Context, brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error)
+	FindAverageOfSpeedByBrand(ctx context.Context, brand string) (average float64, err error)
+	AddMultipleVehicles(ctx context.Context, v []models.VehicleDoc) (err error)
+	// CheckNewVehicle is a method that returns why a vehicle can't be added besides its mandatory fields
+	CheckNewVehicle(ctx context.Context, vehicleDoc models.VehicleDoc) (err error)
+	UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error)
+	GetVehicleById(ctx context.Context, id int) (models.Vehicle, error)
+	FindVehiclesByFuel(ctx context.Context, fuel string) (v map[int]models.Vehicle, err error)
+	DeleteVehicle(ctx context.Context, id int) (err error)
+	FindVehiclesByTransmission(ctx context.Context, transmisiion string) (v map[int]models.Vehicle, err error)
+	UpdateFuel(ctx context.Context, id int, vehicleDoc models.VehicleDoc) (err error)
+	GetAveragePeopleCapacityByBrand(ctx context.Context, brand string) (capacity int, err error)
+	FindVehiclesByDimensions(ctx context.Context, minLength float64, maxLength float64, minWidth float64, maxWidth float64) (map[int]models.Vehicle, error)
+	FindVehiclesByWeigth(ctx context.Context, minWeigth float64, maxWeigth float64) (map[int]models.Vehicle, error)
+	UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error)
+	FindVehiclesByMileage(ctx context.Context, minMileage int, maxMileage int) (map[int]models.Vehicle, error)
 }
@@ -0,0 +1,142 @@
+package snapshot
+
+import (
+	"app/internal/loader"
+	"app/pkg/models"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotReady is returned before the vehicles are loaded for the first time
+var ErrNotReady = errors.New("the vehicles are still loading")
+
+const (
+	// prefix and extension are the parts of the names of the snapshot files around their time
+	prefix    = "vehicles-"
+	extension = ".json"
+	// timeLayout is the time of the snapshot files in UTC, their names sort in the order they were taken
+	timeLayout = "20060102T150405Z"
+)
+
+// Source is an interface that represents the repository the snapshots are taken from
+type Source interface {
+	// FindAll is a method that returns a copy of all the vehicles at once
+	FindAll(ctx context.Context) (v map[int]models.Vehicle, err error)
+}
+
+// NewSnapshotter is a function that returns a new instance of Snapshotter, it keeps the
+// last keep files written to dir
+func NewSnapshotter(dir string, keep int) *Snapshotter {
+	return &Snapshotter{dir: dir, keep: keep}
+}
+
+// Snapshotter is a struct that takes point-in-time copies of all the vehicles, in the format the
+// loader reads so a copy can be loaded back with loader_file_path or /admin/reload
+type Snapshotter struct {
+	// dir is where the scheduled snapshots are written
+	dir string
+	// keep is the number of files kept in dir, the oldest ones are removed
+	keep int
+	// mu serializes the writes to dir and protects src
+	mu sync.Mutex
+	// src is the repository of the vehicles, nil until they are loaded
+	src Source
+}
+
+// Use is a method that sets the repository the snapshots are taken from
+func (s *Snapshotter) Use(src Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src = src
+}
+
+// Take is a method that returns all the vehicles as they are at the moment, and that moment
+func (s *Snapshotter) Take(ctx context.Context) (v map[int]models.Vehicle, at time.Time, err error) {
+	s.mu.Lock()
+	src := s.src
+	s.mu.Unlock()
+	if src == nil {
+		return nil, time.Time{}, ErrNotReady
+	}
+
+	at = time.Now()
+	v, err = src.FindAll(ctx)
+	return
+}
+
+// Save is a method that writes a snapshot to the directory and removes the oldest ones beyond keep
+func (s *Snapshotter) Save(ctx context.Context) (path string, count int, err error) {
+	v, at, err := s.Take(ctx)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = os.MkdirAll(s.dir, 0o755); err != nil {
+		return
+	}
+	// the file is replaced at once, a restore never reads half a snapshot
+	path = filepath.Join(s.dir, Name(at))
+	if err = loader.NewVehicleJSONFile(path).Save(v); err != nil {
+		return
+	}
+	return path, len(v), s.rotate()
+}
+
+// rotate removes the oldest snapshot files beyond keep
+func (s *Snapshotter) rotate() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	// the other files of the directory are left alone
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), extension) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for len(names) > s.keep {
+		if err := os.Remove(filepath.Join(s.dir, names[0])); err != nil {
+			errs = append(errs, err)
+		}
+		names = names[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// Run is a method that writes a snapshot every interval until the context is done
+func (s *Snapshotter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			path, count, err := s.Save(ctx)
+			if err != nil {
+				slog.Error("writing snapshot", "dir", s.dir, "error", err)
+				continue
+			}
+			slog.Info("snapshot written", "path", path, "count", count)
+		}
+	}
+}
+
+// Name is a function that returns the name of the file of a snapshot taken at a time
+func Name(at time.Time) string {
+	return prefix + at.UTC().Format(timeLayout) + extension
+}
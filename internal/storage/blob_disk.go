@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// NewBlobDisk is a function that returns a new instance of BlobDisk
+func NewBlobDisk(dir string) (*BlobDisk, error) {
+	// default dir
+	if dir == "" {
+		dir = "attachments"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &BlobDisk{dir: dir}, nil
+}
+
+// BlobDisk is a struct that stores files in a local directory named by their sha256
+type BlobDisk struct {
+	// dir is the root directory of the files
+	dir string
+}
+
+// Save writes the content to a temporary file while hashing it and then moves it
+// to its final path, which is shared by every file with the same content
+func (s *BlobDisk) Save(content io.Reader) (hash string, size int64, err error) {
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err = io.Copy(io.MultiWriter(tmp, hasher), content)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	hash = hex.EncodeToString(hasher.Sum(nil))
+
+	// deduplicate: the content is already stored
+	path := s.path(hash)
+	if _, err = os.Stat(path); err == nil {
+		return hash, size, nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", 0, err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	return hash, size, nil
+}
+
+func (s *BlobDisk) Open(hash string) (io.ReadCloser, error) {
+	if !validHash(hash) {
+		return nil, errors.New("Blob not found")
+	}
+	file, err := os.Open(s.path(hash))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.New("Blob not found")
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+func (s *BlobDisk) Remove(hash string) error {
+	if !validHash(hash) {
+		return errors.New("Blob not found")
+	}
+	err := os.Remove(s.path(hash))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns where a hash is stored, fanned out by its first two characters
+func (s *BlobDisk) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+// validHash tells if a hash is a hex encoded sha256, so it can't escape the directory
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// Ping checks the directory is writable
+func (s *BlobDisk) Ping() error {
+	tmp, err := os.CreateTemp(s.dir, "ping-*")
+	if err != nil {
+		return err
+	}
+	err = tmp.Close()
+	if errRemove := os.Remove(tmp.Name()); err == nil {
+		err = errRemove
+	}
+	return err
+}
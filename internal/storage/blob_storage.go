@@ -0,0 +1,16 @@
+package storage
+
+import "io"
+
+// BlobStorage is an interface that represents a content addressed storage of files
+type BlobStorage interface {
+	// Save is a method that stores the content and returns its hash and size.
+	// Saving the same content twice keeps a single copy.
+	Save(content io.Reader) (hash string, size int64, err error)
+	// Open is a method that returns the content stored under a hash
+	Open(hash string) (io.ReadCloser, error)
+	// Remove is a method that deletes the content stored under a hash
+	Remove(hash string) error
+	// Ping is a method that checks new content can be stored
+	Ping() error
+}
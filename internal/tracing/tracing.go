@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ExporterNone disables the tracing
+	ExporterNone = "none"
+	// ExporterOTLP sends the spans to an OTLP collector over HTTP
+	ExporterOTLP = "otlp"
+	// ExporterFile writes the spans as JSON lines to a local file, for offline debugging
+	ExporterFile = "file"
+)
+
+// serviceName is the name the spans are reported under
+const serviceName = "concesionaria"
+
+// tracer creates the spans of the server, it follows the provider set by Setup
+var tracer = otel.Tracer("app")
+
+// Config is a struct that represents where the spans are exported
+type Config struct {
+	// Exporter is none, otlp or file
+	Exporter string
+	// Endpoint is the URL of the OTLP collector, empty for the OTEL_EXPORTER_OTLP_* environment or its default
+	Endpoint string
+	// File is the path of the file written by the file exporter
+	File string
+	// ServiceVersion is the version of the binary reported in the spans
+	ServiceVersion string
+}
+
+// Setup is a function that sets the global tracer provider and propagator.
+// The returned function flushes the spans still buffered and must be called on shutdown.
+func Setup(ctx context.Context, cfg Config) (shutdown func(ctx context.Context) error, err error) {
+	// the trace context of the callers is always propagated, even if nothing is exported
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closeOutput func() error
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterFile:
+		var file *os.File
+		file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		closeOutput = file.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeOutput != nil {
+			err = errors.Join(err, closeOutput())
+		}
+		return err
+	}, nil
+}
+
+// start is a function that starts a span child of the one in the context
+func start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// end is a function that records the error of the operation, if any, and ends the span
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,196 @@
+package tracing
+
+import (
+	"app/internal/repository"
+	"app/pkg/models"
+	"context"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// attribute keys of the vehicle spans
+const (
+	keyVehicleID   = attribute.Key("vehicle.id")
+	keyResultCount = attribute.Key("result.count")
+)
+
+// TraceVehicles is a function that returns a repository that starts a span for every call to rp
+func TraceVehicles(rp repository.VehicleRepository) repository.VehicleRepository {
+	return &vehicleTraced{rp: rp}
+}
+
+// vehicleTraced is a struct that implements the VehicleRepository interface with a span per call
+type vehicleTraced struct {
+	// rp keeps the vehicles
+	rp repository.VehicleRepository
+}
+
+func (r *vehicleTraced) FindAll(ctx context.Context) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "repository.FindAll")
+	defer func() { end(span, err) }()
+
+	v, err = r.rp.FindAll(ctx)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) AddVehicle(ctx context.Context, newVehicle models.Vehicle) (v models.Vehicle, err error) {
+	ctx, span := start(ctx, "repository.AddVehicle", keyVehicleID.Int(newVehicle.Id))
+	defer func() { end(span, err) }()
+
+	return r.rp.AddVehicle(ctx, newVehicle)
+}
+
+func (r *vehicleTraced) GetVehicleById(ctx context.Context, id int) (v models.Vehicle, err error) {
+	ctx, span := start(ctx, "repository.GetVehicleById", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return r.rp.GetVehicleById(ctx, id)
+}
+
+func (r *vehicleTraced) FindVehiclesByColorAndYear(ctx context.Context, color string, year int) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.FindVehiclesByColorAndYear", attribute.String("filter.color", color), attribute.Int("filter.year", year))
+	defer span.End()
+
+	v = r.rp.FindVehiclesByColorAndYear(ctx, color, year)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) FindVehiclesByBrandAndRangeYears(ctx context.Context, brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "repository.FindVehiclesByBrandAndRangeYears", attribute.String("filter.brand", brand), attribute.Int("filter.start_year", starYear), attribute.Int("filter.end_year", endYear))
+	defer func() { end(span, err) }()
+
+	v, err = r.rp.FindVehiclesByBrandAndRangeYears(ctx, brand, starYear, endYear)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) FindVehiclesByBrand(ctx context.Context, brand string) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "repository.FindVehiclesByBrand", attribute.String("filter.brand", brand))
+	defer func() { end(span, err) }()
+
+	v, err = r.rp.FindVehiclesByBrand(ctx, brand)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error) {
+	ctx, span := start(ctx, "repository.UpdateMaxSpeed", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return r.rp.UpdateMaxSpeed(ctx, id, newSpeed)
+}
+
+func (r *vehicleTraced) FindVehiclesByFuel(ctx context.Context, fuel string) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.FindVehiclesByFuel", attribute.String("filter.fuel_type", fuel))
+	defer span.End()
+
+	v = r.rp.FindVehiclesByFuel(ctx, fuel)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) DeleteVehicle(ctx context.Context, id int) (err error) {
+	ctx, span := start(ctx, "repository.DeleteVehicle", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return r.rp.DeleteVehicle(ctx, id)
+}
+
+func (r *vehicleTraced) FindVehiclesByTransmission(ctx context.Context, transmisiion string) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.FindVehiclesByTransmission", attribute.String("filter.transmission", transmisiion))
+	defer span.End()
+
+	v = r.rp.FindVehiclesByTransmission(ctx, transmisiion)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) UpdateFuel(ctx context.Context, id int, newFuel string) (err error) {
+	ctx, span := start(ctx, "repository.UpdateFuel", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return r.rp.UpdateFuel(ctx, id, newFuel)
+}
+
+func (r *vehicleTraced) GetVehiclesByBrand(ctx context.Context, brand string) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.GetVehiclesByBrand", attribute.String("filter.brand", brand))
+	defer span.End()
+
+	v = r.rp.GetVehiclesByBrand(ctx, brand)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) FindVehiclesByDimensions(ctx context.Context, minLength float64, maxLength float64, minWidth float64, maxWidth float64) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.FindVehiclesByDimensions",
+		attribute.Float64("filter.min_length", minLength), attribute.Float64("filter.max_length", maxLength),
+		attribute.Float64("filter.min_width", minWidth), attribute.Float64("filter.max_width", maxWidth))
+	defer span.End()
+
+	v = r.rp.FindVehiclesByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) FindVehiclesByWeigth(ctx context.Context, minWeigth float64, maxWeigth float64) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.FindVehiclesByWeigth", attribute.Float64("filter.min_weight", minWeigth), attribute.Float64("filter.max_weight", maxWeigth))
+	defer span.End()
+
+	v = r.rp.FindVehiclesByWeigth(ctx, minWeigth, maxWeigth)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error) {
+	ctx, span := start(ctx, "repository.UpdatePrice", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return r.rp.UpdatePrice(ctx, id, newPrice)
+}
+
+func (r *vehicleTraced) UpdateMileage(ctx context.Context, id int, newMileage int) (err error) {
+	ctx, span := start(ctx, "repository.UpdateMileage", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return r.rp.UpdateMileage(ctx, id, newMileage)
+}
+
+func (r *vehicleTraced) FindVehiclesByMileage(ctx context.Context, minMileage int, maxMileage int) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.FindVehiclesByMileage", attribute.Int("filter.min_mileage", minMileage), attribute.Int("filter.max_mileage", maxMileage))
+	defer span.End()
+
+	v = r.rp.FindVehiclesByMileage(ctx, minMileage, maxMileage)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) FindVehiclesByBranch(ctx context.Context, branchId int) (v map[int]models.Vehicle) {
+	ctx, span := start(ctx, "repository.FindVehiclesByBranch", attribute.Int("filter.branch_id", branchId))
+	defer span.End()
+
+	v = r.rp.FindVehiclesByBranch(ctx, branchId)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (r *vehicleTraced) UpdateBranch(ctx context.Context, id int, branchId int, status string) (err error) {
+	ctx, span := start(ctx, "repository.UpdateBranch", keyVehicleID.Int(id), attribute.Int("branch.id", branchId), attribute.String("vehicle.status", status))
+	defer func() { end(span, err) }()
+
+	return r.rp.UpdateBranch(ctx, id, branchId, status)
+}
+
+func (r *vehicleTraced) UpdateStatus(ctx context.Context, id int, status string) (err error) {
+	ctx, span := start(ctx, "repository.UpdateStatus", keyVehicleID.Int(id), attribute.String("vehicle.status", status))
+	defer func() { end(span, err) }()
+
+	return r.rp.UpdateStatus(ctx, id, status)
+}
+
+// Ping is not traced, the readiness probe calls it every few seconds
+func (r *vehicleTraced) Ping(ctx context.Context) (err error) {
+	return r.rp.Ping(ctx)
+}
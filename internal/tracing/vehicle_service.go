@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"app/internal/service"
+	"app/pkg/models"
+	"context"
+
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TraceVehicleService is a function that returns a service that starts a span for every call to sv
+func TraceVehicleService(sv service.VehicleService) service.VehicleService {
+	return &vehicleServiceTraced{sv: sv}
+}
+
+// vehicleServiceTraced is a struct that implements the VehicleService interface with a span per call
+type vehicleServiceTraced struct {
+	// sv applies the business rules
+	sv service.VehicleService
+}
+
+func (s *vehicleServiceTraced) FindAll(ctx context.Context) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindAll")
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindAll(ctx)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (s *vehicleServiceTraced) AddVehicle(ctx context.Context, vehicleDoc models.VehicleDoc) (v models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.AddVehicle", keyVehicleID.Int(vehicleDoc.ID))
+	defer func() { end(span, err) }()
+
+	return s.sv.AddVehicle(ctx, vehicleDoc)
+}
+
+func (s *vehicleServiceTraced) FindVehiclesByColorAndYear(ctx context.Context, color string, year string) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindVehiclesByColorAndYear", attribute.String("filter.color", color), attribute.String("filter.year", year))
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindVehiclesByColorAndYear(ctx, color, year)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (s *vehicleServiceTraced) FindVehiclesByBrandAndRangeYears(ctx context.Context, brand string, starYear int, endYear int) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindVehiclesByBrandAndRangeYears", attribute.String("filter.brand", brand), attribute.Int("filter.start_year", starYear), attribute.Int("filter.end_year", endYear))
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindVehiclesByBrandAndRangeYears(ctx, brand, starYear, endYear)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (s *vehicleServiceTraced) FindAverageOfSpeedByBrand(ctx context.Context, brand string) (average float64, err error) {
+	ctx, span := start(ctx, "service.FindAverageOfSpeedByBrand", attribute.String("filter.brand", brand))
+	defer func() { end(span, err) }()
+
+	return s.sv.FindAverageOfSpeedByBrand(ctx, brand)
+}
+
+func (s *vehicleServiceTraced) AddMultipleVehicles(ctx context.Context, v []models.VehicleDoc) (err error) {
+	ctx, span := start(ctx, "service.AddMultipleVehicles", attribute.Int("batch.size", len(v)))
+	defer func() { end(span, err) }()
+
+	return s.sv.AddMultipleVehicles(ctx, v)
+}
+
+func (s *vehicleServiceTraced) CheckNewVehicle(ctx context.Context, vehicleDoc models.VehicleDoc) (err error) {
+	ctx, span := start(ctx, "service.CheckNewVehicle", keyVehicleID.Int(vehicleDoc.ID))
+	defer func() { end(span, err) }()
+
+	return s.sv.CheckNewVehicle(ctx, vehicleDoc)
+}
+
+func (s *vehicleServiceTraced) UpdateMaxSpeed(ctx context.Context, id int, newSpeed float64) (err error) {
+	ctx, span := start(ctx, "service.UpdateMaxSpeed", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return s.sv.UpdateMaxSpeed(ctx, id, newSpeed)
+}
+
+func (s *vehicleServiceTraced) GetVehicleById(ctx context.Context, id int) (v models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.GetVehicleById", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return s.sv.GetVehicleById(ctx, id)
+}
+
+func (s *vehicleServiceTraced) FindVehiclesByFuel(ctx context.Context, fuel string) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindVehiclesByFuel", attribute.String("filter.fuel_type", fuel))
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindVehiclesByFuel(ctx, fuel)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (s *vehicleServiceTraced) DeleteVehicle(ctx context.Context, id int) (err error) {
+	ctx, span := start(ctx, "service.DeleteVehicle", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return s.sv.DeleteVehicle(ctx, id)
+}
+
+func (s *vehicleServiceTraced) FindVehiclesByTransmission(ctx context.Context, transmisiion string) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindVehiclesByTransmission", attribute.String("filter.transmission", transmisiion))
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindVehiclesByTransmission(ctx, transmisiion)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (s *vehicleServiceTraced) UpdateFuel(ctx context.Context, id int, vehicleDoc models.VehicleDoc) (err error) {
+	ctx, span := start(ctx, "service.UpdateFuel", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return s.sv.UpdateFuel(ctx, id, vehicleDoc)
+}
+
+func (s *vehicleServiceTraced) GetAveragePeopleCapacityByBrand(ctx context.Context, brand string) (capacity int, err error) {
+	ctx, span := start(ctx, "service.GetAveragePeopleCapacityByBrand", attribute.String("filter.brand", brand))
+	defer func() { end(span, err) }()
+
+	return s.sv.GetAveragePeopleCapacityByBrand(ctx, brand)
+}
+
+func (s *vehicleServiceTraced) FindVehiclesByDimensions(ctx context.Context, minLength float64, maxLength float64, minWidth float64, maxWidth float64) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindVehiclesByDimensions",
+		attribute.Float64("filter.min_length", minLength), attribute.Float64("filter.max_length", maxLength),
+		attribute.Float64("filter.min_width", minWidth), attribute.Float64("filter.max_width", maxWidth))
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindVehiclesByDimensions(ctx, minLength, maxLength, minWidth, maxWidth)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (s *vehicleServiceTraced) FindVehiclesByWeigth(ctx context.Context, minWeigth float64, maxWeigth float64) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindVehiclesByWeigth", attribute.Float64("filter.min_weight", minWeigth), attribute.Float64("filter.max_weight", maxWeigth))
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindVehiclesByWeigth(ctx, minWeigth, maxWeigth)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
+
+func (s *vehicleServiceTraced) UpdatePrice(ctx context.Context, id int, newPrice decimal.Decimal) (err error) {
+	ctx, span := start(ctx, "service.UpdatePrice", keyVehicleID.Int(id))
+	defer func() { end(span, err) }()
+
+	return s.sv.UpdatePrice(ctx, id, newPrice)
+}
+
+func (s *vehicleServiceTraced) FindVehiclesByMileage(ctx context.Context, minMileage int, maxMileage int) (v map[int]models.Vehicle, err error) {
+	ctx, span := start(ctx, "service.FindVehiclesByMileage", attribute.Int("filter.min_mileage", minMileage), attribute.Int("filter.max_mileage", maxMileage))
+	defer func() { end(span, err) }()
+
+	v, err = s.sv.FindVehiclesByMileage(ctx, minMileage, maxMileage)
+	span.SetAttributes(keyResultCount.Int(len(v)))
+	return
+}
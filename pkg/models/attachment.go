@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+const (
+	// AttachmentPhoto is a picture of the vehicle
+	AttachmentPhoto = "photo"
+	// AttachmentTitle is the title document of the vehicle
+	AttachmentTitle = "title"
+	// AttachmentInspection is an inspection report of the vehicle
+	AttachmentInspection = "inspection"
+)
+
+// Attachment is a struct that represents a file attached to a vehicle
+type Attachment struct {
+	// Id is the unique identifier of the attachment
+	Id int
+	// VehicleId is the identifier of the vehicle
+	VehicleId int
+	// Kind is the kind of attachment (photo, title, inspection)
+	Kind string
+	// FileName is the name of the file as uploaded
+	FileName string
+	// ContentType is the type sniffed from the content
+	ContentType string
+	// Size is the size of the content in bytes
+	Size int64
+	// Hash is the sha256 of the content, used to store and deduplicate it
+	Hash string
+	// ThumbnailHash is the sha256 of the thumbnail, empty if it is not an image
+	ThumbnailHash string
+	// CreatedAt is the moment the file was uploaded
+	CreatedAt time.Time
+}
+
+// AttachmentDoc is a struct that represents an attachment in JSON format
+type AttachmentDoc struct {
+	ID           int       `json:"id"`
+	VehicleId    int       `json:"vehicle_id"`
+	Kind         string    `json:"kind"`
+	FileName     string    `json:"file_name"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Hash         string    `json:"hash"`
+	HasThumbnail bool      `json:"has_thumbnail"`
+	CreatedAt    time.Time `json:"created_at"`
+}
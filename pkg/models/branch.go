@@ -0,0 +1,21 @@
+package models
+
+// Branch is a struct that represents a branch of the dealership
+type Branch struct {
+	// Id is the unique identifier of the branch
+	Id int
+	// Name is the name of the branch
+	Name string
+	// Address is the street address of the branch
+	Address string
+	// City is the city of the branch
+	City string
+}
+
+// BranchDoc is a struct that represents a branch in JSON format
+type BranchDoc struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	City    string `json:"city"`
+}
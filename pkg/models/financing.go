@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// AmortizationFrench is the amortization system with a constant installment
+	AmortizationFrench = "french"
+	// AmortizationGerman is the amortization system with a constant principal payment
+	AmortizationGerman = "german"
+)
+
+// FinancingRequest is a struct that represents the parameters of a financing simulation
+type FinancingRequest struct {
+	// VehicleId is the identifier of the financed vehicle
+	VehicleId int
+	// DownPayment is the amount paid upfront
+	DownPayment decimal.Decimal
+	// TermMonths is the amount of monthly installments
+	TermMonths int
+	// AnnualRate is the nominal annual interest rate (TNA) as a percentage
+	AnnualRate decimal.Decimal
+	// OpeningFee is the fee charged when the loan is granted
+	OpeningFee decimal.Decimal
+	// System is the amortization system (french or german)
+	System string
+}
+
+// Installment is a struct that represents a row of an amortization schedule
+type Installment struct {
+	// Number is the number of the installment, starting at 1
+	Number int `json:"number"`
+	// Payment is the total amount of the installment
+	Payment decimal.Decimal `json:"payment"`
+	// Interest is the interest part of the installment
+	Interest decimal.Decimal `json:"interest"`
+	// Principal is the principal part of the installment
+	Principal decimal.Decimal `json:"principal"`
+	// Balance is the outstanding principal after the installment
+	Balance decimal.Decimal `json:"balance"`
+}
+
+// FinancingPlan is a struct that represents the result of a financing simulation
+type FinancingPlan struct {
+	VehicleId     int             `json:"vehicle_id"`
+	System        string          `json:"system"`
+	ListPrice     decimal.Decimal `json:"list_price"`
+	DownPayment   decimal.Decimal `json:"down_payment"`
+	Principal     decimal.Decimal `json:"principal"`
+	TermMonths    int             `json:"term"`
+	AnnualRate    decimal.Decimal `json:"annual_rate"`
+	OpeningFee    decimal.Decimal `json:"opening_fee"`
+	TotalInterest decimal.Decimal `json:"total_interest"`
+	TotalPaid     decimal.Decimal `json:"total_paid"`
+	// CFT is the total financial cost expressed as an effective annual rate percentage
+	CFT          decimal.Decimal `json:"cft"`
+	Installments []Installment   `json:"installments"`
+}
+
+// Quote is a struct that represents a financing plan saved for a customer
+type Quote struct {
+	// Id is the unique identifier of the quote
+	Id int
+	// CustomerName is the full name of the customer
+	CustomerName string
+	// CustomerDocument is the identity document of the customer
+	CustomerDocument string
+	// CreatedAt is the moment the quote was saved
+	CreatedAt time.Time
+	// SalespersonId is the identifier of the staff account that saved the quote, zero if unknown
+	SalespersonId int
+	// Salesperson is who saved the quote: the username or the name of the API key
+	Salesperson string
+	// Plan is the financing plan quoted
+	Plan FinancingPlan
+}
+
+// QuoteDoc is a struct that represents a quote in JSON format
+type QuoteDoc struct {
+	ID               int             `json:"id"`
+	CustomerName     string          `json:"customer_name"`
+	CustomerDocument string          `json:"customer_document"`
+	VehicleId        int             `json:"vehicle_id"`
+	DownPayment      decimal.Decimal `json:"down_payment"`
+	TermMonths       int             `json:"term"`
+	AnnualRate       decimal.Decimal `json:"annual_rate"`
+	OpeningFee       decimal.Decimal `json:"opening_fee"`
+	System           string          `json:"system"`
+	CreatedAt        time.Time       `json:"created_at"`
+	SalespersonId    int             `json:"salesperson_id,omitempty"`
+	Salesperson      string          `json:"salesperson,omitempty"`
+	Plan             *FinancingPlan  `json:"plan,omitempty"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+const (
+	// ImportPending is the status of an import job waiting to start
+	ImportPending = "pending"
+	// ImportRunning is the status of an import job adding its vehicles
+	ImportRunning = "running"
+	// ImportSucceeded is the status of an import job that added every vehicle
+	ImportSucceeded = "succeeded"
+	// ImportFailed is the status of an import job stopped by an error
+	ImportFailed = "failed"
+)
+
+// ImportRow is a struct that represents a row of a file of vehicles to import
+type ImportRow struct {
+	// Line is the line of the file where the row starts
+	Line int
+	// Vehicle is the vehicle read from the row
+	Vehicle VehicleDoc
+	// Errors are the values of the row that could not be read, as "field: reason" when they belong to a field
+	Errors []string
+}
+
+// ImportFile is a struct that represents a file of vehicles to import
+type ImportFile struct {
+	// Rows are the rows of the file in order
+	Rows []ImportRow
+	// IgnoredColumns are the columns of the file that are not fields of the vehicles
+	IgnoredColumns []string
+}
+
+// ImportReport is a struct that represents the validation of the rows of an import
+type ImportReport struct {
+	Rows           int              `json:"rows"`
+	Valid          int              `json:"valid"`
+	Invalid        int              `json:"invalid"`
+	IgnoredColumns []string         `json:"ignored_columns,omitempty"`
+	Errors         []ImportRowError `json:"errors"`
+}
+
+// ImportRowError is a struct that represents the reasons a row can't be imported
+type ImportRowError struct {
+	Line   int      `json:"line"`
+	Id     int      `json:"id,omitempty"`
+	Errors []string `json:"errors"`
+}
+
+// ImportJob is a struct that represents the addition of the vehicles of an import
+type ImportJob struct {
+	// Id is the unique identifier of the job
+	Id int
+	// Status is the step of the job (pending, running, succeeded, failed)
+	Status string
+	// Report is the validation of the rows imported
+	Report ImportReport
+	// Total is the number of vehicles to add
+	Total int
+	// Created is the number of vehicles added so far, the ones of the first rows
+	Created int
+	// LastLine is the line of the last row added, the rows after it were not when the job failed
+	LastLine int
+	// Error is the reason the job failed
+	Error string
+	// CreatedAt is the moment the job was created
+	CreatedAt time.Time
+	// StartedAt is the moment the job started adding the vehicles
+	StartedAt *time.Time
+	// FinishedAt is the moment the job succeeded or failed
+	FinishedAt *time.Time
+}
+
+// ImportJobDoc is a struct that represents an import job in JSON format
+type ImportJobDoc struct {
+	ID         int          `json:"id"`
+	Status     string       `json:"status"`
+	Report     ImportReport `json:"report"`
+	Total      int          `json:"total"`
+	Created    int          `json:"created"`
+	LastLine   int          `json:"last_line,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	StartedAt  *time.Time   `json:"started_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// LoadReport is a struct that represents what the loader did with the records of its sources
+type LoadReport struct {
+	// Loaded is the number of vehicles loaded
+	Loaded int `json:"loaded"`
+	// Skipped are the records that could not be read or are not valid vehicles
+	Skipped []LoadIssue `json:"skipped"`
+	// Repaired are the records loaded after filling their missing fields with the defaults
+	Repaired []LoadIssue `json:"repaired"`
+	// Duplicates are the records skipped because their id was already loaded
+	Duplicates []LoadIssue `json:"duplicates"`
+	// Ignored are the files of a directory that were not read, their extension is of no known format
+	Ignored []string `json:"ignored"`
+}
+
+// LoadIssue is a struct that represents a record of a source that was skipped or repaired
+type LoadIssue struct {
+	// Source is the file of the record
+	Source string `json:"source"`
+	// Position is the line of the record, or its position in a JSON array
+	Position int `json:"position"`
+	// Id is the identifier of the vehicle, if it could be read
+	Id int `json:"id,omitempty"`
+	// Reasons explain what was wrong with the record
+	Reasons []string `json:"reasons"`
+}
+
+// Errors is a method that returns the number of records that were not loaded
+func (r LoadReport) Errors() int {
+	return len(r.Skipped) + len(r.Duplicates)
+}
+
+// ReloadReport is a struct that represents a reload of the vehicles while the server runs
+type ReloadReport struct {
+	LoadReport
+	// Merge tells if the changes made since the last load were kept
+	Merge bool `json:"merge"`
+	// Kept is the number of vehicles added, updated or deleted since the last load that kept their state
+	Kept int `json:"kept"`
+	// Dropped is the number of vehicles removed because they are no longer in the files
+	Dropped int `json:"dropped"`
+}
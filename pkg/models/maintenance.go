@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaintenancePart is a struct that represents a part used in a workshop intervention
+type MaintenancePart struct {
+	Name     string          `json:"name"`
+	Quantity int             `json:"quantity"`
+	UnitCost decimal.Decimal `json:"unit_cost"`
+}
+
+// MaintenanceRecord is a struct that represents a workshop intervention on a vehicle
+type MaintenanceRecord struct {
+	// Id is the unique identifier of the record
+	Id int
+	// VehicleId is the identifier of the vehicle serviced
+	VehicleId int
+	// Date is the day of the intervention
+	Date time.Time
+	// Type is the kind of intervention (oil_change, tires, paint, ...)
+	Type string
+	// Parts are the parts used in the intervention
+	Parts []MaintenancePart
+	// LaborCost is the cost of the labor
+	LaborCost decimal.Decimal
+	// Odometer is the odometer value when the vehicle was serviced, zero if unknown
+	Odometer int
+	// Notes are free comments of the workshop
+	Notes string
+	// RecordedBy is who recorded the intervention: the username or the name of the API key
+	RecordedBy string
+}
+
+// PartsCost is a method that returns the cost of all the parts of the record
+func (m MaintenanceRecord) PartsCost() decimal.Decimal {
+	total := decimal.Zero
+	for _, part := range m.Parts {
+		total = total.Add(part.UnitCost.Mul(decimal.NewFromInt(int64(part.Quantity))))
+	}
+	return total
+}
+
+// MaintenanceRecordDoc is a struct that represents a maintenance record in JSON format
+type MaintenanceRecordDoc struct {
+	ID         int               `json:"id"`
+	VehicleId  int               `json:"vehicle_id"`
+	Date       time.Time         `json:"date"`
+	Type       string            `json:"type"`
+	Parts      []MaintenancePart `json:"parts"`
+	LaborCost  decimal.Decimal   `json:"labor_cost"`
+	Odometer   int               `json:"odometer"`
+	Notes      string            `json:"notes"`
+	TotalCost  decimal.Decimal   `json:"total_cost"`
+	RecordedBy string            `json:"recorded_by,omitempty"`
+}
+
+// ReconditioningCost is a struct that represents the money spent reconditioning a vehicle
+type ReconditioningCost struct {
+	VehicleId int             `json:"vehicle_id"`
+	Records   int             `json:"records"`
+	PartsCost decimal.Decimal `json:"parts_cost"`
+	LaborCost decimal.Decimal `json:"labor_cost"`
+	TotalCost decimal.Decimal `json:"total_cost"`
+	ListPrice decimal.Decimal `json:"list_price"`
+	// NetOfCost is the list price minus the reconditioning cost
+	NetOfCost decimal.Decimal `json:"net_of_cost"`
+}
+
+// MaintenanceSchedule is a struct that represents a recurring maintenance of a vehicle
+type MaintenanceSchedule struct {
+	// Id is the unique identifier of the schedule
+	Id int
+	// VehicleId is the identifier of the vehicle
+	VehicleId int
+	// Type is the kind of intervention, matched against the records type
+	Type string
+	// IntervalDays is the amount of days between interventions, zero to ignore
+	IntervalDays int
+	// IntervalKm is the amount of kilometers between interventions, zero to ignore
+	IntervalKm int
+	// StartDate is the moment the schedule was created
+	StartDate time.Time
+	// StartOdometer is the mileage of the vehicle when the schedule was created
+	StartOdometer int
+}
+
+// MaintenanceScheduleDoc is a struct that represents a maintenance schedule in JSON format
+type MaintenanceScheduleDoc struct {
+	ID            int       `json:"id"`
+	VehicleId     int       `json:"vehicle_id"`
+	Type          string    `json:"type"`
+	IntervalDays  int       `json:"interval_days"`
+	IntervalKm    int       `json:"interval_km"`
+	StartDate     time.Time `json:"start_date"`
+	StartOdometer int       `json:"start_odometer"`
+}
+
+// OverdueMaintenance is a struct that represents a schedule whose interval has elapsed
+type OverdueMaintenance struct {
+	ScheduleId     int        `json:"schedule_id"`
+	VehicleId      int        `json:"vehicle_id"`
+	Type           string     `json:"type"`
+	LastDate       time.Time  `json:"last_date"`
+	LastOdometer   int        `json:"last_odometer"`
+	DueDate        *time.Time `json:"due_date,omitempty"`
+	DueOdometer    int        `json:"due_odometer,omitempty"`
+	CurrentMileage int        `json:"current_mileage"`
+	OverdueDays    int        `json:"overdue_days"`
+	OverdueKm      int        `json:"overdue_km"`
+}
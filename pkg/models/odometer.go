@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// OdometerReading is a struct that represents a reading of the odometer of a vehicle
+type OdometerReading struct {
+	// Id is the unique identifier of the reading
+	Id int
+	// VehicleId is the identifier of the vehicle read
+	VehicleId int
+	// Value is the odometer value in kilometers
+	Value int
+	// Date is the moment the odometer was read
+	Date time.Time
+	// Source is where the reading comes from (intake, inspection, workshop, ...)
+	Source string
+	// Override tells if the reading was allowed to go backwards (odometer replacement)
+	Override bool
+	// OverrideReason is the justification of the override
+	OverrideReason string
+	// PreviousValue is the value of the last reading when the override was recorded
+	PreviousValue int
+	// RecordedBy is who recorded the reading: the username or the name of the API key
+	RecordedBy string
+}
+
+// OdometerReadingDoc is a struct that represents an odometer reading in JSON format
+type OdometerReadingDoc struct {
+	ID             int       `json:"id"`
+	VehicleId      int       `json:"vehicle_id"`
+	Value          int       `json:"value"`
+	Date           time.Time `json:"date"`
+	Source         string    `json:"source"`
+	Override       bool      `json:"override"`
+	OverrideReason string    `json:"override_reason,omitempty"`
+	PreviousValue  int       `json:"previous_value,omitempty"`
+	RecordedBy     string    `json:"recorded_by,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+const (
+	// TransferRequested is the status of a transfer waiting to be dispatched
+	TransferRequested = "requested"
+	// TransferInTransit is the status of a transfer whose vehicle left the origin branch
+	TransferInTransit = "in_transit"
+	// TransferReceived is the status of a transfer whose vehicle arrived at the destination branch
+	TransferReceived = "received"
+	// TransferCancelled is the status of a transfer cancelled before dispatch
+	TransferCancelled = "cancelled"
+)
+
+// Transfer is a struct that represents the move of a vehicle between branches
+type Transfer struct {
+	// Id is the unique identifier of the transfer
+	Id int
+	// VehicleId is the identifier of the vehicle moved
+	VehicleId int
+	// FromBranchId is the branch the vehicle leaves
+	FromBranchId int
+	// ToBranchId is the branch the vehicle goes to
+	ToBranchId int
+	// Status is the step of the workflow (requested, in_transit, received, cancelled)
+	Status string
+	// Notes are free comments of the transfer
+	Notes string
+	// RequestedAt is the moment the transfer was requested
+	RequestedAt time.Time
+	// DispatchedAt is the moment the vehicle left the origin branch
+	DispatchedAt *time.Time
+	// ReceivedAt is the moment the vehicle arrived at the destination branch
+	ReceivedAt *time.Time
+	// CancelledAt is the moment the transfer was cancelled
+	CancelledAt *time.Time
+	// RequestedBy, DispatchedBy, ReceivedBy and CancelledBy are who made each step of the workflow:
+	// the username or the name of the API key, empty while the step is pending
+	RequestedBy  string
+	DispatchedBy string
+	ReceivedBy   string
+	CancelledBy  string
+}
+
+// TransferDoc is a struct that represents a transfer in JSON format
+type TransferDoc struct {
+	ID           int        `json:"id"`
+	VehicleId    int        `json:"vehicle_id"`
+	FromBranchId int        `json:"from_branch_id"`
+	ToBranchId   int        `json:"to_branch_id"`
+	Status       string     `json:"status"`
+	Notes        string     `json:"notes"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+	ReceivedAt   *time.Time `json:"received_at,omitempty"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	RequestedBy  string     `json:"requested_by,omitempty"`
+	DispatchedBy string     `json:"dispatched_by,omitempty"`
+	ReceivedBy   string     `json:"received_by,omitempty"`
+	CancelledBy  string     `json:"cancelled_by,omitempty"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// User is a struct that represents a staff account of the dealership
+type User struct {
+	// Id is the unique identifier of the user
+	Id int
+	// Username is the name used to log in, unique
+	Username string
+	// FullName is the name shown in quotes and audit entries
+	FullName string
+	// Role is the role granted to the user (viewer, salesperson or admin)
+	Role string
+	// PasswordHash is the bcrypt hash of the password
+	PasswordHash []byte
+	// CreatedAt is the moment the account was created
+	CreatedAt time.Time
+	// TokensRevokedAt invalidates the access tokens issued before it
+	TokensRevokedAt time.Time
+}
+
+// UserDoc is a struct that represents a user in JSON format, the password is only read
+type UserDoc struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Role      string    `json:"role"`
+	Password  string    `json:"password,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TokenPair is a struct that represents the tokens issued on login
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int `json:"expires_in"`
+}
+
+// RefreshToken is a struct that represents a refresh token issued to a user
+type RefreshToken struct {
+	// Hash is the sha256 of the token, the token itself is never stored
+	Hash string
+	// UserId is the identifier of the owner
+	UserId int
+	// ExpiresAt is the moment the token stops being accepted
+	ExpiresAt time.Time
+	// RevokedAt is the moment the token was used or revoked, nil while valid
+	RevokedAt *time.Time
+}
+
+// PasswordReset is a struct that represents a single use token to set a new password
+type PasswordReset struct {
+	// Hash is the sha256 of the token, the token itself is never stored
+	Hash string
+	// UserId is the identifier of the owner
+	UserId int
+	// ExpiresAt is the moment the token stops being accepted
+	ExpiresAt time.Time
+	// UsedAt is the moment the token was used, nil while valid
+	UsedAt *time.Time
+}
+
+// PasswordResetDoc is a struct that represents a password reset token in JSON format
+type PasswordResetDoc struct {
+	Token     string    `json:"token"`
+	UserId    int       `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,5 +1,17 @@
 package models
 
+import "github.com/shopspring/decimal"
+
+const (
+	// DefaultBranchId is the branch assigned to the vehicles without one
+	DefaultBranchId = 1
+
+	// VehicleAvailable is the status of a vehicle in stock at its branch
+	VehicleAvailable = "available"
+	// VehicleInTransit is the status of a vehicle being transferred between branches
+	VehicleInTransit = "in_transit"
+)
+
 // Dimensions is a struct that represents a dimension in 3d
 type Dimensions struct {
 	// Height is the height of the dimension
@@ -32,26 +44,38 @@ type VehicleAttributes struct {
 	Transmission string
 	// Weight is the weight of the vehicle
 	Weight float64
+	// Price is the list price of the vehicle
+	Price decimal.Decimal
+	// Mileage is the current odometer reading of the vehicle in kilometers
+	Mileage int
+	// BranchId is the branch where the vehicle is in stock
+	BranchId int
+	// Status is the stock status of the vehicle (available, in_transit)
+	Status string
 	// Dimensions is the dimensions of the vehicle
 	Dimensions
 }
 
 // Vehicle is a struct that represents a vehicle in JSON format
 type VehicleDoc struct {
-	ID              int     `json:"id"`
-	Brand           string  `json:"brand"`
-	Model           string  `json:"model"`
-	Registration    string  `json:"registration"`
-	Color           string  `json:"color"`
-	FabricationYear int     `json:"year"`
-	Capacity        int     `json:"passengers"`
-	MaxSpeed        float64 `json:"max_speed"`
-	FuelType        string  `json:"fuel_type"`
-	Transmission    string  `json:"transmission"`
-	Weight          float64 `json:"weight"`
-	Height          float64 `json:"height"`
-	Length          float64 `json:"length"`
-	Width           float64 `json:"width"`
+	ID              int             `json:"id"`
+	Brand           string          `json:"brand"`
+	Model           string          `json:"model"`
+	Registration    string          `json:"registration"`
+	Color           string          `json:"color"`
+	FabricationYear int             `json:"year"`
+	Capacity        int             `json:"passengers"`
+	MaxSpeed        float64         `json:"max_speed"`
+	FuelType        string          `json:"fuel_type"`
+	Transmission    string          `json:"transmission"`
+	Weight          float64         `json:"weight"`
+	Height          float64         `json:"height"`
+	Length          float64         `json:"length"`
+	Width           float64         `json:"width"`
+	Price           decimal.Decimal `json:"price"`
+	Mileage         int             `json:"mileage"`
+	BranchId        int             `json:"branch_id"`
+	Status          string          `json:"status"`
 }
 
 // Vehicle is a struct that represents a vehicle
@@ -62,3 +86,32 @@ type Vehicle struct {
 	// VehicleAttribue is the attributes of a vehicle
 	VehicleAttributes
 }
+
+// MissingFields is a method that returns the JSON names of the mandatory fields the vehicle lacks
+func (v Vehicle) MissingFields() (fields []string) {
+	mandatory := []struct {
+		name    string
+		missing bool
+	}{
+		{"id", v.Id == 0},
+		{"brand", v.Brand == ""},
+		{"model", v.Model == ""},
+		{"registration", v.Registration == ""},
+		{"color", v.Color == ""},
+		{"year", v.FabricationYear == 0},
+		{"passengers", v.Capacity == 0},
+		{"max_speed", v.MaxSpeed == 0},
+		{"fuel_type", v.FuelType == ""},
+		{"transmission", v.Transmission == ""},
+		{"weight", v.Weight == 0},
+		{"height", v.Height == 0},
+		{"length", v.Length == 0},
+		{"width", v.Width == 0},
+	}
+	for _, field := range mandatory {
+		if field.missing {
+			fields = append(fields, field.name)
+		}
+	}
+	return
+}